@@ -4,24 +4,251 @@ import (
 	"fmt"
 	"github.com/Debjth19/go-evermos/config"
 	"github.com/Debjth19/go-evermos/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// backfillSnapshot melengkapi transaksi lama yang dibuat sebelum snapshot disimpan saat checkout.
+// Setiap query hanya menyentuh baris yang belum memiliki snapshot sehingga aman dijalankan berulang.
+var backfillSnapshot = []string{
+	// Snapshot produk dilengkapi nama kategori
+	`UPDATE log_produks l
+		JOIN kategoris k ON k.id = l.category_id
+		SET l.nama_category = k.nama_category
+		WHERE COALESCE(l.nama_category, '') = ''`,
+	// Alamat tujuan disalin dari alamat pembeli yang masih ada
+	`UPDATE transaksis t
+		JOIN alamats a ON a.id = t.alamat_kirim_id
+		SET t.judul_alamat = a.judul_alamat, t.nama_penerima = a.nama_penerima, t.no_telp_penerima = a.no_telp,
+			t.alamat_penerima = a.detail_alamat, t.id_provinsi_penerima = a.id_provinsi, t.id_kota_penerima = a.id_kota
+		WHERE t.dropship = 0 AND COALESCE(t.nama_penerima, '') = ''`,
+	`UPDATE transaksis SET judul_alamat = 'Dropship'
+		WHERE dropship = 1 AND COALESCE(judul_alamat, '') = ''`,
+}
+
 func MigrateDatabase() {
+	// Transaksi yang dibuat sebelum kolom status ada sudah selesai diproses di luar sistem.
+	// Dicek sebelum AutoMigrate karena kolom baru langsung terisi nilai bawaan 'pending_payment'.
+	tanpaStatus := config.DB.Migrator().HasTable(&model.Transaksi{}) &&
+		!config.DB.Migrator().HasColumn(&model.Transaksi{}, "Status")
+
 	err := config.DB.AutoMigrate(
 		&model.User{},
+		&model.PengajuanReseller{},
 		&model.Toko{},
 		&model.Alamat{},
 		&model.Kategori{},
 		&model.Produk{},
 		&model.FotoProduk{},
+		&model.OpsiProduk{},
+		&model.NilaiOpsiProduk{},
+		&model.VarianProduk{},
 		&model.Transaksi{},
+		&model.Pembayaran{},
 		&model.DetailTransaksi{},
 		&model.LogProduk{},
+		&model.InvoiceSequence{},
+		&model.IdempotencyRecord{},
+		&model.Retur{},
+		&model.ReturItem{},
+		&model.FotoRetur{},
+		&model.Refund{},
+		&model.Jurnal{},
+		&model.LedgerEntry{},
+		&model.Penarikan{},
+		&model.Voucher{},
+		&model.PemakaianVoucher{},
+		&model.KeranjangItem{},
+		&model.Wishlist{},
 	)
 	
 	if err != nil {
 		panic("Gagal melakukan migrasi database")
 	}
+
+	// Transaksi lama diberi status final agar tidak dianggap belum dibayar dan dikedaluwarsakan worker
+	if tanpaStatus {
+		err := config.DB.Exec("UPDATE transaksis SET status = ?, completed_at = updated_at", model.StatusCompleted).Error
+		if err != nil {
+			panic("Gagal melengkapi status transaksi lama")
+		}
+	}
+
+	// Transaksi tidak lagi berelasi ke alamat; alamat tujuan disimpan sebagai snapshot
+	if config.DB.Migrator().HasConstraint(&model.Transaksi{}, "fk_transaksis_alamat") {
+		if err := config.DB.Migrator().DropConstraint(&model.Transaksi{}, "fk_transaksis_alamat"); err != nil {
+			panic("Gagal menghapus relasi alamat transaksi")
+		}
+	}
+
+	// Item keranjang kini unik per produk dan varian
+	if config.DB.Migrator().HasIndex(&model.KeranjangItem{}, "idx_keranjang_user_produk") {
+		if err := config.DB.Migrator().DropIndex(&model.KeranjangItem{}, "idx_keranjang_user_produk"); err != nil {
+			panic("Gagal menghapus indeks keranjang lama")
+		}
+	}
+
+	// Record idempotency lama diberi masa berlaku: lease processing langsung berakhir, respons completed disimpan sehari
+	err = config.DB.Exec(`UPDATE idempotency_records
+		SET expires_at = CASE WHEN status = ? THEN DATE_ADD(updated_at, INTERVAL 1 DAY) ELSE updated_at END
+		WHERE expires_at IS NULL`, model.IdempotencyCompleted).Error
+	if err != nil {
+		panic("Gagal melengkapi masa berlaku idempotency key")
+	}
+
+	// Transaksi lama dipecah menjadi sub-transaksi per toko agar tampil di pesanan masuk penjual
+	if err := backfillSubTransaksi(config.DB); err != nil {
+		panic("Gagal membuat sub-transaksi untuk transaksi lama")
+	}
+
+	// Data lama dilengkapi snapshot yang ditambahkan belakangan
+	if err := hubungkanSnapshot(config.DB); err != nil {
+		panic("Gagal menghubungkan item transaksi lama ke snapshot produk")
+	}
+	for _, query := range backfillSnapshot {
+		if err := config.DB.Exec(query).Error; err != nil {
+			panic("Gagal melengkapi snapshot transaksi")
+		}
+	}
 	
 	fmt.Println("Migrasi database berhasil")
-}
\ No newline at end of file
+}
+
+// backfillSubTransaksi memecah transaksi yang dibuat sebelum adanya sub-transaksi per toko.
+// Transaksi tersebut menyimpan item dari beberapa toko sekaligus dan tidak memiliki toko_id,
+// sehingga item dan snapshot-nya dipindahkan ke sub-transaksi baru untuk setiap toko.
+// Hanya transaksi induk tanpa sub-transaksi yang masih memiliki item sendiri yang diproses,
+// sehingga aman dijalankan berulang.
+func backfillSubTransaksi(db *gorm.DB) error {
+	var lama []model.Transaksi
+	err := db.Where("parent_id IS NULL AND toko_id = 0").
+		Where("NOT EXISTS (SELECT 1 FROM transaksis c WHERE c.parent_id = transaksis.id)").
+		Where("EXISTS (SELECT 1 FROM detail_transaksis d WHERE d.transaksi_id = transaksis.id)").
+		Order("id asc").
+		Find(&lama).Error
+	if err != nil {
+		return err
+	}
+
+	for _, induk := range lama {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var details []model.DetailTransaksi
+			if err := tx.Where("transaksi_id = ?", induk.ID).Order("id asc").Find(&details).Error; err != nil {
+				return err
+			}
+
+			// Urutan toko sesuai kemunculan pertama itemnya, seperti saat checkout
+			var tokoIDs []uint
+			subtotal := map[uint]uint{}
+			for _, detail := range details {
+				if _, ok := subtotal[detail.TokoID]; !ok {
+					tokoIDs = append(tokoIDs, detail.TokoID)
+				}
+				subtotal[detail.TokoID] += detail.HargaTotal
+			}
+
+			parentID := induk.ID
+			for i, tokoID := range tokoIDs {
+				// Status, waktu, alamat, dan pembeli disalin dari transaksi induk
+				sub := induk
+				sub.ID = 0
+				sub.ParentID = &parentID
+				sub.TokoID = tokoID
+				sub.Subtotal = subtotal[tokoID]
+				sub.OngkosKirim = 0
+				sub.Diskon = 0
+				sub.HargaTotal = subtotal[tokoID]
+				sub.KodeInvoice = fmt.Sprintf("%s-%d", induk.KodeInvoice, i+1)
+				if err := tx.Omit(clause.Associations).Create(&sub).Error; err != nil {
+					return err
+				}
+
+				err := tx.Model(&model.DetailTransaksi{}).
+					Where("transaksi_id = ? AND toko_id = ?", induk.ID, tokoID).
+					Update("transaksi_id", sub.ID).Error
+				if err != nil {
+					return err
+				}
+				err = tx.Model(&model.LogProduk{}).
+					Where("transaksi_id = ? AND toko_id = ?", induk.ID, tokoID).
+					Update("transaksi_id", sub.ID).Error
+				if err != nil {
+					return err
+				}
+			}
+
+			// Subtotal induk yang belum terisi mengikuti total item
+			if induk.Subtotal == 0 {
+				return tx.Model(&model.Transaksi{}).Where("id = ?", induk.ID).
+					Update("subtotal", induk.HargaTotal).Error
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hubungkanSnapshot menghubungkan item transaksi lama ke snapshot produknya. Transaksi lama dapat memuat
+// produk yang sama di beberapa baris, sehingga item dan snapshot dengan transaksi dan produk yang sama
+// dipasangkan satu per satu sesuai urutan ID. Snapshot yang sudah dipakai item lain tidak dipasangkan lagi,
+// dan item yang tidak memiliki pasangan dibiarkan tanpa snapshot.
+func hubungkanSnapshot(db *gorm.DB) error {
+	var trxIDs []uint
+	err := db.Model(&model.DetailTransaksi{}).
+		Where("COALESCE(log_produk_id, 0) = 0").
+		Distinct("transaksi_id").
+		Order("transaksi_id asc").
+		Pluck("transaksi_id", &trxIDs).Error
+	if err != nil {
+		return err
+	}
+
+	for _, trxID := range trxIDs {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var details []model.DetailTransaksi
+			err := tx.Where("transaksi_id = ? AND COALESCE(log_produk_id, 0) = 0", trxID).
+				Order("id asc").
+				Find(&details).Error
+			if err != nil {
+				return err
+			}
+
+			var logs []model.LogProduk
+			err = tx.Where("transaksi_id = ?", trxID).
+				Where("NOT EXISTS (SELECT 1 FROM detail_transaksis d WHERE d.log_produk_id = log_produks.id)").
+				Order("id asc").
+				Find(&logs).Error
+			if err != nil {
+				return err
+			}
+
+			// Snapshot yang belum dipakai diantrekan per produk sesuai urutan ID
+			antrean := map[uint][]uint{}
+			for _, log := range logs {
+				antrean[log.ProductID] = append(antrean[log.ProductID], log.ID)
+			}
+			for _, detail := range details {
+				logIDs := antrean[detail.ProductID]
+				if len(logIDs) == 0 {
+					continue
+				}
+				antrean[detail.ProductID] = logIDs[1:]
+
+				err := tx.Model(&model.DetailTransaksi{}).Where("id = ?", detail.ID).
+					Update("log_produk_id", logIDs[0]).Error
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
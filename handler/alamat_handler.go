@@ -58,6 +58,8 @@ func (h *alamatHandler) CreateAlamat(c *fiber.Ctx) error {
 		NamaPenerima: newAlamat.NamaPenerima,
 		NoTelp:       newAlamat.NoTelp,
 		DetailAlamat: newAlamat.DetailAlamat,
+		IDProvinsi:   newAlamat.IDProvinsi,
+		IDKota:       newAlamat.IDKota,
 		UserID:       newAlamat.UserID,
 	}
 
@@ -92,6 +94,8 @@ func (h *alamatHandler) GetAllAlamat(c *fiber.Ctx) error {
 			NamaPenerima: alamat.NamaPenerima,
 			NoTelp:       alamat.NoTelp,
 			DetailAlamat: alamat.DetailAlamat,
+			IDProvinsi:   alamat.IDProvinsi,
+			IDKota:       alamat.IDKota,
 			UserID:       alamat.UserID,
 		})
 	}
@@ -152,6 +156,8 @@ func (h *alamatHandler) GetAlamatByID(c *fiber.Ctx) error {
 		NamaPenerima: alamat.NamaPenerima,
 		NoTelp:       alamat.NoTelp,
 		DetailAlamat: alamat.DetailAlamat,
+		IDProvinsi:   alamat.IDProvinsi,
+		IDKota:       alamat.IDKota,
 		UserID:       alamat.UserID,
 	}
 
@@ -238,6 +244,13 @@ func (h *alamatHandler) DeleteAlamat(c *fiber.Ctx) error {
 	// 3. Panggil service
 	err = h.alamatService.DeleteAlamat(userID, uint(alamatID))
 	if err != nil {
+		if strings.Contains(err.Error(), "tidak diizinkan") {
+			return c.Status(fiber.StatusConflict).JSON(web.WebResponse{ // 409 Conflict
+				Status:  false,
+				Message: "Gagal",
+				Errors:  err.Error(),
+			})
+		}
 		if strings.Contains(err.Error(), "Akses ditolak") {
 			return c.Status(fiber.StatusForbidden).JSON(web.WebResponse{
 				Status:  false,
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"github.com/Debjth19/go-evermos/helpers"
+	"github.com/Debjth19/go-evermos/model/web"
+
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// statusError menentukan status HTTP dan pesan untuk error service yang memiliki jenis
+// (helpers.ErrTidakValid dan seterusnya). ok bernilai false untuk error tanpa jenis.
+func statusError(err error) (status int, message string, ok bool) {
+	switch {
+	case errors.Is(err, helpers.ErrTidakValid):
+		return fiber.StatusBadRequest, "Bad Request", true
+	case errors.Is(err, helpers.ErrAksesDitolak):
+		return fiber.StatusForbidden, "Gagal", true
+	case errors.Is(err, helpers.ErrTidakDitemukan):
+		return fiber.StatusNotFound, "Gagal", true
+	case errors.Is(err, helpers.ErrTidakDiizinkan):
+		return fiber.StatusConflict, "Gagal", true // 409 Conflict
+	}
+	return fiber.StatusInternalServerError, "Server Error", false
+}
+
+// errorResponse memetakan error service ke status HTTP berdasarkan jenisnya.
+// Error tanpa jenis dianggap kesalahan server.
+func errorResponse(c *fiber.Ctx, err error) error {
+	status, message, _ := statusError(err)
+	return c.Status(status).JSON(web.WebResponse{
+		Status:  false,
+		Message: message,
+		Errors:  err.Error(),
+	})
+}
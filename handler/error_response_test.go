@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"github.com/Debjth19/go-evermos/helpers"
+	"github.com/Debjth19/go-evermos/service"
+
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestErrorResponse(t *testing.T) {
+	tests := []struct {
+		nama   string
+		err    error
+		status int
+	}{
+		{"tidak valid", helpers.Errorf(helpers.ErrTidakValid, "Catatan wajib diisi"), fiber.StatusBadRequest},
+		{"akses ditolak", helpers.Errorf(helpers.ErrAksesDitolak, "Bukan pemilik"), fiber.StatusForbidden},
+		{"tidak ditemukan", helpers.Errorf(helpers.ErrTidakDitemukan, "Data %d hilang", 7), fiber.StatusNotFound},
+		{"tidak diizinkan", helpers.Errorf(helpers.ErrTidakDiizinkan, "Sudah diproses"), fiber.StatusConflict},
+		{"dibungkus", fmt.Errorf("gagal: %w", helpers.Errorf(helpers.ErrTidakDitemukan, "x")), fiber.StatusNotFound},
+		// Pesan saja tidak menentukan status
+		{"tanpa jenis", errors.New("Produk tidak ditemukan"), fiber.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.nama, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", func(c *fiber.Ctx) error {
+				return errorResponse(c, tt.err)
+			})
+			resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status = %d, ingin %d", resp.StatusCode, tt.status)
+			}
+		})
+	}
+}
+
+func TestCheckoutErrorResponse(t *testing.T) {
+	tests := []struct {
+		nama   string
+		err    error
+		status int
+	}{
+		{"harga berubah", &service.PerubahanCheckoutError{}, fiber.StatusConflict},
+		{"keranjang sudah di-checkout", helpers.Errorf(helpers.ErrTidakDitemukan, "Item keranjang tidak ditemukan: sudah di-checkout atau dihapus"), fiber.StatusNotFound},
+		{"item kosong", helpers.Errorf(helpers.ErrTidakValid, "Item keranjang yang di-checkout wajib diisi"), fiber.StatusBadRequest},
+		{"tanpa jenis", errors.New("Gagal membuat transaksi"), fiber.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.nama, func(t *testing.T) {
+			app := fiber.New()
+			app.Post("/", func(c *fiber.Ctx) error {
+				return checkoutErrorResponse(c, tt.err)
+			})
+			resp, err := app.Test(httptest.NewRequest("POST", "/", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status = %d, ingin %d", resp.StatusCode, tt.status)
+			}
+		})
+	}
+}
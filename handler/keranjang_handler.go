@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"github.com/Debjth19/go-evermos/model"
+	"github.com/Debjth19/go-evermos/model/web"
+	"github.com/Debjth19/go-evermos/service"
+
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type KeranjangHandler interface {
+	GetKeranjang(c *fiber.Ctx) error
+	AddItem(c *fiber.Ctx) error
+	UpdateItem(c *fiber.Ctx) error
+	RemoveItem(c *fiber.Ctx) error
+	Checkout(c *fiber.Ctx) error
+}
+
+type keranjangHandler struct {
+	keranjangService service.KeranjangService
+}
+
+func NewKeranjangHandler(keranjangService service.KeranjangService) KeranjangHandler {
+	return &keranjangHandler{keranjangService: keranjangService}
+}
+
+// GetKeranjang menangani GET /user/keranjang
+func (h *keranjangHandler) GetKeranjang(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	baris, err := h.keranjangService.GetKeranjang(userID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Data:    mapKeranjangToResponse(baris),
+	})
+}
+
+// AddItem menangani POST /user/keranjang
+func (h *keranjangHandler) AddItem(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var request web.KeranjangAddRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{
+			Status:  false,
+			Message: "Bad Request",
+			Errors:  err.Error(),
+		})
+	}
+
+	item, err := h.keranjangService.AddItem(userID, request)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to POST data",
+		Data:    item.ID,
+	})
+}
+
+// UpdateItem menangani PUT /user/keranjang/:id
+func (h *keranjangHandler) UpdateItem(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	itemID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{
+			Status:  false,
+			Message: "Bad Request",
+			Errors:  "ID item keranjang tidak valid",
+		})
+	}
+
+	var request web.KeranjangUpdateRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{
+			Status:  false,
+			Message: "Bad Request",
+			Errors:  err.Error(),
+		})
+	}
+
+	item, err := h.keranjangService.UpdateItem(userID, uint(itemID), request)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to PUT data",
+		Data:    item.ID,
+	})
+}
+
+// RemoveItem menangani DELETE /user/keranjang/:id
+func (h *keranjangHandler) RemoveItem(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	itemID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{
+			Status:  false,
+			Message: "Bad Request",
+			Errors:  "ID item keranjang tidak valid",
+		})
+	}
+
+	if err := h.keranjangService.RemoveItem(userID, uint(itemID)); err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to DELETE data",
+		Data:    "Delete item keranjang succeed",
+	})
+}
+
+// Checkout menangani POST /user/keranjang/checkout
+func (h *keranjangHandler) Checkout(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var request web.KeranjangCheckoutRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{
+			Status:  false,
+			Message: "Bad Request",
+			Errors:  err.Error(),
+		})
+	}
+
+	transaksi, err := h.keranjangService.Checkout(userID, request)
+	if err != nil {
+		return checkoutErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to POST data",
+		Data:    transaksi.ID,
+	})
+}
+
+// --- Helper Mapping ---
+
+// mapKeranjangToResponse mengelompokkan item keranjang per toko sesuai urutan kemunculannya
+func mapKeranjangToResponse(baris []service.KeranjangBaris) web.KeranjangResponse {
+	response := web.KeranjangResponse{Toko: []web.KeranjangTokoResponse{}, SiapBayar: len(baris) > 0}
+	posisi := map[uint]int{}
+
+	for _, b := range baris {
+		item := mapKeranjangItemToResponse(b)
+
+		tokoID := b.Item.Produk.TokoID
+		i, ok := posisi[tokoID]
+		if !ok {
+			i = len(response.Toko)
+			posisi[tokoID] = i
+			response.Toko = append(response.Toko, web.KeranjangTokoResponse{
+				Toko: MapTokoToResponse(b.Item.Produk.Toko),
+			})
+		}
+		response.Toko[i].Items = append(response.Toko[i].Items, item)
+		response.Toko[i].Subtotal += item.Subtotal
+
+		response.TotalItem += item.Kuantitas
+		response.Total += item.Subtotal
+		if !item.StokCukup {
+			response.SiapBayar = false
+		}
+	}
+	return response
+}
+
+func mapKeranjangItemToResponse(b service.KeranjangBaris) web.KeranjangItemResponse {
+	produk := MapProdukToResponse(b.Item.Produk)
+	if b.TierHarga != model.TierReseller {
+		produk.HargaReseler = nil // Harga reseller hanya tampil untuk reseller
+		for i := range produk.Varian {
+			produk.Varian[i].HargaReseler = nil
+		}
+	}
+
+	var varian *web.VarianRingkasResponse
+	if b.Varian.ID != 0 {
+		varian = &web.VarianRingkasResponse{ID: b.Varian.ID, SKU: b.Varian.SKU, Nama: b.Varian.Nama}
+	}
+
+	var pesan string
+	switch {
+	case b.VarianWajib:
+		pesan = "Produk kini memiliki varian, hapus item lalu pilih varian"
+	case !b.StokCukup:
+		pesan = "Stok tersisa " + strconv.FormatUint(uint64(b.Stok), 10) + ", kurangi kuantitas"
+	case b.HargaBerubah:
+		pesan = "Harga produk berubah sejak ditambahkan ke keranjang"
+	}
+
+	return web.KeranjangItemResponse{
+		ID:              b.Item.ID,
+		Produk:          produk,
+		Varian:          varian,
+		Kuantitas:       b.Item.Kuantitas,
+		HargaSatuan:     b.HargaSatuan,
+		HargaSebelumnya: b.Item.HargaSatuan,
+		Subtotal:        b.HargaSatuan * b.Item.Kuantitas,
+		HargaBerubah:    b.HargaBerubah,
+		StokCukup:       b.StokCukup,
+		Pesan:           pesan,
+	}
+}
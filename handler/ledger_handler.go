@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"github.com/Debjth19/go-evermos/helpers"
+	"github.com/Debjth19/go-evermos/model"
+	"github.com/Debjth19/go-evermos/model/web"
+	"github.com/Debjth19/go-evermos/service"
+
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type LedgerHandler interface {
+	GetSaldo(c *fiber.Ctx) error
+	GetRiwayatSaldo(c *fiber.Ctx) error
+	CreatePenarikan(c *fiber.Ctx) error
+	GetMyPenarikan(c *fiber.Ctx) error
+	GetAllPenarikan(c *fiber.Ctx) error
+	ApprovePenarikan(c *fiber.Ctx) error
+	RejectPenarikan(c *fiber.Ctx) error
+}
+
+type ledgerHandler struct {
+	ledgerService service.LedgerService
+}
+
+func NewLedgerHandler(ledgerService service.LedgerService) LedgerHandler {
+	return &ledgerHandler{ledgerService: ledgerService}
+}
+
+// GetSaldo menangani GET /toko/my/saldo
+func (h *ledgerHandler) GetSaldo(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	saldo, err := h.ledgerService.GetSaldo(userID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Data:    saldo,
+	})
+}
+
+// GetRiwayatSaldo menangani GET /toko/my/saldo/riwayat
+func (h *ledgerHandler) GetRiwayatSaldo(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	pagination := helpers.GeneratePagination(c)
+
+	entries, total, err := h.ledgerService.GetRiwayatSaldo(userID, pagination)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	var response []web.LedgerEntryResponse
+	for _, entry := range entries {
+		response = append(response, web.LedgerEntryResponse{
+			ID:         entry.ID,
+			Referensi:  entry.Jurnal.Referensi,
+			Keterangan: entry.Keterangan,
+			Debit:      entry.Debit,
+			Kredit:     entry.Kredit,
+			CreatedAt:  entry.CreatedAt,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Data: web.PaginatedLedgerEntryResponse{
+			Page:       pagination.Page,
+			Limit:      pagination.Limit,
+			TotalItems: total,
+			TotalPages: helpers.TotalPages(total, pagination.Limit),
+			Data:       response,
+		},
+	})
+}
+
+// CreatePenarikan menangani POST /toko/my/penarikan
+func (h *ledgerHandler) CreatePenarikan(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var request web.PenarikanCreateRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{
+			Status:  false,
+			Message: "Bad Request",
+			Errors:  err.Error(),
+		})
+	}
+
+	penarikan, err := h.ledgerService.CreatePenarikan(userID, request)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to POST data",
+		Data:    mapPenarikanToResponse(penarikan),
+	})
+}
+
+// GetMyPenarikan menangani GET /toko/my/penarikan
+func (h *ledgerHandler) GetMyPenarikan(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	pagination := helpers.GeneratePagination(c)
+
+	penarikans, err := h.ledgerService.GetMyPenarikan(userID, pagination)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Data:    mapPenarikanPage(pagination, penarikans),
+	})
+}
+
+// GetAllPenarikan menangani GET /admin/penarikan
+func (h *ledgerHandler) GetAllPenarikan(c *fiber.Ctx) error {
+	pagination := helpers.GeneratePagination(c)
+
+	penarikans, err := h.ledgerService.GetAllPenarikan(pagination, c.Query("status"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Data:    mapPenarikanPage(pagination, penarikans),
+	})
+}
+
+// ApprovePenarikan menangani PUT /admin/penarikan/:id/approve
+func (h *ledgerHandler) ApprovePenarikan(c *fiber.Ctx) error {
+	return h.decide(c, h.ledgerService.ApprovePenarikan)
+}
+
+// RejectPenarikan menangani PUT /admin/penarikan/:id/reject
+func (h *ledgerHandler) RejectPenarikan(c *fiber.Ctx) error {
+	return h.decide(c, h.ledgerService.RejectPenarikan)
+}
+
+func (h *ledgerHandler) decide(c *fiber.Ctx, decide func(adminID uint, penarikanID uint, request web.PenarikanDecisionRequest) (model.Penarikan, error)) error {
+	adminID := c.Locals("user_id").(uint)
+
+	penarikanID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{
+			Status:  false,
+			Message: "Bad Request",
+			Errors:  "ID penarikan tidak valid",
+		})
+	}
+
+	// Body opsional untuk persetujuan, catatan wajib untuk penolakan
+	var request web.PenarikanDecisionRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{
+				Status:  false,
+				Message: "Bad Request",
+				Errors:  err.Error(),
+			})
+		}
+	}
+
+	penarikan, err := decide(adminID, uint(penarikanID), request)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to PUT data",
+		Data:    mapPenarikanToResponse(penarikan),
+	})
+}
+
+// --- Helper Mapping ---
+
+func mapPenarikanPage(pagination helpers.Pagination, penarikans []model.Penarikan) web.PaginatedPenarikanResponse {
+	var response []web.PenarikanResponse
+	for _, penarikan := range penarikans {
+		response = append(response, mapPenarikanToResponse(penarikan))
+	}
+	return web.PaginatedPenarikanResponse{
+		Page:  pagination.Page,
+		Limit: pagination.Limit,
+		Data:  response,
+	}
+}
+
+func mapPenarikanToResponse(p model.Penarikan) web.PenarikanResponse {
+	return web.PenarikanResponse{
+		ID:           p.ID,
+		TokoID:       p.TokoID,
+		NamaToko:     p.Toko.NamaToko,
+		Jumlah:       p.Jumlah,
+		NamaBank:     p.NamaBank,
+		NoRekening:   p.NoRekening,
+		AtasNama:     p.AtasNama,
+		Status:       p.Status,
+		CatatanAdmin: p.CatatanAdmin,
+		ReviewedAt:   p.ReviewedAt,
+		CreatedAt:    p.CreatedAt,
+	}
+}
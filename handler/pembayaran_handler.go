@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"github.com/Debjth19/go-evermos/model/web"
+	"github.com/Debjth19/go-evermos/payment"
+	"github.com/Debjth19/go-evermos/service"
+
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PembayaranHandler interface {
+	GetMethods(c *fiber.Ctx) error
+	Callback(c *fiber.Ctx) error
+}
+
+type pembayaranHandler struct {
+	pembayaranService service.PembayaranService
+}
+
+func NewPembayaranHandler(pembayaranService service.PembayaranService) PembayaranHandler {
+	return &pembayaranHandler{pembayaranService: pembayaranService}
+}
+
+// GetMethods menangani GET /payments/methods
+func (h *pembayaranHandler) GetMethods(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Data:    h.pembayaranService.GetMethods(),
+	})
+}
+
+// Callback menangani POST /payments/callback/:provider
+func (h *pembayaranHandler) Callback(c *fiber.Ctx) error {
+	// 1. Ambil signature dari header
+	signature := c.Get("X-Callback-Signature")
+
+	// 2. Panggil service dengan body mentah agar signature dapat diverifikasi
+	transaksi, err := h.pembayaranService.HandleCallback(c.Params("provider"), c.Body(), signature)
+	if err != nil {
+		// Signature yang tidak cocok berarti callback tidak berasal dari provider
+		if errors.Is(err, payment.ErrInvalidSignature) {
+			return c.Status(fiber.StatusUnauthorized).JSON(web.WebResponse{ // 401 Unauthorized
+				Status:  false,
+				Message: "Gagal",
+				Errors:  err.Error(),
+			})
+		}
+		return errorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to POST data",
+		Data:    transaksi.ID,
+	})
+}
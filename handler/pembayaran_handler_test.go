@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"github.com/Debjth19/go-evermos/helpers"
+	"github.com/Debjth19/go-evermos/model"
+	"github.com/Debjth19/go-evermos/payment"
+	"github.com/Debjth19/go-evermos/service"
+
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// pembayaranServiceStub mengembalikan error yang sudah ditentukan dari HandleCallback
+type pembayaranServiceStub struct {
+	err error
+}
+
+func (s pembayaranServiceStub) GetMethods() []string {
+	return nil
+}
+
+func (s pembayaranServiceStub) HandleCallback(providerName string, body []byte, signature string) (model.Transaksi, error) {
+	return model.Transaksi{}, s.err
+}
+
+func TestCallbackErrorStatus(t *testing.T) {
+	tests := []struct {
+		nama   string
+		err    error
+		status int
+	}{
+		{"signature tidak valid", payment.ErrInvalidSignature, fiber.StatusUnauthorized},
+		{"callback tidak sesuai tagihan", fmt.Errorf("%w: referensi berbeda", service.ErrCallbackTidakSesuai), fiber.StatusBadRequest},
+		{"provider tidak ditemukan", helpers.Errorf(helpers.ErrTidakDitemukan, "Provider pembayaran x tidak ditemukan"), fiber.StatusNotFound},
+		{"sudah dibayar", helpers.Errorf(helpers.ErrTidakDiizinkan, "Transisi status tidak diizinkan"), fiber.StatusConflict},
+		// Pesan yang menyebut signature tidak menentukan status
+		{"pesan menyebut signature", errors.New("Signature server gagal dibaca"), fiber.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.nama, func(t *testing.T) {
+			app := fiber.New()
+			app.Post("/:provider", NewPembayaranHandler(pembayaranServiceStub{err: tt.err}).Callback)
+			resp, err := app.Test(httptest.NewRequest("POST", "/fake", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status = %d, ingin %d", resp.StatusCode, tt.status)
+			}
+		})
+	}
+}
@@ -60,6 +60,15 @@ func parseProdukCreateRequest(c *fiber.Ctx) (web.ProdukCreateRequest, error) {
 	}
 	request.Stok = uint(stok)
 
+	// Berat opsional, produk tanpa berat dihitung 1 kg saat menghitung ongkos kirim
+	if c.FormValue("berat") != "" {
+		berat, err := strconv.Atoi(c.FormValue("berat"))
+		if err != nil || berat < 0 {
+			return request, errors.New("berat tidak valid")
+		}
+		request.Berat = uint(berat)
+	}
+
 	return request, nil
 }
 
@@ -118,11 +127,19 @@ func (h *produkHandler) GetAllProduk(c *fiber.Ctx) error {
 		"toko_id":     c.Query("toko_id"),
 		"min_harga":   c.Query("min_harga"),
 		"max_harga":   c.Query("max_harga"),
+		"sort":        c.Query("sort"),
 	}
 
 	// 3. Panggil service
-	produks, err := h.produkService.GetAllProduk(pagination, filterParams)
+	produks, err := h.produkService.GetAllProduk(&pagination, filterParams)
 	if err != nil {
+		if strings.Contains(err.Error(), "tidak dikenal") || strings.Contains(err.Error(), "tidak valid") {
+			return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{
+				Status:  false,
+				Message: "Bad Request",
+				Errors:  err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(web.WebResponse{
 			Status:  false,
 			Message: "Server Error",
@@ -133,13 +150,16 @@ func (h *produkHandler) GetAllProduk(c *fiber.Ctx) error {
 	// 4. Buat respons
 	var response []web.ProdukResponse
 	for _, p := range produks {
-		response = append(response, MapProdukToResponse(p))
+		response = append(response, mapProdukForViewer(c, p))
 	}
 
 	paginatedResponse := web.PaginatedProdukResponse{
-		Page:  pagination.Page,
-		Limit: pagination.Limit,
-		Data:  response,
+		Page:       pagination.Page,
+		Limit:      pagination.Limit,
+		TotalItems: pagination.TotalItems,
+		TotalPages: pagination.TotalPages,
+		NextCursor: pagination.NextCursor,
+		Data:       response,
 	}
 
 	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
@@ -172,7 +192,7 @@ func (h *produkHandler) GetProdukByID(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
 		Status:  true,
 		Message: "Succeed to GET data",
-		Data:    MapProdukToResponse(produk),
+		Data:    mapProdukForViewer(c, produk),
 	})
 }
 
@@ -193,6 +213,9 @@ func parseProdukUpdateRequest(c *fiber.Ctx) web.ProdukUpdateRequest {
 	if stok, err := strconv.Atoi(c.FormValue("stok")); err == nil {
 		request.Stok = uint(stok)
 	}
+	if berat, err := strconv.Atoi(c.FormValue("berat")); err == nil && berat > 0 {
+		request.Berat = uint(berat)
+	}
 	return request
 }
 
@@ -216,6 +239,13 @@ func (h *produkHandler) UpdateProduk(c *fiber.Ctx) error {
 
 	_, err = h.produkService.UpdateProduk(userID, uint(produkID), request, files)
 	if err != nil {
+		if strings.Contains(err.Error(), "tidak valid") {
+			return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{
+				Status:  false,
+				Message: "Bad Request",
+				Errors:  err.Error(),
+			})
+		}
 		if strings.Contains(err.Error(), "Akses ditolak") {
 			return c.Status(fiber.StatusForbidden).JSON(web.WebResponse{
 				Status:  false,
@@ -276,14 +306,32 @@ func (h *produkHandler) DeleteProduk(c *fiber.Ctx) error {
 }
 
 // --- Helper untuk mapping ---
+
+// mapProdukForViewer menyembunyikan harga reseller kecuali untuk reseller,
+// admin, dan pemilik toko produk tersebut (dibaca dari OptionalAuthMiddleware)
+func mapProdukForViewer(c *fiber.Ctx, p model.Produk) web.ProdukResponse {
+	response := MapProdukToResponse(p)
+
+	role, _ := c.Locals("role").(string)
+	userID, _ := c.Locals("user_id").(uint)
+	isOwner := userID != 0 && p.Toko.UserID == userID
+	if role != model.RoleReseller && role != model.RoleAdmin && !isOwner {
+		response.HargaReseler = nil
+		for i := range response.Varian {
+			response.Varian[i].HargaReseler = nil
+		}
+	}
+	return response
+}
 func MapProdukToResponse(p model.Produk) web.ProdukResponse {
 	return web.ProdukResponse{
 		ID:            p.ID,
 		NamaProduk:    p.NamaProduk,
 		Slug:          p.Slug,
-		HargaReseler:  p.HargaReseler,
+		HargaReseler:  &p.HargaReseler,
 		HargaKonsumen: p.HargaKonsumen,
 		Stok:          p.Stok,
+		Berat:         p.Berat,
 		Deskripsi:     p.Deskripsi,
 		Toko: web.TokoResponse{
 			ID:       p.Toko.ID,
@@ -295,7 +343,51 @@ func MapProdukToResponse(p model.Produk) web.ProdukResponse {
 			NamaCategory: p.Category.NamaCategory,
 		},
 		Photos: MapFotosToResponse(p.FotoProduk),
+		Opsi:   MapOpsiToResponse(p.Opsi),
+		Varian: MapVarianToResponse(p),
+	}
+}
+
+func MapOpsiToResponse(opsi []model.OpsiProduk) []web.OpsiProdukResponse {
+	var response []web.OpsiProdukResponse
+	for _, o := range opsi {
+		response = append(response, web.OpsiProdukResponse{
+			ID:    o.ID,
+			Nama:  o.Nama,
+			Nilai: mapNilaiOpsiToResponse(o.Nilai),
+		})
+	}
+	return response
+}
+
+// MapVarianToResponse memetakan varian produk dengan harga efektifnya
+func MapVarianToResponse(p model.Produk) []web.VarianProdukResponse {
+	var response []web.VarianProdukResponse
+	for _, v := range p.Varian {
+		harga := p.DenganVarian(v)
+		response = append(response, web.VarianProdukResponse{
+			ID:            v.ID,
+			SKU:           v.SKU,
+			Nama:          v.Nama,
+			NilaiOpsi:     mapNilaiOpsiToResponse(v.NilaiOpsi),
+			HargaReseler:  &harga.HargaReseler,
+			HargaKonsumen: harga.HargaKonsumen,
+			Stok:          v.Stok,
+			UrlFoto:       v.UrlFoto,
+		})
+	}
+	return response
+}
+
+func mapNilaiOpsiToResponse(nilai []model.NilaiOpsiProduk) []web.NilaiOpsiResponse {
+	var response []web.NilaiOpsiResponse
+	for _, n := range nilai {
+		response = append(response, web.NilaiOpsiResponse{
+			ID:    n.ID,
+			Nilai: n.Nilai,
+		})
 	}
+	return response
 }
 
 func MapTokoToResponse(t model.Toko) web.TokoResponse {
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"github.com/Debjth19/go-evermos/helpers"
+	"github.com/Debjth19/go-evermos/model"
+	"github.com/Debjth19/go-evermos/model/web"
+	"github.com/Debjth19/go-evermos/service"
+
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ResellerHandler interface {
+	Apply(c *fiber.Ctx) error
+	GetMyApplication(c *fiber.Ctx) error
+	GetApplications(c *fiber.Ctx) error
+	Approve(c *fiber.Ctx) error
+	Reject(c *fiber.Ctx) error
+	GetMarginReport(c *fiber.Ctx) error
+}
+
+type resellerHandler struct {
+	resellerService service.ResellerService
+}
+
+func NewResellerHandler(resellerService service.ResellerService) ResellerHandler {
+	return &resellerHandler{resellerService: resellerService}
+}
+
+// Apply menangani POST /user/reseller
+func (h *resellerHandler) Apply(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var request web.ResellerApplyRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{
+			Status:  false,
+			Message: "Bad Request",
+			Errors:  err.Error(),
+		})
+	}
+
+	pengajuan, err := h.resellerService.Apply(userID, request)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to POST data",
+		Data:    mapPengajuanResellerToResponse(pengajuan),
+	})
+}
+
+// GetMyApplication menangani GET /user/reseller
+func (h *resellerHandler) GetMyApplication(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	pengajuan, err := h.resellerService.GetMyApplication(userID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Data:    mapPengajuanResellerToResponse(pengajuan),
+	})
+}
+
+// GetApplications menangani GET /admin/reseller
+func (h *resellerHandler) GetApplications(c *fiber.Ctx) error {
+	pagination := helpers.GeneratePagination(c)
+
+	pengajuans, err := h.resellerService.GetApplications(pagination, c.Query("status"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	var response []web.PengajuanResellerResponse
+	for _, pengajuan := range pengajuans {
+		response = append(response, mapPengajuanResellerToResponse(pengajuan))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Data: web.PaginatedPengajuanResellerResponse{
+			Page:  pagination.Page,
+			Limit: pagination.Limit,
+			Data:  response,
+		},
+	})
+}
+
+// Approve menangani PUT /admin/reseller/:id/approve
+func (h *resellerHandler) Approve(c *fiber.Ctx) error {
+	return h.decide(c, h.resellerService.Approve)
+}
+
+// Reject menangani PUT /admin/reseller/:id/reject
+func (h *resellerHandler) Reject(c *fiber.Ctx) error {
+	return h.decide(c, h.resellerService.Reject)
+}
+
+// GetMarginReport menangani GET /user/reseller/margin
+func (h *resellerHandler) GetMarginReport(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	filterParams := map[string]string{
+		"periode":        c.Query("periode"),
+		"tanggal_dari":   c.Query("tanggal_dari"),
+		"tanggal_sampai": c.Query("tanggal_sampai"),
+	}
+
+	report, err := h.resellerService.GetMarginReport(userID, filterParams)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Data:    report,
+	})
+}
+
+func (h *resellerHandler) decide(c *fiber.Ctx, decide func(adminID uint, pengajuanID uint, request web.ResellerDecisionRequest) (model.PengajuanReseller, error)) error {
+	adminID := c.Locals("user_id").(uint)
+
+	pengajuanID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{
+			Status:  false,
+			Message: "Bad Request",
+			Errors:  "ID pengajuan tidak valid",
+		})
+	}
+
+	// Body opsional untuk persetujuan, catatan wajib untuk penolakan
+	var request web.ResellerDecisionRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{
+				Status:  false,
+				Message: "Bad Request",
+				Errors:  err.Error(),
+			})
+		}
+	}
+
+	pengajuan, err := decide(adminID, uint(pengajuanID), request)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to PUT data",
+		Data:    mapPengajuanResellerToResponse(pengajuan),
+	})
+}
+
+// --- Helper Mapping ---
+
+func mapPengajuanResellerToResponse(p model.PengajuanReseller) web.PengajuanResellerResponse {
+	return web.PengajuanResellerResponse{
+		ID:           p.ID,
+		UserID:       p.UserID,
+		NamaUser:     p.User.Nama,
+		Alasan:       p.Alasan,
+		Status:       p.Status,
+		CatatanAdmin: p.CatatanAdmin,
+		ReviewedAt:   p.ReviewedAt,
+		CreatedAt:    p.CreatedAt,
+	}
+}
@@ -0,0 +1,254 @@
+package handler
+
+import (
+	"github.com/Debjth19/go-evermos/helpers"
+	"github.com/Debjth19/go-evermos/model"
+	"github.com/Debjth19/go-evermos/model/web"
+	"github.com/Debjth19/go-evermos/service"
+
+	"encoding/json"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReturHandler interface {
+	CreateRetur(c *fiber.Ctx) error
+	GetMyReturs(c *fiber.Ctx) error
+	GetMyReturByID(c *fiber.Ctx) error
+	GetTokoReturs(c *fiber.Ctx) error
+	GetTokoReturByID(c *fiber.Ctx) error
+	ApproveRetur(c *fiber.Ctx) error
+	RejectRetur(c *fiber.Ctx) error
+}
+
+type returHandler struct {
+	returService service.ReturService
+}
+
+func NewReturHandler(returService service.ReturService) ReturHandler {
+	return &returHandler{returService: returService}
+}
+
+// CreateRetur menangani POST /trx/:id/retur (form-data: alasan, items, photos)
+func (h *returHandler) CreateRetur(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	trxID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{
+			Status:  false,
+			Message: "Bad Request",
+			Errors:  "ID transaksi tidak valid",
+		})
+	}
+
+	// 1. Parse request form-data
+	request := web.ReturCreateRequest{Alasan: c.FormValue("alasan")}
+	if err := json.Unmarshal([]byte(c.FormValue("items")), &request.Items); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{
+			Status:  false,
+			Message: "Bad Request",
+			Errors:  "items tidak valid",
+		})
+	}
+
+	// 2. Ambil file (photos)
+	form, err := c.MultipartForm()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{
+			Status:  false,
+			Message: "Bad Request",
+			Errors:  "Gagal memproses form: " + err.Error(),
+		})
+	}
+	files := form.File["photos"]
+
+	// 3. Panggil service
+	retur, err := h.returService.CreateRetur(userID, uint(trxID), request, files)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to POST data",
+		Data:    mapReturToResponse(retur),
+	})
+}
+
+// GetMyReturs menangani GET /trx/retur
+func (h *returHandler) GetMyReturs(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	returs, err := h.returService.GetMyReturs(userID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	var response []web.ReturResponse
+	for _, retur := range returs {
+		response = append(response, mapReturToResponse(retur))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Data:    response,
+	})
+}
+
+// GetMyReturByID menangani GET /trx/retur/:id
+func (h *returHandler) GetMyReturByID(c *fiber.Ctx) error {
+	return h.getOne(c, h.returService.GetMyReturByID)
+}
+
+// GetTokoReturs menangani GET /toko/my/retur
+func (h *returHandler) GetTokoReturs(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	pagination := helpers.GeneratePagination(c)
+
+	returs, err := h.returService.GetTokoReturs(userID, pagination, c.Query("status"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	var response []web.ReturResponse
+	for _, retur := range returs {
+		response = append(response, mapReturToResponse(retur))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Data: web.PaginatedReturResponse{
+			Page:  pagination.Page,
+			Limit: pagination.Limit,
+			Data:  response,
+		},
+	})
+}
+
+// GetTokoReturByID menangani GET /toko/my/retur/:id
+func (h *returHandler) GetTokoReturByID(c *fiber.Ctx) error {
+	return h.getOne(c, h.returService.GetTokoReturByID)
+}
+
+// ApproveRetur menangani PUT /toko/my/retur/:id/approve
+func (h *returHandler) ApproveRetur(c *fiber.Ctx) error {
+	return h.decide(c, h.returService.ApproveRetur)
+}
+
+// RejectRetur menangani PUT /toko/my/retur/:id/reject
+func (h *returHandler) RejectRetur(c *fiber.Ctx) error {
+	return h.decide(c, h.returService.RejectRetur)
+}
+
+func (h *returHandler) getOne(c *fiber.Ctx, find func(userID uint, returID uint) (model.Retur, error)) error {
+	userID := c.Locals("user_id").(uint)
+
+	returID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{
+			Status:  false,
+			Message: "Bad Request",
+			Errors:  "ID retur tidak valid",
+		})
+	}
+
+	retur, err := find(userID, uint(returID))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Data:    mapReturToResponse(retur),
+	})
+}
+
+func (h *returHandler) decide(c *fiber.Ctx, decide func(userID uint, returID uint, request web.ReturDecisionRequest) (model.Retur, error)) error {
+	userID := c.Locals("user_id").(uint)
+
+	returID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{
+			Status:  false,
+			Message: "Bad Request",
+			Errors:  "ID retur tidak valid",
+		})
+	}
+
+	// Body opsional untuk persetujuan, catatan wajib untuk penolakan
+	var request web.ReturDecisionRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{
+				Status:  false,
+				Message: "Bad Request",
+				Errors:  err.Error(),
+			})
+		}
+	}
+
+	retur, err := decide(userID, uint(returID), request)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to PUT data",
+		Data:    mapReturToResponse(retur),
+	})
+}
+
+// --- Helper Mapping ---
+
+func mapReturToResponse(r model.Retur) web.ReturResponse {
+	items := []web.ReturItemResponse{}
+	for _, item := range r.Items {
+		items = append(items, web.ReturItemResponse{
+			ID:                item.ID,
+			DetailTransaksiID: item.DetailTransaksiID,
+			ProductID:         item.ProductID,
+			NamaProduk:        item.NamaProduk,
+			Kuantitas:         item.Kuantitas,
+			JumlahRefund:      item.JumlahRefund,
+		})
+	}
+
+	photos := []web.FotoReturResponse{}
+	for _, foto := range r.FotoRetur {
+		photos = append(photos, web.FotoReturResponse{ID: foto.ID, Url: foto.Url})
+	}
+
+	var refund *web.RefundResponse
+	if r.Refund != nil {
+		refund = &web.RefundResponse{
+			ID:           r.Refund.ID,
+			PembayaranID: r.Refund.PembayaranID,
+			Jumlah:       r.Refund.Jumlah,
+			Status:       r.Refund.Status,
+			CreatedAt:    r.Refund.CreatedAt,
+		}
+	}
+
+	return web.ReturResponse{
+		ID:             r.ID,
+		TransaksiID:    r.TransaksiID,
+		KodeInvoice:    r.Transaksi.KodeInvoice,
+		TokoID:         r.TokoID,
+		UserID:         r.UserID,
+		Alasan:         r.Alasan,
+		Status:         r.Status,
+		CatatanPenjual: r.CatatanPenjual,
+		Items:          items,
+		Photos:         photos,
+		Refund:         refund,
+		ApprovedAt:     r.ApprovedAt,
+		RejectedAt:     r.RejectedAt,
+		CreatedAt:      r.CreatedAt,
+	}
+}
@@ -43,10 +43,12 @@ func (h *tokoHandler) GetMyToko(c *fiber.Ctx) error {
 
 	// 3. Buat respons
 	response := web.TokoResponse{
-		ID:       toko.ID,
-		NamaToko: toko.NamaToko,
-		UrlFoto:  toko.UrlFoto,
-		UserID:   toko.UserID,
+		ID:         toko.ID,
+		NamaToko:   toko.NamaToko,
+		UrlFoto:    toko.UrlFoto,
+		UserID:     toko.UserID,
+		IDProvinsi: toko.IDProvinsi,
+		IDKota:     toko.IDKota,
 	}
 
 	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
@@ -98,8 +100,15 @@ func (h *tokoHandler) GetAllToko(c *fiber.Ctx) error {
 
 	// 2. Ambil query param untuk filter nama 
 	search := c.Query("nama") 
-	tokos, err := h.tokoService.GetAllToko(pagination, search)
+	tokos, err := h.tokoService.GetAllToko(&pagination, search)
 	if err != nil {
+		if strings.Contains(err.Error(), "tidak valid") {
+			return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{
+				Status:  false,
+				Message: "Bad Request",
+				Errors:  err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(web.WebResponse{
 			Status:  false,
 			Message: "Server Error",
@@ -119,9 +128,12 @@ func (h *tokoHandler) GetAllToko(c *fiber.Ctx) error {
 
 	// 5. Buat respons paginasi
 	paginatedResponse := web.PaginatedTokoResponse{
-		Page:  pagination.Page,
-		Limit: pagination.Limit,
-		Data:  response,
+		Page:       pagination.Page,
+		Limit:      pagination.Limit,
+		TotalItems: pagination.TotalItems,
+		TotalPages: pagination.TotalPages,
+		NextCursor: pagination.NextCursor,
+		Data:       response,
 	}
 
 	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
@@ -148,7 +160,9 @@ func (h *tokoHandler) UpdateToko(c *fiber.Ctx) error {
 
 	// 3. Ambil data form-data
 	request := web.TokoUpdateRequest{
-		NamaToko: c.FormValue("nama_toko"),
+		NamaToko:   c.FormValue("nama_toko"),
+		IDProvinsi: c.FormValue("id_provinsi"),
+		IDKota:     c.FormValue("id_kota"),
 	}
 
 	// 4. Ambil file dari form-data 
@@ -1,10 +1,13 @@
 package handler
 
 import (
+	"github.com/Debjth19/go-evermos/helpers"
 	"github.com/Debjth19/go-evermos/model"
 	"github.com/Debjth19/go-evermos/model/web"
 	"github.com/Debjth19/go-evermos/service"
 
+	"errors"
+	"fmt"
 	"strconv"
 	"strings"
 
@@ -13,8 +16,17 @@ import (
 
 type TransaksiHandler interface {
 	CreateTransaksi(c *fiber.Ctx) error
+	QuoteOngkir(c *fiber.Ctx) error
 	GetMyTransactions(c *fiber.Ctx) error
 	GetMyTransactionByID(c *fiber.Ctx) error
+	UpdateStatusByBuyer(c *fiber.Ctx) error
+	UpdateStatusBySeller(c *fiber.Ctx) error
+	CancelByBuyer(c *fiber.Ctx) error
+	GetTokoOrders(c *fiber.Ctx) error
+	GetInvoicePDF(c *fiber.Ctx) error
+	GetTokoInvoicePDF(c *fiber.Ctx) error
+	GetItemSnapshot(c *fiber.Ctx) error
+	GetTokoItemSnapshot(c *fiber.Ctx) error
 }
 
 type transaksiHandler struct {
@@ -43,26 +55,38 @@ func (h *transaksiHandler) CreateTransaksi(c *fiber.Ctx) error {
 	// 3. Panggil service
 	transaksi, err := h.transaksiService.CreateTransaksi(userID, request)
 	if err != nil {
-		if strings.Contains(err.Error(), "Stok tidak mencukupi") ||
-			strings.Contains(err.Error(), "tidak ditemukan") ||
-			strings.Contains(err.Error(), "Akses ditolak") {
-			return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{ // 400 Bad Request
-				Status:  false,
-				Message: "Gagal membuat transaksi",
-				Errors:  err.Error(),
-			})
-		}
-		return c.Status(fiber.StatusInternalServerError).JSON(web.WebResponse{
+		return checkoutErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to POST data",
+		Data:    transaksi.ID,
+	})
+}
+
+// QuoteOngkir menangani POST /trx/quote
+func (h *transaksiHandler) QuoteOngkir(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var request web.OngkirQuoteRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{
 			Status:  false,
-			Message: "Server Error",
+			Message: "Bad Request",
 			Errors:  err.Error(),
 		})
 	}
 
+	quotes, err := h.transaksiService.QuoteOngkir(userID, request)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
 	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
 		Status:  true,
 		Message: "Succeed to POST data",
-		Data:    transaksi.ID,
+		Data:    quotes,
 	})
 }
 
@@ -70,16 +94,25 @@ func (h *transaksiHandler) CreateTransaksi(c *fiber.Ctx) error {
 func (h *transaksiHandler) GetMyTransactions(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(uint)
 
-	transaksis, err := h.transaksiService.GetMyTransactions(userID)
+	// 1. Ambil query params untuk pagination dan filter
+	pagination := helpers.GeneratePagination(c)
+	filterParams := map[string]string{
+		"status":         c.Query("status"),
+		"tanggal_dari":   c.Query("tanggal_dari"),
+		"tanggal_sampai": c.Query("tanggal_sampai"),
+		"kode_invoice":   c.Query("kode_invoice"),
+		"method_bayar":   c.Query("method_bayar"),
+		"min_total":      c.Query("min_total"),
+		"max_total":      c.Query("max_total"),
+	}
+
+	// 2. Panggil service
+	transaksis, err := h.transaksiService.GetMyTransactions(userID, &pagination, filterParams)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(web.WebResponse{
-			Status:  false,
-			Message: "Server Error",
-			Errors:  err.Error(),
-		})
+		return errorResponse(c, err)
 	}
 
-	// Buat respons
+	// 3. Buat respons
 	var response []web.TransaksiResponse
 	for _, trx := range transaksis {
 		response = append(response, mapTransaksiToResponse(trx))
@@ -88,7 +121,14 @@ func (h *transaksiHandler) GetMyTransactions(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
 		Status:  true,
 		Message: "Succeed to GET data",
-		Data:    web.PaginatedTransaksiResponse{Data: response}, // Sesuai Postman, ada 'data' di dalamnya
+		Data: web.PaginatedTransaksiResponse{ // Sesuai Postman, ada 'data' di dalamnya
+			Page:       pagination.Page,
+			Limit:      pagination.Limit,
+			TotalItems: pagination.TotalItems,
+			TotalPages: pagination.TotalPages,
+			NextCursor: pagination.NextCursor,
+			Data:       response,
+		},
 	})
 }
 
@@ -121,39 +161,368 @@ func (h *transaksiHandler) GetMyTransactionByID(c *fiber.Ctx) error {
 	})
 }
 
+// GetTokoOrders menangani GET /toko/my/orders
+func (h *transaksiHandler) GetTokoOrders(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	// 1. Ambil query params untuk pagination dan filter
+	pagination := helpers.GeneratePagination(c)
+	filterParams := map[string]string{
+		"status":         c.Query("status"),
+		"tanggal_dari":   c.Query("tanggal_dari"),
+		"tanggal_sampai": c.Query("tanggal_sampai"),
+	}
+
+	// 2. Panggil service
+	transaksis, err := h.transaksiService.GetTokoOrders(userID, &pagination, filterParams)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	// 3. Buat respons
+	var response []web.TransaksiResponse
+	for _, trx := range transaksis {
+		response = append(response, mapTransaksiToResponse(trx))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Data: web.PaginatedTransaksiResponse{
+			Page:       pagination.Page,
+			Limit:      pagination.Limit,
+			TotalItems: pagination.TotalItems,
+			TotalPages: pagination.TotalPages,
+			NextCursor: pagination.NextCursor,
+			Data:       response,
+		},
+	})
+}
+
+// UpdateStatusByBuyer menangani PUT /trx/:id/status
+func (h *transaksiHandler) UpdateStatusByBuyer(c *fiber.Ctx) error {
+	return h.updateStatus(c, h.transaksiService.UpdateStatusByBuyer)
+}
+
+// UpdateStatusBySeller menangani PUT /toko/my/orders/:id/status
+func (h *transaksiHandler) UpdateStatusBySeller(c *fiber.Ctx) error {
+	return h.updateStatus(c, h.transaksiService.UpdateStatusBySeller)
+}
+
+func (h *transaksiHandler) updateStatus(c *fiber.Ctx, update func(userID uint, trxID uint, request web.TransaksiStatusUpdateRequest) (model.Transaksi, error)) error {
+	userID := c.Locals("user_id").(uint)
+
+	trxID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{
+			Status:  false,
+			Message: "Bad Request",
+			Errors:  "ID transaksi tidak valid",
+		})
+	}
+
+	var request web.TransaksiStatusUpdateRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{
+			Status:  false,
+			Message: "Bad Request",
+			Errors:  err.Error(),
+		})
+	}
+
+	transaksi, err := update(userID, uint(trxID), request)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to UPDATE data",
+		Data:    mapTransaksiToResponse(transaksi),
+	})
+}
+
+// CancelByBuyer menangani POST /trx/:id/cancel
+func (h *transaksiHandler) CancelByBuyer(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	trxID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{
+			Status:  false,
+			Message: "Bad Request",
+			Errors:  "ID transaksi tidak valid",
+		})
+	}
+
+	var request web.TransaksiCancelRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{
+			Status:  false,
+			Message: "Bad Request",
+			Errors:  err.Error(),
+		})
+	}
+
+	transaksi, err := h.transaksiService.CancelByBuyer(userID, uint(trxID), request)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to UPDATE data",
+		Data:    mapTransaksiToResponse(transaksi),
+	})
+}
+
+// GetInvoicePDF menangani GET /trx/:id/invoice.pdf
+func (h *transaksiHandler) GetInvoicePDF(c *fiber.Ctx) error {
+	return h.sendInvoicePDF(c, h.transaksiService.GetInvoicePDF)
+}
+
+// GetTokoInvoicePDF menangani GET /toko/my/orders/:id/invoice.pdf
+func (h *transaksiHandler) GetTokoInvoicePDF(c *fiber.Ctx) error {
+	return h.sendInvoicePDF(c, h.transaksiService.GetTokoInvoicePDF)
+}
+
+// GetItemSnapshot menangani GET /trx/items/:id/snapshot
+func (h *transaksiHandler) GetItemSnapshot(c *fiber.Ctx) error {
+	return h.sendItemSnapshot(c, h.transaksiService.GetItemSnapshot)
+}
+
+// GetTokoItemSnapshot menangani GET /toko/my/orders/items/:id/snapshot
+func (h *transaksiHandler) GetTokoItemSnapshot(c *fiber.Ctx) error {
+	return h.sendItemSnapshot(c, h.transaksiService.GetTokoItemSnapshot)
+}
+
+func (h *transaksiHandler) sendItemSnapshot(c *fiber.Ctx, find func(userID uint, detailID uint) (model.DetailTransaksi, error)) error {
+	userID := c.Locals("user_id").(uint)
+
+	detailID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{
+			Status:  false,
+			Message: "Bad Request",
+			Errors:  "ID item transaksi tidak valid",
+		})
+	}
+
+	detail, err := find(userID, uint(detailID))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Data:    mapProdukSnapshotToResponse(detail),
+	})
+}
+
+func (h *transaksiHandler) sendInvoicePDF(c *fiber.Ctx, generate func(userID uint, trxID uint) (model.Transaksi, []byte, error)) error {
+	userID := c.Locals("user_id").(uint)
+
+	trxID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{
+			Status:  false,
+			Message: "Bad Request",
+			Errors:  "ID transaksi tidak valid",
+		})
+	}
+
+	transaksi, pdf, err := generate(userID, uint(trxID))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	filename := strings.ReplaceAll(transaksi.KodeInvoice, "/", "-") + ".pdf"
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("inline; filename=%q", filename))
+	return c.Status(fiber.StatusOK).Send(pdf)
+}
+
+// checkoutErrorResponse memetakan error pembuatan transaksi ke status HTTP
+func checkoutErrorResponse(c *fiber.Ctx, err error) error {
+	// Harga atau stok berubah sejak ditampilkan, kirim rincian agar pembeli dapat mengonfirmasi ulang
+	var perubahan *service.PerubahanCheckoutError
+	if errors.As(err, &perubahan) {
+		return c.Status(fiber.StatusConflict).JSON(web.WebResponse{ // 409 Conflict
+			Status:  false,
+			Message: "Gagal membuat transaksi",
+			Errors:  err.Error(),
+			Data:    perubahan.Items,
+		})
+	}
+	// Error lain dipetakan sesuai jenisnya
+	return errorResponse(c, err)
+}
+
 // --- Helper Mapping ---
 
 func mapTransaksiToResponse(t model.Transaksi) web.TransaksiResponse {
+	var subOrders []web.TransaksiResponse
+	for _, sub := range t.SubTransaksi {
+		subOrders = append(subOrders, mapTransaksiToResponse(sub))
+	}
+
+	var pembayaran *web.PembayaranResponse
+	if t.Pembayaran != nil {
+		pembayaran = &web.PembayaranResponse{
+			Provider:  t.Pembayaran.Provider,
+			Method:    t.Pembayaran.Method,
+			Reference: t.Pembayaran.Reference,
+			Instruksi: t.Pembayaran.Instruksi,
+			Jumlah:    t.Pembayaran.Jumlah,
+			Status:    t.Pembayaran.Status,
+			PaidAt:    t.Pembayaran.PaidAt,
+		}
+	}
+
+	var voucher []web.PemakaianVoucherResponse
+	for _, p := range t.Voucher {
+		voucher = append(voucher, mapPemakaianVoucherToResponse(p))
+	}
+
 	return web.TransaksiResponse{
-		ID:          t.ID,
-		HargaTotal:  t.HargaTotal,
-		KodeInvoice: t.KodeInvoice,
-		MethodBayar: t.MethodBayar,
-		AlamatKirim: mapAlamatToResponse(t.Alamat),
-		DetailTrx:   mapDetailTrxToResponse(t.DetailTransaksi),
+		ID:            t.ID,
+		ParentID:      t.ParentID,
+		TokoID:        t.TokoID,
+		Subtotal:      t.Subtotal,
+		OngkosKirim:   t.OngkosKirim,
+		Diskon:        t.Diskon,
+		HargaTotal:    t.HargaTotal,
+		KodeInvoice:   t.KodeInvoice,
+		MethodBayar:   t.MethodBayar,
+		Status:        t.Status,
+		NoResi:        t.NoResi,
+		Kurir:         t.Kurir,
+		LayananKirim:  t.LayananKirim,
+		EstimasiKirim: t.EstimasiKirim,
+		BeratTotal:    t.BeratTotal,
+		CancelledBy:   t.CancelledBy,
+		CancelReason:  t.CancelReason,
+		Dropship:      t.Dropship,
+		AlamatKirim:   mapAlamatKirimToResponse(t),
+		DetailTrx:     mapDetailTrxToResponse(t.AllDetails()),
+		SubOrders:     subOrders,
+		Pembayaran:    pembayaran,
+		Voucher:       voucher,
+		PaidAt:        t.PaidAt,
+		ProcessedAt:   t.ProcessedAt,
+		ShippedAt:     t.ShippedAt,
+		DeliveredAt:   t.DeliveredAt,
+		CompletedAt:   t.CompletedAt,
+		CancelledAt:   t.CancelledAt,
+		ExpiredAt:     t.ExpiredAt,
+		CreatedAt:     t.CreatedAt,
 	}
 }
 
-func mapAlamatToResponse(a model.Alamat) web.AlamatResponse {
-	return web.AlamatResponse{
-		ID:           a.ID,
-		JudulAlamat:  a.JudulAlamat,
-		NamaPenerima: a.NamaPenerima,
-		NoTelp:       a.NoTelp,
-		DetailAlamat: a.DetailAlamat,
-		UserID:       a.UserID,
+// mapAlamatKirimToResponse memakai snapshot alamat tujuan yang disalin saat checkout
+// (alamat pelanggan reseller untuk pesanan dropship)
+func mapAlamatKirimToResponse(t model.Transaksi) web.AlamatResponse {
+	response := web.AlamatResponse{
+		ID:           t.AlamatKirimID,
+		JudulAlamat:  t.JudulAlamat,
+		NamaPenerima: t.NamaPenerima,
+		NoTelp:       t.NoTelpPenerima,
+		DetailAlamat: t.AlamatPenerima,
+		IDProvinsi:   t.IDProvinsiPenerima,
+		IDKota:       t.IDKotaPenerima,
+	}
+	if !t.Dropship {
+		response.UserID = t.UserID
 	}
+	return response
 }
 
 func mapDetailTrxToResponse(details []model.DetailTransaksi) []web.DetailTransaksiResponse {
 	var response []web.DetailTransaksiResponse
 	for _, d := range details {
+		produk := mapDetailProdukToResponse(d)
+		if d.TierHarga != model.TierReseller {
+			produk.HargaReseler = nil // Harga reseller hanya tampil pada pembelian reseller
+		}
+
 		response = append(response, web.DetailTransaksiResponse{
-			Produk:     MapProdukToResponse(d.Produk),
-			Toko:       MapTokoToResponse(d.Toko),
-			Kuantitas:  d.Kuantitas,
-			HargaTotal: d.HargaTotal,
+			ID:          d.ID,
+			TransaksiID: d.TransaksiID,
+			Produk:      produk,
+			Varian:      mapVarianSnapshotToResponse(d.LogProduk),
+			Toko:        MapTokoToResponse(d.Toko),
+			Kuantitas:   d.Kuantitas,
+			HargaSatuan: d.HargaSatuan,
+			HargaTotal:  d.HargaTotal,
+			TierHarga:   d.TierHarga,
+			HargaJual:   d.HargaJual,
+			Margin:      d.Margin,
+			Diskon:      d.DiskonToko + d.DiskonPlatform,
 		})
 	}
 	return response
 }
+
+// mapDetailProdukToResponse menampilkan produk sesuai snapshot saat checkout,
+// sehingga perubahan atau penghapusan produk tidak mengubah riwayat transaksi.
+// Item lama tanpa snapshot memakai data produk terkini.
+func mapDetailProdukToResponse(d model.DetailTransaksi) web.ProdukResponse {
+	if d.LogProdukID == 0 {
+		return MapProdukToResponse(d.Produk)
+	}
+	log := d.LogProduk
+	return web.ProdukResponse{
+		ID:            log.ProductID,
+		NamaProduk:    log.NamaProduk,
+		Slug:          log.Slug,
+		HargaReseler:  &log.HargaReseler,
+		HargaKonsumen: log.HargaKonsumen,
+		Berat:         log.Berat,
+		Deskripsi:     log.Deskripsi,
+		Toko:          MapTokoToResponse(d.Toko),
+		Category: web.KategoriResponse{
+			ID:           log.CategoryID,
+			NamaCategory: log.NamaCategory,
+		},
+		Photos: MapFotosToResponse(d.Produk.FotoProduk),
+	}
+}
+
+// mapVarianSnapshotToResponse mengembalikan nil untuk produk tanpa varian
+func mapVarianSnapshotToResponse(log model.LogProduk) *web.VarianRingkasResponse {
+	if log.VarianID == 0 {
+		return nil
+	}
+	return &web.VarianRingkasResponse{
+		ID:   log.VarianID,
+		SKU:  log.SKU,
+		Nama: log.NamaVarian,
+	}
+}
+
+func mapProdukSnapshotToResponse(d model.DetailTransaksi) web.ProdukSnapshotResponse {
+	log := d.LogProduk
+	response := web.ProdukSnapshotResponse{
+		ID:                log.ID,
+		DetailTransaksiID: d.ID,
+		TransaksiID:       log.TransaksiID,
+		ProductID:         log.ProductID,
+		NamaProduk:        log.NamaProduk,
+		Slug:              log.Slug,
+		HargaKonsumen:     log.HargaKonsumen,
+		Berat:             log.Berat,
+		Deskripsi:         log.Deskripsi,
+		Toko:              MapTokoToResponse(d.Toko),
+		Category: web.KategoriResponse{
+			ID:           log.CategoryID,
+			NamaCategory: log.NamaCategory,
+		},
+		Varian:    mapVarianSnapshotToResponse(log),
+		CreatedAt: log.CreatedAt,
+	}
+	if d.TierHarga == model.TierReseller {
+		response.HargaReseler = &log.HargaReseler
+	}
+	return response
+}
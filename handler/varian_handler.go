@@ -0,0 +1,233 @@
+package handler
+
+import (
+	"github.com/Debjth19/go-evermos/helpers"
+	"github.com/Debjth19/go-evermos/model"
+	"github.com/Debjth19/go-evermos/model/web"
+	"github.com/Debjth19/go-evermos/service"
+
+	"errors"
+	"mime/multipart"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+type VarianHandler interface {
+	CreateOpsi(c *fiber.Ctx) error
+	DeleteOpsi(c *fiber.Ctx) error
+	CreateVarian(c *fiber.Ctx) error
+	UpdateVarian(c *fiber.Ctx) error
+	DeleteVarian(c *fiber.Ctx) error
+}
+
+type varianHandler struct {
+	varianService service.VarianService
+}
+
+func NewVarianHandler(varianService service.VarianService) VarianHandler {
+	return &varianHandler{varianService: varianService}
+}
+
+// CreateOpsi menangani POST /product/:id/opsi
+func (h *varianHandler) CreateOpsi(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	produkID, err := paramID(c, "id", "ID produk tidak valid")
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	var request web.OpsiCreateRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{
+			Status:  false,
+			Message: "Bad Request",
+			Errors:  err.Error(),
+		})
+	}
+
+	opsi, err := h.varianService.CreateOpsi(userID, produkID, request)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to POST data",
+		Data:    MapOpsiToResponse([]model.OpsiProduk{opsi})[0],
+	})
+}
+
+// DeleteOpsi menangani DELETE /product/:id/opsi/:opsi_id
+func (h *varianHandler) DeleteOpsi(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	produkID, err := paramID(c, "id", "ID produk tidak valid")
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	opsiID, err := paramID(c, "opsi_id", "ID opsi tidak valid")
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	if err := h.varianService.DeleteOpsi(userID, produkID, opsiID); err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to DELETE data",
+		Data:    "",
+	})
+}
+
+// CreateVarian menangani POST /product/:id/varian (form-data)
+func (h *varianHandler) CreateVarian(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	produkID, err := paramID(c, "id", "ID produk tidak valid")
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	request := web.VarianCreateRequest{SKU: c.FormValue("sku")}
+	for _, bagian := range strings.Split(c.FormValue("nilai_opsi"), ",") {
+		if strings.TrimSpace(bagian) == "" {
+			continue
+		}
+		nilaiID, err := strconv.Atoi(strings.TrimSpace(bagian))
+		if err != nil {
+			return errorResponse(c, helpers.Errorf(helpers.ErrTidakValid, "nilai_opsi tidak valid"))
+		}
+		request.NilaiOpsiIDs = append(request.NilaiOpsiIDs, uint(nilaiID))
+	}
+	harga, err := parseVarianForm(c)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	if harga.Stok != nil {
+		request.Stok = *harga.Stok
+	}
+	request.HargaReseler = harga.HargaReseler
+	request.HargaKonsumen = harga.HargaKonsumen
+
+	file, err := varianFoto(c)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	varian, err := h.varianService.CreateVarian(userID, produkID, request, file)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to POST data",
+		Data:    varian.ID,
+	})
+}
+
+// UpdateVarian menangani PUT /product/:id/varian/:varian_id (form-data)
+func (h *varianHandler) UpdateVarian(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	produkID, err := paramID(c, "id", "ID produk tidak valid")
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	varianID, err := paramID(c, "varian_id", "ID varian tidak valid")
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	request, err := parseVarianForm(c)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	request.SKU = c.FormValue("sku")
+
+	file, err := varianFoto(c)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	if _, err := h.varianService.UpdateVarian(userID, produkID, varianID, request, file); err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to UPDATE data",
+		Data:    "",
+	})
+}
+
+// DeleteVarian menangani DELETE /product/:id/varian/:varian_id
+func (h *varianHandler) DeleteVarian(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	produkID, err := paramID(c, "id", "ID produk tidak valid")
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	varianID, err := paramID(c, "varian_id", "ID varian tidak valid")
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	if err := h.varianService.DeleteVarian(userID, produkID, varianID); err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to DELETE data",
+		Data:    "",
+	})
+}
+
+// parseVarianForm membaca stok dan harga varian yang diisi pada form-data
+func parseVarianForm(c *fiber.Ctx) (web.VarianUpdateRequest, error) {
+	var request web.VarianUpdateRequest
+	fields := []struct {
+		nama   string
+		tujuan **uint
+	}{
+		{"stok", &request.Stok},
+		{"harga_reseller", &request.HargaReseler},
+		{"harga_konsumen", &request.HargaKonsumen},
+	}
+	for _, f := range fields {
+		if c.FormValue(f.nama) == "" {
+			continue
+		}
+		nilai, err := strconv.Atoi(c.FormValue(f.nama))
+		if err != nil || nilai < 0 {
+			return request, helpers.Errorf(helpers.ErrTidakValid, "%s tidak valid", f.nama)
+		}
+		v := uint(nilai)
+		*f.tujuan = &v
+	}
+	return request, nil
+}
+
+// varianFoto mengambil file "photo" yang opsional
+func varianFoto(c *fiber.Ctx) (*multipart.FileHeader, error) {
+	file, err := c.FormFile("photo")
+	if err != nil {
+		if !errors.Is(err, fasthttp.ErrMissingFile) {
+			return nil, helpers.Errorf(helpers.ErrTidakValid, "Gagal memproses file: %v", err)
+		}
+		return nil, nil
+	}
+	return file, nil
+}
+
+// paramID membaca parameter ID pada URL
+func paramID(c *fiber.Ctx, nama string, pesan string) (uint, error) {
+	id, err := strconv.Atoi(c.Params(nama))
+	if err != nil || id <= 0 {
+		return 0, helpers.Errorf(helpers.ErrTidakValid, "%s", pesan)
+	}
+	return uint(id), nil
+}
@@ -0,0 +1,241 @@
+package handler
+
+import (
+	"github.com/Debjth19/go-evermos/helpers"
+	"github.com/Debjth19/go-evermos/model"
+	"github.com/Debjth19/go-evermos/model/web"
+	"github.com/Debjth19/go-evermos/service"
+
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type VoucherHandler interface {
+	CreateVoucher(c *fiber.Ctx) error
+	GetVouchers(c *fiber.Ctx) error
+	GetVoucherByID(c *fiber.Ctx) error
+	UpdateVoucher(c *fiber.Ctx) error
+	GetPemakaian(c *fiber.Ctx) error
+}
+
+// voucherHandler melayani voucher toko (/toko/my/voucher) atau voucher platform (/admin/voucher)
+type voucherHandler struct {
+	voucherService service.VoucherService
+	platform       bool
+}
+
+// NewVoucherHandler membuat handler untuk voucher milik toko penjual
+func NewVoucherHandler(voucherService service.VoucherService) VoucherHandler {
+	return &voucherHandler{voucherService: voucherService}
+}
+
+// NewPlatformVoucherHandler membuat handler untuk voucher platform yang dikelola admin
+func NewPlatformVoucherHandler(voucherService service.VoucherService) VoucherHandler {
+	return &voucherHandler{voucherService: voucherService, platform: true}
+}
+
+// tokoID menentukan pemilik voucher: 0 untuk voucher platform, atau toko milik user
+func (h *voucherHandler) tokoID(c *fiber.Ctx) (uint, error) {
+	if h.platform {
+		return 0, nil
+	}
+	return h.voucherService.TokoIDByUser(c.Locals("user_id").(uint))
+}
+
+// CreateVoucher menangani POST /toko/my/voucher dan /admin/voucher
+func (h *voucherHandler) CreateVoucher(c *fiber.Ctx) error {
+	tokoID, err := h.tokoID(c)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	var request web.VoucherCreateRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{
+			Status:  false,
+			Message: "Bad Request",
+			Errors:  err.Error(),
+		})
+	}
+
+	voucher, err := h.voucherService.CreateVoucher(tokoID, request)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to POST data",
+		Data:    mapVoucherToResponse(voucher),
+	})
+}
+
+// GetVouchers menangani GET /toko/my/voucher dan /admin/voucher
+func (h *voucherHandler) GetVouchers(c *fiber.Ctx) error {
+	tokoID, err := h.tokoID(c)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	pagination := helpers.GeneratePagination(c)
+
+	vouchers, err := h.voucherService.GetVouchers(tokoID, pagination)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	var response []web.VoucherResponse
+	for _, voucher := range vouchers {
+		response = append(response, mapVoucherToResponse(voucher))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Data: web.PaginatedVoucherResponse{
+			Page:  pagination.Page,
+			Limit: pagination.Limit,
+			Data:  response,
+		},
+	})
+}
+
+// GetVoucherByID menangani GET /toko/my/voucher/:id dan /admin/voucher/:id
+func (h *voucherHandler) GetVoucherByID(c *fiber.Ctx) error {
+	tokoID, voucherID, err := h.params(c)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	voucher, totalDiskon, err := h.voucherService.GetVoucherByID(tokoID, voucherID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	response := mapVoucherToResponse(voucher)
+	response.TotalDiskon = &totalDiskon
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Data:    response,
+	})
+}
+
+// UpdateVoucher menangani PUT /toko/my/voucher/:id dan /admin/voucher/:id
+func (h *voucherHandler) UpdateVoucher(c *fiber.Ctx) error {
+	tokoID, voucherID, err := h.params(c)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	var request web.VoucherUpdateRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{
+			Status:  false,
+			Message: "Bad Request",
+			Errors:  err.Error(),
+		})
+	}
+
+	voucher, err := h.voucherService.UpdateVoucher(tokoID, voucherID, request)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to PUT data",
+		Data:    mapVoucherToResponse(voucher),
+	})
+}
+
+// GetPemakaian menangani GET /toko/my/voucher/:id/pemakaian dan /admin/voucher/:id/pemakaian
+func (h *voucherHandler) GetPemakaian(c *fiber.Ctx) error {
+	tokoID, voucherID, err := h.params(c)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	pagination := helpers.GeneratePagination(c)
+
+	pemakaian, total, err := h.voucherService.GetPemakaian(tokoID, voucherID, pagination)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	var response []web.PemakaianVoucherResponse
+	for _, p := range pemakaian {
+		item := mapPemakaianVoucherToResponse(p)
+		item.UserID = p.UserID
+		item.TransaksiID = p.TransaksiID
+		item.KodeInvoice = p.Transaksi.KodeInvoice
+		response = append(response, item)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Data: web.PaginatedPemakaianVoucherResponse{
+			Page:       pagination.Page,
+			Limit:      pagination.Limit,
+			TotalItems: total,
+			TotalPages: helpers.TotalPages(total, pagination.Limit),
+			Data:       response,
+		},
+	})
+}
+
+// params membaca pemilik voucher dan :id
+func (h *voucherHandler) params(c *fiber.Ctx) (uint, uint, error) {
+	tokoID, err := h.tokoID(c)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	voucherID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return 0, 0, helpers.Errorf(helpers.ErrTidakValid, "ID voucher tidak valid")
+	}
+	return tokoID, uint(voucherID), nil
+}
+
+// --- Helper Mapping ---
+
+func mapVoucherToResponse(v model.Voucher) web.VoucherResponse {
+	kategori := []web.KategoriResponse{}
+	for _, k := range v.Kategori {
+		kategori = append(kategori, web.KategoriResponse{
+			ID:           k.ID,
+			NamaCategory: k.NamaCategory,
+		})
+	}
+
+	return web.VoucherResponse{
+		ID:            v.ID,
+		Kode:          v.Kode,
+		Nama:          v.Nama,
+		TokoID:        v.TokoID,
+		Tipe:          v.Tipe,
+		Nilai:         v.Nilai,
+		MaksDiskon:    v.MaksDiskon,
+		MinBelanja:    v.MinBelanja,
+		KuotaTotal:    v.KuotaTotal,
+		KuotaPerUser:  v.KuotaPerUser,
+		Terpakai:      v.Terpakai,
+		BerlakuMulai:  v.BerlakuMulai,
+		BerlakuSampai: v.BerlakuSampai,
+		Aktif:         v.Aktif,
+		Kategori:      kategori,
+		CreatedAt:     v.CreatedAt,
+	}
+}
+
+func mapPemakaianVoucherToResponse(p model.PemakaianVoucher) web.PemakaianVoucherResponse {
+	return web.PemakaianVoucherResponse{
+		ID:        p.ID,
+		VoucherID: p.VoucherID,
+		Kode:      p.Kode,
+		TokoID:    p.TokoID,
+		Jumlah:    p.Jumlah,
+		CreatedAt: p.CreatedAt,
+	}
+}
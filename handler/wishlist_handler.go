@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"github.com/Debjth19/go-evermos/helpers"
+	"github.com/Debjth19/go-evermos/model"
+	"github.com/Debjth19/go-evermos/model/web"
+	"github.com/Debjth19/go-evermos/service"
+
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type WishlistHandler interface {
+	GetWishlist(c *fiber.Ctx) error
+	AddWishlist(c *fiber.Ctx) error
+	RemoveWishlist(c *fiber.Ctx) error
+}
+
+type wishlistHandler struct {
+	wishlistService service.WishlistService
+}
+
+func NewWishlistHandler(wishlistService service.WishlistService) WishlistHandler {
+	return &wishlistHandler{wishlistService: wishlistService}
+}
+
+// GetWishlist menangani GET /user/wishlist
+func (h *wishlistHandler) GetWishlist(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	pagination := helpers.GeneratePagination(c)
+
+	baris, total, err := h.wishlistService.GetWishlist(userID, pagination)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	response := []web.WishlistResponse{}
+	for _, b := range baris {
+		response = append(response, mapWishlistToResponse(b))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Data: web.PaginatedWishlistResponse{
+			Page:       pagination.Page,
+			Limit:      pagination.Limit,
+			TotalItems: total,
+			TotalPages: helpers.TotalPages(total, pagination.Limit),
+			Data:       response,
+		},
+	})
+}
+
+// AddWishlist menangani POST /user/wishlist
+func (h *wishlistHandler) AddWishlist(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var request web.WishlistAddRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{
+			Status:  false,
+			Message: "Bad Request",
+			Errors:  err.Error(),
+		})
+	}
+
+	wishlist, err := h.wishlistService.AddWishlist(userID, request)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to POST data",
+		Data:    wishlist.ID,
+	})
+}
+
+// RemoveWishlist menangani DELETE /user/wishlist/:product_id
+func (h *wishlistHandler) RemoveWishlist(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	produkID, err := strconv.Atoi(c.Params("product_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{
+			Status:  false,
+			Message: "Bad Request",
+			Errors:  "ID produk tidak valid",
+		})
+	}
+
+	if err := h.wishlistService.RemoveWishlist(userID, uint(produkID)); err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Status:  true,
+		Message: "Succeed to DELETE data",
+		Data:    "Delete wishlist succeed",
+	})
+}
+
+// --- Helper Mapping ---
+
+func mapWishlistToResponse(b service.WishlistBaris) web.WishlistResponse {
+	produk := MapProdukToResponse(b.Wishlist.Produk)
+	if b.TierHarga != model.TierReseller {
+		produk.HargaReseler = nil // Harga reseller hanya tampil untuk reseller
+	}
+
+	return web.WishlistResponse{
+		ID:                b.Wishlist.ID,
+		Produk:            produk,
+		HargaSatuan:       b.HargaSatuan,
+		HargaSaatDisimpan: b.Wishlist.HargaSaatDisimpan,
+		HargaTurun:        b.HargaTurun,
+		StokHabis:         b.StokHabis,
+		CreatedAt:         b.Wishlist.CreatedAt,
+	}
+}
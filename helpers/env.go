@@ -0,0 +1,16 @@
+package helpers
+
+import (
+	"os"
+	"time"
+)
+
+// EnvDuration membaca durasi dari env (format time.ParseDuration, contoh "30m" atau "24h").
+// Nilai kosong, tidak valid, atau tidak positif memakai nilai bawaan.
+func EnvDuration(key string, bawaan time.Duration) time.Duration {
+	durasi, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || durasi <= 0 {
+		return bawaan
+	}
+	return durasi
+}
@@ -0,0 +1,34 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Jenis error service. Handler memetakan setiap jenis ke status HTTP dengan errors.Is,
+// sehingga pesan error dapat diubah tanpa mengubah status yang dikembalikan.
+var (
+	ErrTidakValid     = errors.New("tidak valid")     // 400 Bad Request
+	ErrAksesDitolak   = errors.New("akses ditolak")   // 403 Forbidden
+	ErrTidakDitemukan = errors.New("tidak ditemukan") // 404 Not Found
+	ErrTidakDiizinkan = errors.New("tidak diizinkan") // 409 Conflict
+)
+
+// ServiceError adalah error service berisi pesan untuk klien dan jenisnya
+type ServiceError struct {
+	Jenis error
+	Pesan string
+}
+
+func (e *ServiceError) Error() string {
+	return e.Pesan
+}
+
+func (e *ServiceError) Unwrap() error {
+	return e.Jenis
+}
+
+// Errorf membuat ServiceError berjenis 'jenis' dengan pesan yang diformat seperti fmt.Sprintf
+func Errorf(jenis error, format string, args ...interface{}) error {
+	return &ServiceError{Jenis: jenis, Pesan: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,24 @@
+package helpers
+
+import (
+	"os"
+	"strconv"
+)
+
+// PlatformFeeBps mengambil biaya platform dari env PLATFORM_FEE_PERCENT dalam basis poin
+// (contoh: "2.5" menjadi 250). Nilai kosong atau tidak valid berarti tanpa biaya.
+func PlatformFeeBps() uint {
+	persen, err := strconv.ParseFloat(os.Getenv("PLATFORM_FEE_PERCENT"), 64)
+	if err != nil || persen <= 0 {
+		return 0
+	}
+	if persen > 100 {
+		persen = 100
+	}
+	return uint(persen*100 + 0.5)
+}
+
+// HitungBiayaPlatform menghitung biaya platform dari sebuah jumlah, dibulatkan ke bawah
+func HitungBiayaPlatform(jumlah uint, feeBps uint) uint {
+	return uint(uint64(jumlah) * uint64(feeBps) / 10000)
+}
@@ -11,6 +11,7 @@ import (
 const (
 	TokoImagesPath   = "./public/images/toko"
 	ProdukImagesPath = "./public/images/produk"
+	ReturImagesPath  = "./public/images/retur"
 )
 
 // SaveUploadedFiles menyimpan file ke path yang ditentukan dan mengembalikan nama filenya
@@ -0,0 +1,53 @@
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultInvoiceFormat menghasilkan kode seperti INV/20261018/000123
+const DefaultInvoiceFormat = "INV/{YYYY}{MM}{DD}/{SEQ:6}"
+
+var seqToken = regexp.MustCompile(`\{SEQ(?::(\d+))?\}`)
+
+// InvoiceFormat mengambil format kode invoice dari env INVOICE_FORMAT.
+// Token yang didukung: {YYYY}, {YY}, {MM}, {DD}, dan {SEQ} atau {SEQ:n} (nomor urut, diisi nol hingga n digit).
+// Format tanpa token {SEQ} tidak menjamin keunikan, sehingga format default yang dipakai.
+func InvoiceFormat() string {
+	format := os.Getenv("INVOICE_FORMAT")
+	if format == "" || len(seqToken.FindAllString(format, -1)) != 1 {
+		return DefaultInvoiceFormat
+	}
+	return format
+}
+
+// InvoiceScope mengembalikan kunci penghitung nomor urut untuk sebuah format dan tanggal.
+// Nomor urut dimulai dari 1 lagi setiap kali bagian tanggal pada format berubah.
+func InvoiceScope(format string, t time.Time) string {
+	return replaceDateTokens(seqToken.ReplaceAllString(format, "{SEQ}"), t)
+}
+
+// FormatInvoice membuat kode invoice dari format, tanggal, dan nomor urut
+func FormatInvoice(format string, t time.Time, seq uint64) string {
+	kode := seqToken.ReplaceAllStringFunc(format, func(token string) string {
+		width := 0
+		if match := seqToken.FindStringSubmatch(token); match[1] != "" {
+			width, _ = strconv.Atoi(match[1])
+		}
+		return fmt.Sprintf("%0*d", width, seq)
+	})
+	return replaceDateTokens(kode, t)
+}
+
+func replaceDateTokens(format string, t time.Time) string {
+	return strings.NewReplacer(
+		"{YYYY}", t.Format("2006"),
+		"{YY}", t.Format("06"),
+		"{MM}", t.Format("01"),
+		"{DD}", t.Format("02"),
+	).Replace(format)
+}
@@ -0,0 +1,135 @@
+package helpers
+
+import (
+	"github.com/Debjth19/go-evermos/model"
+
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// FormatRupiah memformat angka menjadi teks rupiah, contoh Rp150.000
+func FormatRupiah(n uint) string {
+	digits := strconv.FormatUint(uint64(n), 10)
+	var buf bytes.Buffer
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			buf.WriteByte('.')
+		}
+		buf.WriteRune(d)
+	}
+	return "Rp" + buf.String()
+}
+
+// GenerateInvoicePDF membuat PDF invoice dari transaksi beserta relasinya.
+// Nama dan harga produk diambil dari snapshot LogProduk saat checkout,
+// bukan dari data Produk yang mungkin sudah diubah.
+func GenerateInvoicePDF(t model.Transaksi) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.AddPage()
+
+	// 1. Kepala invoice
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(0, 10, "INVOICE", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	invoiceRow(pdf, "Kode Invoice", t.KodeInvoice)
+	invoiceRow(pdf, "Tanggal", t.CreatedAt.Format("02/01/2006 15:04"))
+	invoiceRow(pdf, "Status", t.Status)
+	invoiceRow(pdf, "Metode Bayar", t.MethodBayar)
+	if t.Pembayaran != nil {
+		invoiceRow(pdf, "Status Bayar", t.Pembayaran.Status)
+	}
+	pdf.Ln(4)
+
+	// 2. Alamat pengiriman
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(0, 7, "Dikirim ke", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	namaPenerima, noTelp, detailAlamat := t.AlamatTujuan()
+	pdf.CellFormat(0, 5, fmt.Sprintf("%s (%s)", namaPenerima, noTelp), "", 1, "L", false, 0, "")
+	pdf.MultiCell(0, 5, detailAlamat, "", "L", false)
+	pdf.Ln(4)
+
+	// 3. Item per toko
+	orders := t.SubTransaksi
+	if len(orders) == 0 {
+		orders = []model.Transaksi{t}
+	}
+	for _, order := range orders {
+		invoiceOrderTable(pdf, order)
+	}
+
+	// 4. Ringkasan total
+	pdf.SetFont("Helvetica", "", 10)
+	invoiceTotalRow(pdf, "Subtotal Produk", t.Subtotal)
+	invoiceTotalRow(pdf, "Ongkos Kirim", t.OngkosKirim)
+	if t.Diskon > 0 {
+		invoiceTotalRow(pdf, "Diskon Voucher", t.Diskon)
+	}
+	pdf.SetFont("Helvetica", "B", 11)
+	invoiceTotalRow(pdf, "Total Tagihan", t.HargaTotal)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func invoiceRow(pdf *fpdf.Fpdf, label, value string) {
+	pdf.CellFormat(35, 5, label, "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 5, ": "+value, "", 1, "L", false, 0, "")
+}
+
+func invoiceTotalRow(pdf *fpdf.Fpdf, label string, value uint) {
+	pdf.CellFormat(140, 6, label, "", 0, "R", false, 0, "")
+	pdf.CellFormat(0, 6, FormatRupiah(value), "", 1, "R", false, 0, "")
+}
+
+// invoiceOrderTable menulis tabel item untuk satu (sub-)transaksi
+func invoiceOrderTable(pdf *fpdf.Fpdf, order model.Transaksi) {
+	namaToko := ""
+	if len(order.DetailTransaksi) > 0 {
+		namaToko = order.DetailTransaksi[0].Toko.NamaToko
+	}
+
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(0, 7, fmt.Sprintf("%s - %s", order.KodeInvoice, namaToko), "", 1, "L", false, 0, "")
+
+	pdf.SetFillColor(230, 230, 230)
+	pdf.CellFormat(85, 7, "Produk", "1", 0, "L", true, 0, "")
+	pdf.CellFormat(20, 7, "Qty", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(35, 7, "Harga", "1", 0, "R", true, 0, "")
+	pdf.CellFormat(0, 7, "Total", "1", 1, "R", true, 0, "")
+
+	pdf.SetFont("Helvetica", "", 10)
+	for _, detail := range order.DetailTransaksi {
+		nama := detail.LogProduk.NamaProduk // Nama produk saat checkout
+		if detail.LogProdukID == 0 {
+			nama = detail.Produk.NamaProduk
+		}
+		if detail.LogProduk.NamaVarian != "" {
+			nama += " (" + detail.LogProduk.NamaVarian + ")"
+		}
+		harga := detail.HargaSatuan // Sesuai tier harga yang diterapkan saat checkout
+		if harga == 0 && detail.Kuantitas > 0 {
+			harga = detail.HargaTotal / detail.Kuantitas
+		}
+
+		pdf.CellFormat(85, 7, nama, "1", 0, "L", false, 0, "")
+		pdf.CellFormat(20, 7, strconv.FormatUint(uint64(detail.Kuantitas), 10), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(35, 7, FormatRupiah(harga), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(0, 7, FormatRupiah(detail.HargaTotal), "1", 1, "R", false, 0, "")
+	}
+
+	pdf.CellFormat(140, 6, "Ongkos Kirim", "", 0, "R", false, 0, "")
+	pdf.CellFormat(0, 6, FormatRupiah(order.OngkosKirim), "", 1, "R", false, 0, "")
+	if order.Diskon > 0 {
+		pdf.CellFormat(140, 6, "Diskon Voucher", "", 0, "R", false, 0, "")
+		pdf.CellFormat(0, 6, "-"+FormatRupiah(order.Diskon), "", 1, "R", false, 0, "")
+	}
+	pdf.Ln(3)
+}
@@ -1,7 +1,11 @@
 package helpers
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -9,7 +13,21 @@ import (
 type Pagination struct {
 	Limit int `json:"limit"`
 	Page  int `json:"page"`
-	
+
+	// Mode cursor (opt-in): aktif bila query param 'cursor' dikirim, kosong untuk halaman pertama
+	ModeCursor bool   `json:"-"`
+	Cursor     string `json:"-"`
+
+	// Diisi repository setelah query dijalankan
+	TotalItems int64  `json:"total_items"`
+	TotalPages int    `json:"total_pages"`
+	NextCursor string `json:"next_cursor,omitempty"` // Kosong bila tidak ada halaman berikutnya
+}
+
+// Cursor adalah posisi item terakhir pada mode cursor. Klien menerimanya sebagai string opaque.
+type Cursor struct {
+	Nilai string `json:"n,omitempty"` // Nilai kolom urutan item terakhir
+	ID    uint   `json:"id"`          // Penentu akhir untuk nilai urutan yang sama
 }
 
 // GeneratePagination mem-parsing query params dari URL
@@ -28,7 +46,73 @@ func GeneratePagination(c *fiber.Ctx) Pagination {
 	}
 
 	return Pagination{
-		Limit: limit,
-		Page:  page,
+		Limit:      limit,
+		Page:       page,
+		ModeCursor: c.Context().QueryArgs().Has("cursor"),
+		Cursor:     c.Query("cursor"),
+	}
+}
+
+// Offset menghitung jumlah data yang dilewati pada mode halaman
+func (p Pagination) Offset() int {
+	return (p.Page - 1) * p.Limit
+}
+
+// SetTotal mengisi total data dan jumlah halaman
+func (p *Pagination) SetTotal(total int64) {
+	p.TotalItems = total
+	p.TotalPages = TotalPages(total, p.Limit)
+}
+
+// DecodeCursor membaca cursor dari klien, nil untuk halaman pertama
+func (p Pagination) DecodeCursor() (*Cursor, error) {
+	if p.Cursor == "" {
+		return nil, nil
 	}
-}
\ No newline at end of file
+	raw, err := base64.RawURLEncoding.DecodeString(p.Cursor)
+	if err != nil {
+		return nil, errors.New("Cursor tidak valid")
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(raw, &cursor); err != nil || cursor.ID == 0 {
+		return nil, errors.New("Cursor tidak valid")
+	}
+	return &cursor, nil
+}
+
+// SetNextCursor menyimpan posisi item terakhir sebagai cursor halaman berikutnya
+func (p *Pagination) SetNextCursor(cursor Cursor) {
+	raw, _ := json.Marshal(cursor)
+	p.NextCursor = base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// TotalPages menghitung jumlah halaman dari total data dan limit per halaman
+func TotalPages(total int64, limit int) int {
+	if limit <= 0 {
+		return 0
+	}
+	return int((total + int64(limit) - 1) / int64(limit))
+}
+
+// Waktu membaca nilai cursor untuk kolom waktu (disimpan dalam nanodetik Unix)
+func (c Cursor) Waktu() (time.Time, error) {
+	n, err := strconv.ParseInt(c.Nilai, 10, 64)
+	if err != nil {
+		return time.Time{}, errors.New("Cursor tidak valid")
+	}
+	return time.Unix(0, n).UTC(), nil
+}
+
+// Angka membaca nilai cursor untuk kolom bilangan seperti harga atau jumlah terjual
+func (c Cursor) Angka() (uint64, error) {
+	n, err := strconv.ParseUint(c.Nilai, 10, 64)
+	if err != nil {
+		return 0, errors.New("Cursor tidak valid")
+	}
+	return n, nil
+}
+
+// CursorWaktu membuat cursor dari kolom waktu dan ID item terakhir
+func CursorWaktu(t time.Time, id uint) Cursor {
+	return Cursor{Nilai: strconv.FormatInt(t.UnixNano(), 10), ID: id}
+}
@@ -0,0 +1,41 @@
+package helpers
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+// Kode error MySQL yang aman untuk diulang karena seluruh DB transaction dibatalkan
+const (
+	mysqlErrLockWaitTimeout = 1205
+	mysqlErrDeadlock        = 1213
+)
+
+// IsRetryableTxError mengecek apakah error disebabkan deadlock atau lock wait timeout
+func IsRetryableTxError(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return false
+	}
+	return mysqlErr.Number == mysqlErrDeadlock || mysqlErr.Number == mysqlErrLockWaitTimeout
+}
+
+// TransactionWithRetry menjalankan fn di dalam DB transaction dan mengulanginya paling banyak
+// maxAttempts kali bila gagal karena deadlock atau lock wait timeout. fn harus dapat diulang
+// dari awal: seluruh perubahan percobaan sebelumnya sudah di-rollback.
+func TransactionWithRetry(db *gorm.DB, maxAttempts int, fn func(tx *gorm.DB) error) error {
+	for attempt := 1; ; attempt++ {
+		err := db.Transaction(fn)
+		if err == nil || attempt >= maxAttempts || !IsRetryableTxError(err) {
+			return err
+		}
+
+		// Jeda bertambah di setiap percobaan, dengan acak agar transaksi yang bertabrakan tidak bentrok lagi
+		jeda := time.Duration(attempt*20)*time.Millisecond + time.Duration(rand.Intn(20))*time.Millisecond
+		time.Sleep(jeda)
+	}
+}
@@ -4,9 +4,18 @@ import (
 	"github.com/Debjth19/go-evermos/config"
 	"github.com/Debjth19/go-evermos/database"
 	"github.com/Debjth19/go-evermos/handler"
+	"github.com/Debjth19/go-evermos/helpers"
+	"github.com/Debjth19/go-evermos/middleware"
+	"github.com/Debjth19/go-evermos/payment"
 	"github.com/Debjth19/go-evermos/repository"
 	"github.com/Debjth19/go-evermos/routes"
 	"github.com/Debjth19/go-evermos/service"
+	"github.com/Debjth19/go-evermos/shipping"
+	"github.com/Debjth19/go-evermos/worker"
+
+	"context"
+	"os"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -28,7 +37,23 @@ func main() {
 	tokoRepository := repository.NewTokoRepository(config.DB)
 	kategoriRepository := repository.NewKategoriRepository(config.DB)
 	produkRepository := repository.NewProdukRepository(config.DB)
+	varianRepository := repository.NewVarianRepository(config.DB)
 	transaksiRepository := repository.NewTransaksiRepository(config.DB)
+	pembayaranRepository := repository.NewPembayaranRepository(config.DB)
+	idempotencyRepository := repository.NewIdempotencyRepository(config.DB)
+	invoiceRepository := repository.NewInvoiceRepository(config.DB)
+	returRepository := repository.NewReturRepository(config.DB)
+	resellerRepository := repository.NewResellerRepository(config.DB)
+	ledgerRepository := repository.NewLedgerRepository(config.DB)
+	voucherRepository := repository.NewVoucherRepository(config.DB)
+	keranjangRepository := repository.NewKeranjangRepository(config.DB)
+	wishlistRepository := repository.NewWishlistRepository(config.DB)
+
+	// Provider pembayaran
+	paymentRegistry := payment.NewRegistry(
+		payment.NewFakeProvider(os.Getenv("PAYMENT_FAKE_SECRET")),
+		payment.NewCODProvider(),
+	)
 
 	// 2. Service
 	authService := service.NewAuthService(authRepository)
@@ -37,7 +62,31 @@ func main() {
 	tokoService := service.NewTokoService(tokoRepository)
 	kategoriService := service.NewKategoriService(kategoriRepository)
 	produkService := service.NewProdukService(produkRepository, tokoRepository)
-	transaksiService := service.NewTransaksiService(config.DB, transaksiRepository, produkRepository, alamatRepository)
+	varianService := service.NewVarianService(varianRepository, produkRepository, tokoRepository)
+	shippingProvider := shipping.NewLocalRateProvider(shipping.DefaultLocalRates)
+	ledgerService := service.NewLedgerService(config.DB, ledgerRepository, transaksiRepository, tokoRepository)
+	voucherService := service.NewVoucherService(voucherRepository, kategoriRepository, tokoRepository)
+	transaksiService := service.NewTransaksiService(config.DB, transaksiRepository, produkRepository, kategoriRepository, alamatRepository, tokoRepository, pembayaranRepository, invoiceRepository, userRepository, ledgerService, voucherService, paymentRegistry, shippingProvider)
+	pembayaranService := service.NewPembayaranService(paymentRegistry, transaksiService)
+	keranjangService := service.NewKeranjangService(keranjangRepository, produkRepository, userRepository, transaksiService)
+	wishlistService := service.NewWishlistService(wishlistRepository, produkRepository, userRepository)
+	resellerService := service.NewResellerService(config.DB, resellerRepository, userRepository)
+	returService := service.NewReturService(config.DB, returRepository, transaksiRepository, produkRepository, tokoRepository, pembayaranRepository, ledgerService)
+
+	// Worker kedaluwarsa pesanan yang belum dibayar
+	orderExpiryWorker := worker.NewOrderExpiryWorker(
+		transaksiService,
+		helpers.EnvDuration("ORDER_PAYMENT_TTL", 24*time.Hour),
+		helpers.EnvDuration("ORDER_EXPIRY_INTERVAL", time.Minute),
+	)
+	orderExpiryWorker.Start(context.Background())
+
+	// Worker pembersih Idempotency-Key yang kedaluwarsa
+	idempotencyCleanupWorker := worker.NewIdempotencyCleanupWorker(
+		idempotencyRepository,
+		helpers.EnvDuration("IDEMPOTENCY_CLEANUP_INTERVAL", time.Hour),
+	)
+	idempotencyCleanupWorker.Start(context.Background())
 
 	// 3. Handler
 	authHandler := handler.NewAuthHandler(authService)
@@ -46,10 +95,26 @@ func main() {
 	tokoHandler := handler.NewTokoHandler(tokoService)
 	kategoriHandler := handler.NewKategoriHandler(kategoriService)
 	produkHandler := handler.NewProdukHandler(produkService)
+	varianHandler := handler.NewVarianHandler(varianService)
 	transaksiHandler := handler.NewTransaksiHandler(transaksiService)
+	pembayaranHandler := handler.NewPembayaranHandler(pembayaranService)
+	returHandler := handler.NewReturHandler(returService)
+	resellerHandler := handler.NewResellerHandler(resellerService)
+	ledgerHandler := handler.NewLedgerHandler(ledgerService)
+	voucherHandler := handler.NewVoucherHandler(voucherService)
+	platformVoucherHandler := handler.NewPlatformVoucherHandler(voucherService)
+	keranjangHandler := handler.NewKeranjangHandler(keranjangService)
+	wishlistHandler := handler.NewWishlistHandler(wishlistService)
+
+	// 4. Middleware yang membutuhkan repository
+	idempotencyMiddleware := middleware.IdempotencyMiddleware(
+		idempotencyRepository,
+		helpers.EnvDuration("IDEMPOTENCY_LEASE", time.Minute),
+		helpers.EnvDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+	)
 
 	// --- Setup Rute ---
-	routes.SetupRoutes(app, authHandler, userHandler, alamatHandler, tokoHandler, kategoriHandler, produkHandler, transaksiHandler)
+	routes.SetupRoutes(app, authHandler, userHandler, alamatHandler, tokoHandler, kategoriHandler, produkHandler, varianHandler, transaksiHandler, pembayaranHandler, returHandler, resellerHandler, ledgerHandler, voucherHandler, platformVoucherHandler, keranjangHandler, wishlistHandler, idempotencyMiddleware)
 	
 	// Rute sederhana untuk tes 
 	app.Get("/", func(c *fiber.Ctx) error {
@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"github.com/Debjth19/go-evermos/model"
+	"github.com/Debjth19/go-evermos/model/web"
+	"github.com/Debjth19/go-evermos/repository"
+
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Middleware untuk request dengan header Idempotency-Key.
+// Request pertama dijalankan dan responsnya disimpan per (user, key) selama ttl;
+// request ulang dengan key yang sama mendapatkan respons yang identik tanpa
+// menjalankan handler lagi. Key yang masih diproses dipegang selama lease, sehingga
+// key yang ditinggalkan request yang terhenti (misalnya server mati) dapat dipakai lagi.
+// Harus dipasang setelah AuthMiddleware.
+func IdempotencyMiddleware(repo repository.IdempotencyRepository, lease time.Duration, ttl time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// 1. Tanpa header, request diproses seperti biasa
+		key := c.Get("Idempotency-Key")
+		if key == "" {
+			return c.Next()
+		}
+		if len(key) > 255 {
+			return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{
+				Status:  false,
+				Message: "Bad Request",
+				Errors:  "Idempotency-Key maksimal 255 karakter",
+			})
+		}
+
+		userID := c.Locals("user_id").(uint)
+		hash := sha256.Sum256([]byte(c.Method() + " " + c.Path() + "\n" + string(c.Body())))
+		requestHash := hex.EncodeToString(hash[:])
+
+		// 2. Klaim key. Hanya satu request yang berhasil menyimpan record,
+		// atau mengambil alih record yang lease/masa simpannya sudah habis.
+		leaseToken, err := newLeaseToken()
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(web.WebResponse{
+				Status:  false,
+				Message: "Server Error",
+				Errors:  err.Error(),
+			})
+		}
+		now := time.Now()
+		record := model.IdempotencyRecord{
+			UserID:         userID,
+			IdempotencyKey: key,
+			RequestHash:    requestHash,
+			Status:         model.IdempotencyProcessing,
+			LeaseToken:     leaseToken,
+			ExpiresAt:      now.Add(lease),
+		}
+		created, err := repo.Create(&record)
+		if err == nil && !created {
+			created, err = repo.TakeOverExpired(&record, now)
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(web.WebResponse{
+				Status:  false,
+				Message: "Server Error",
+				Errors:  err.Error(),
+			})
+		}
+
+		// 3. Key sudah pernah dipakai: tolak atau putar ulang respons
+		if !created {
+			existing, err := repo.FindByUserAndKey(userID, key)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(web.WebResponse{
+					Status:  false,
+					Message: "Server Error",
+					Errors:  err.Error(),
+				})
+			}
+			if existing.RequestHash != requestHash {
+				return c.Status(fiber.StatusUnprocessableEntity).JSON(web.WebResponse{
+					Status:  false,
+					Message: "Gagal",
+					Errors:  "Idempotency-Key sudah dipakai untuk request yang berbeda",
+				})
+			}
+			if existing.Status != model.IdempotencyCompleted {
+				return c.Status(fiber.StatusConflict).JSON(web.WebResponse{ // 409 Conflict
+					Status:  false,
+					Message: "Gagal",
+					Errors:  "Request dengan Idempotency-Key yang sama sedang diproses",
+				})
+			}
+
+			c.Set("Idempotent-Replayed", "true")
+			c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+			return c.Status(existing.ResponseCode).SendString(existing.ResponseBody)
+		}
+
+		// 4. Jalankan handler lalu simpan responsnya.
+		// Error server tidak disimpan agar request dapat dicoba ulang.
+		if err := c.Next(); err != nil {
+			_ = repo.Delete(record.ID, leaseToken)
+			return err
+		}
+
+		statusCode := c.Response().StatusCode()
+		if statusCode >= fiber.StatusInternalServerError {
+			_ = repo.Delete(record.ID, leaseToken)
+			return nil
+		}
+		if err := repo.Complete(record.ID, leaseToken, statusCode, string(c.Response().Body()), time.Now().Add(ttl)); err != nil {
+			_ = repo.Delete(record.ID, leaseToken)
+		}
+		return nil
+	}
+}
+
+// newLeaseToken membuat penanda acak untuk request yang memegang sebuah key
+func newLeaseToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"github.com/Debjth19/go-evermos/helpers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Middleware untuk rute publik yang responsnya berbeda bagi user yang login.
+// Jika token valid, user_id dan role disimpan seperti AuthMiddleware;
+// jika token kosong atau tidak valid, request tetap dilanjutkan sebagai tamu.
+func OptionalAuthMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		tokenString := c.Get("token")
+		if tokenString == "" {
+			return c.Next()
+		}
+
+		claims, err := helpers.ValidateToken(tokenString)
+		if err != nil {
+			return c.Next()
+		}
+
+		c.Locals("user_id", claims.UserID)
+		c.Locals("role", claims.Role)
+		return c.Next()
+	}
+}
@@ -15,7 +15,7 @@ type User struct {
 	Email        string    `gorm:"type:varchar(100);unique"`
 	IDProvinsi   string    `gorm:"type:varchar(10)"`
 	IDKota       string    `gorm:"type:varchar(10)"`
-	Role         string    `gorm:"type:enum('user', 'admin');default:'user'"`
+	Role         string    `gorm:"type:enum('user', 'reseller', 'admin');default:'user'"`
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 	Toko         Toko      `gorm:"foreignKey:UserID"` // Relasi one-to-one
@@ -23,15 +23,46 @@ type User struct {
 	Transaksi    []Transaksi `gorm:"foreignKey:UserID"` // Relasi one-to-many
 }
 
+// Role user
+const (
+	RoleUser     = "user"
+	RoleReseller = "reseller" // Diberikan setelah pengajuan disetujui admin
+	RoleAdmin    = "admin"
+)
+
+// PengajuanReseller mewakili tabel 'pengajuan_reseller'.
+// User mengajukan diri menjadi reseller, admin menyetujui atau menolak.
+type PengajuanReseller struct {
+	ID           uint   `gorm:"primaryKey"`
+	UserID       uint   `gorm:"index"` // Foreign key ke User
+	Alasan       string `gorm:"type:text"`
+	Status       string `gorm:"type:varchar(20);default:'pending';index"` // pending, approved, rejected
+	CatatanAdmin string `gorm:"type:text"`
+	ReviewedBy   uint   // Admin yang memutuskan
+	ReviewedAt   *time.Time
+	User         User `gorm:"foreignKey:UserID"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// Status PengajuanReseller
+const (
+	PengajuanPending  = "pending"
+	PengajuanApproved = "approved"
+	PengajuanRejected = "rejected"
+)
+
 // Toko mewakili tabel 'toko'
 type Toko struct {
-	ID        uint      `gorm:"primaryKey"`
-	NamaToko  string    `gorm:"type:varchar(100)"`
-	UrlFoto   string    `gorm:"type:varchar(255)"`
-	UserID    uint      `gorm:"unique"` // Relasi one-to-one
-	Produk    []Produk  `gorm:"foreignKey:TokoID"`
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID         uint      `gorm:"primaryKey"`
+	NamaToko   string    `gorm:"type:varchar(100)"`
+	UrlFoto    string    `gorm:"type:varchar(255)"`
+	UserID     uint      `gorm:"unique"`           // Relasi one-to-one
+	IDProvinsi string    `gorm:"type:varchar(10)"` // Kota asal pengiriman, kosong = mengikuti pemilik toko
+	IDKota     string    `gorm:"type:varchar(10)"`
+	Produk     []Produk  `gorm:"foreignKey:TokoID"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
 }
 
 // Alamat mewakili tabel 'alamat'
@@ -41,6 +72,8 @@ type Alamat struct {
 	NamaPenerima  string `gorm:"type:varchar(100)"`
 	NoTelp        string `gorm:"type:varchar(20)"`
 	DetailAlamat  string `gorm:"type:text"`
+	IDProvinsi    string `gorm:"type:varchar(10)"` // Dipakai untuk menghitung ongkos kirim
+	IDKota        string `gorm:"type:varchar(10)"`
 	UserID        uint   // Foreign key ke User
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
@@ -63,12 +96,15 @@ type Produk struct {
 	HargaReseler   uint
 	HargaKonsumen  uint
 	Stok           uint
+	Berat          uint      // Dalam gram, dipakai untuk menghitung ongkos kirim
 	Deskripsi      string    `gorm:"type:text"`
 	TokoID         uint         // Foreign key ke Toko
 	CategoryID     uint         // Foreign key ke Kategori
 	Toko           Toko         `gorm:"foreignKey:TokoID"`     // Relasi
 	Category       Kategori     `gorm:"foreignKey:CategoryID"` // Relasi
 	FotoProduk     []FotoProduk `gorm:"foreignKey:ProductID"`
+	Opsi           []OpsiProduk   `gorm:"foreignKey:ProductID"` // Kelompok pilihan varian
+	Varian         []VarianProduk `gorm:"foreignKey:ProductID"` // Bila ada, stok produk adalah jumlah stok varian
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
 }
@@ -82,34 +118,139 @@ type FotoProduk struct {
 	UpdatedAt time.Time
 }
 
-// Transaksi mewakili tabel 'transaksi'
+// OpsiProduk mewakili tabel 'opsi_produk'. Kelompok pilihan varian, contoh "Warna" atau "Ukuran".
+type OpsiProduk struct {
+	ID        uint              `gorm:"primaryKey"`
+	ProductID uint              `gorm:"index"` // Foreign key ke Produk
+	Nama      string            `gorm:"type:varchar(100)"`
+	Nilai     []NilaiOpsiProduk `gorm:"foreignKey:OpsiID"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NilaiOpsiProduk mewakili tabel 'nilai_opsi_produk', contoh "Merah" pada opsi "Warna"
+type NilaiOpsiProduk struct {
+	ID        uint   `gorm:"primaryKey"`
+	OpsiID    uint   `gorm:"index"` // Foreign key ke OpsiProduk
+	Nilai     string `gorm:"type:varchar(100)"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// VarianProduk mewakili tabel 'varian_produk'. Satu kombinasi nilai opsi dengan stok sendiri,
+// harga yang kosong mengikuti harga produk.
+type VarianProduk struct {
+	ID            uint   `gorm:"primaryKey"`
+	ProductID     uint   `gorm:"uniqueIndex:idx_varian_produk_sku"` // Foreign key ke Produk
+	SKU           string `gorm:"type:varchar(100);uniqueIndex:idx_varian_produk_sku"`
+	Nama          string `gorm:"type:varchar(255)"` // Gabungan nilai opsi, contoh "Merah / M"
+	HargaReseler  *uint  // Nil = mengikuti harga reseller produk
+	HargaKonsumen *uint  // Nil = mengikuti harga konsumen produk
+	Stok          uint
+	UrlFoto       string            `gorm:"type:varchar(255)"`
+	NilaiOpsi     []NilaiOpsiProduk `gorm:"many2many:varian_nilai_opsi"` // Satu nilai untuk setiap opsi produk
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Transaksi mewakili tabel 'transaksi'.
+// Checkout menghasilkan satu transaksi induk (ParentID nil) yang dibayar sekali,
+// dan satu sub-transaksi per toko (TokoID) yang berisi DetailTransaksi.
 type Transaksi struct {
-	ID              uint   `gorm:"primaryKey"`
-	HargaTotal      uint
-	KodeInvoice     string `gorm:"type:varchar(100);unique"`
-	MethodBayar     string `gorm:"type:varchar(50)"`
-	AlamatKirimID   uint   // Foreign key ke Alamat
-	UserID          uint   // Foreign key ke User
-	Alamat          Alamat `gorm:"foreignKey:AlamatKirimID"` // Relasi
-	DetailTransaksi []DetailTransaksi `gorm:"foreignKey:TransaksiID"`
-	CreatedAt       time.Time
-	UpdatedAt       time.Time
+	ID                 uint  `gorm:"primaryKey"`
+	ParentID           *uint `gorm:"index"` // Foreign key ke Transaksi induk (nil = induk)
+	TokoID             uint  `gorm:"index"` // Toko penjual, hanya diisi pada sub-transaksi
+	Subtotal           uint  // Total harga produk
+	OngkosKirim        uint
+	Diskon             uint               // Total potongan voucher
+	HargaTotal         uint               // Subtotal + OngkosKirim - Diskon
+	KodeInvoice        string             `gorm:"type:varchar(100);unique"`
+	MethodBayar        string             `gorm:"type:varchar(50)"`
+	Status             string             `gorm:"type:varchar(30);default:'pending_payment';index"` // Lihat transaksi_status.go
+	NoResi             string             `gorm:"type:varchar(100)"`                                // Diisi penjual saat pesanan dikirim
+	Kurir              string             `gorm:"type:varchar(50)"`                                 // Opsi pengiriman yang dipilih, hanya pada sub-transaksi
+	LayananKirim       string             `gorm:"type:varchar(50)"`
+	EstimasiKirim      string             `gorm:"type:varchar(50)"`
+	BeratTotal         uint               // Dalam gram
+	CancelledBy        uint               // User yang membatalkan (0 = sistem)
+	CancelReason       string             `gorm:"type:text"`
+	AlamatKirimID      uint               `gorm:"index"` // Alamat pembeli yang dipilih saat checkout (0 untuk pesanan dropship)
+	Dropship           bool               // Dipesan reseller atas nama pelanggannya
+	JudulAlamat        string             `gorm:"type:varchar(100)"` // Snapshot alamat tujuan saat checkout, tidak berubah walau alamat diedit atau dihapus
+	NamaPenerima       string             `gorm:"type:varchar(100)"`
+	NoTelpPenerima     string             `gorm:"type:varchar(20)"`
+	AlamatPenerima     string             `gorm:"type:text"`
+	IDProvinsiPenerima string             `gorm:"type:varchar(10)"`
+	IDKotaPenerima     string             `gorm:"type:varchar(10)"`
+	UserID             uint               // Foreign key ke User
+	DetailTransaksi    []DetailTransaksi  `gorm:"foreignKey:TransaksiID"`
+	LogProduk          []LogProduk        `gorm:"foreignKey:TransaksiID"` // Snapshot produk saat checkout
+	SubTransaksi       []Transaksi        `gorm:"foreignKey:ParentID"`    // Sub-transaksi per toko
+	Pembayaran         *Pembayaran        `gorm:"foreignKey:TransaksiID"` // Hanya pada transaksi induk
+	Voucher            []PemakaianVoucher `gorm:"foreignKey:TransaksiID"` // Hanya pada transaksi induk
+	PaidAt             *time.Time         // Waktu setiap transisi status
+	ProcessedAt        *time.Time
+	ShippedAt          *time.Time
+	DeliveredAt        *time.Time
+	CompletedAt        *time.Time
+	CancelledAt        *time.Time
+	ExpiredAt          *time.Time
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
 }
 
-// DetailTransaksi mewakili tabel 'detail_transaksi'
-type DetailTransaksi struct {
+// Pembayaran mewakili tabel 'pembayaran'
+type Pembayaran struct {
 	ID          uint   `gorm:"primaryKey"`
-	TransaksiID uint   // Foreign key ke Transaksi
-	ProductID   uint   // Foreign key ke Produk
-	TokoID      uint   // Foreign key ke Toko
-	Kuantitas   uint
-	HargaTotal  uint
-	Produk      Produk `gorm:"foreignKey:ProductID"` // Relasi
-	Toko        Toko   `gorm:"foreignKey:TokoID"`    // Relasi
+	TransaksiID uint   `gorm:"uniqueIndex"` // Foreign key ke Transaksi induk
+	Provider    string `gorm:"type:varchar(50)"`
+	Method      string `gorm:"type:varchar(50)"`
+	Reference   string `gorm:"type:varchar(100);index"` // ID tagihan di sisi provider
+	Instruksi   string `gorm:"type:text"`
+	Jumlah      uint
+	Status      string `gorm:"type:varchar(20);default:'pending'"` // pending, paid, failed, expired
+	PaidAt      *time.Time
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 }
 
+// Status pembayaran
+const (
+	PembayaranPending = "pending"
+	PembayaranPaid    = "paid"
+	PembayaranFailed  = "failed"
+	PembayaranExpired = "expired"
+)
+
+// DetailTransaksi mewakili tabel 'detail_transaksi'
+type DetailTransaksi struct {
+	ID             uint `gorm:"primaryKey"`
+	TransaksiID    uint // Foreign key ke Transaksi
+	ProductID      uint // Foreign key ke Produk
+	VarianID       uint `gorm:"index"` // Varian yang dibeli, 0 bila produk tanpa varian
+	TokoID         uint // Foreign key ke Toko
+	Kuantitas      uint
+	HargaSatuan    uint // Harga per unit sesuai tier
+	HargaTotal     uint
+	TierHarga      string    `gorm:"type:varchar(20);default:'konsumen'"` // konsumen atau reseller
+	HargaJual      uint      // Harga per unit yang dibayar pelanggan reseller (dropship)
+	Margin         uint      // (HargaJual - HargaSatuan) * Kuantitas
+	DiskonToko     uint      // Bagian diskon voucher toko pada baris ini, ditanggung toko
+	DiskonPlatform uint      // Bagian diskon voucher platform pada baris ini, ditanggung platform
+	LogProdukID    uint      `gorm:"index"`                  // Foreign key ke LogProduk, snapshot produk saat checkout
+	Produk         Produk    `gorm:"foreignKey:ProductID"`   // Relasi ke produk terkini (bisa sudah berubah atau dihapus)
+	LogProduk      LogProduk `gorm:"foreignKey:LogProdukID"` // Relasi
+	Toko           Toko      `gorm:"foreignKey:TokoID"`      // Relasi
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// Tier harga yang diterapkan pada DetailTransaksi
+const (
+	TierKonsumen = "konsumen"
+	TierReseller = "reseller"
+)
+
 // LogProduk mewakili tabel 'log_produk'
 type LogProduk struct {
 	ID            uint   `gorm:"primaryKey"`
@@ -119,9 +260,238 @@ type LogProduk struct {
 	Slug          string `gorm:"type:varchar(255)"` // Snapshot data
 	HargaReseler  uint   // Snapshot data
 	HargaKonsumen uint   // Snapshot data
-	Deskripsi     string `gorm:"type:text"`         // Snapshot data
+	Deskripsi     string `gorm:"type:text"` // Snapshot data
 	TokoID        uint   // Snapshot data
 	CategoryID    uint   // Snapshot data
+	NamaCategory  string `gorm:"type:varchar(255)"` // Snapshot data
+	Berat         uint   // Snapshot data, dalam gram
+	VarianID      uint   // ID varian asli, 0 bila produk tanpa varian
+	SKU           string `gorm:"type:varchar(100)"` // Snapshot data varian
+	NamaVarian    string `gorm:"type:varchar(255)"` // Snapshot data varian
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// InvoiceSequence mewakili tabel 'invoice_sequence'.
+// Satu baris penghitung untuk setiap scope (format invoice dengan tanggal yang sudah diisi).
+type InvoiceSequence struct {
+	Scope     string `gorm:"type:varchar(100);primaryKey"`
+	Nilai     uint64 // Nomor urut terakhir yang sudah dibagikan
+	UpdatedAt time.Time
+}
+
+// IdempotencyRecord mewakili tabel 'idempotency_record'.
+// Menyimpan respons pertama dari request yang dikirim dengan header Idempotency-Key.
+type IdempotencyRecord struct {
+	ID             uint   `gorm:"primaryKey"`
+	UserID         uint   `gorm:"uniqueIndex:idx_idempotency_user_key"`
+	IdempotencyKey string `gorm:"type:varchar(255);uniqueIndex:idx_idempotency_user_key"`
+	RequestHash    string `gorm:"type:varchar(64)"`                      // SHA-256 dari method, path, dan body
+	Status         string `gorm:"type:varchar(20);default:'processing'"` // processing, completed
+	ResponseCode   int
+	ResponseBody   string    `gorm:"type:longtext"`
+	LeaseToken     string    `gorm:"type:varchar(64)"` // Penanda request yang sedang memegang key
+	ExpiresAt      time.Time `gorm:"index"`            // Akhir lease saat processing, akhir masa simpan respons saat completed
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// Status IdempotencyRecord
+const (
+	IdempotencyProcessing = "processing"
+	IdempotencyCompleted  = "completed"
+)
+
+// Retur mewakili tabel 'retur'.
+// Pengajuan pengembalian barang oleh pembeli untuk satu sub-transaksi (per toko).
+type Retur struct {
+	ID             uint        `gorm:"primaryKey"`
+	TransaksiID    uint        `gorm:"index"` // Foreign key ke sub-transaksi
+	UserID         uint        `gorm:"index"` // Pembeli yang mengajukan
+	TokoID         uint        `gorm:"index"` // Toko penjual yang memutuskan
+	Alasan         string      `gorm:"type:text"`
+	Status         string      `gorm:"type:varchar(20);default:'requested';index"` // requested, approved, rejected
+	CatatanPenjual string      `gorm:"type:text"`
+	Transaksi      Transaksi   `gorm:"foreignKey:TransaksiID"`
+	Items          []ReturItem `gorm:"foreignKey:ReturID"`
+	FotoRetur      []FotoRetur `gorm:"foreignKey:ReturID"`
+	Refund         *Refund     `gorm:"foreignKey:ReturID"` // Dibuat saat retur disetujui
+	ApprovedAt     *time.Time
+	RejectedAt     *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// Status Retur
+const (
+	ReturRequested = "requested"
+	ReturApproved  = "approved"
+	ReturRejected  = "rejected"
+)
+
+// ReturItem mewakili tabel 'retur_item'
+type ReturItem struct {
+	ID                uint   `gorm:"primaryKey"`
+	ReturID           uint   `gorm:"index"` // Foreign key ke Retur
+	DetailTransaksiID uint   `gorm:"index"` // Baris pesanan yang dikembalikan
+	ProductID         uint   // Produk yang stoknya dikembalikan
+	VarianID          uint   // Varian yang stoknya dikembalikan, 0 bila produk tanpa varian
+	NamaProduk        string `gorm:"type:varchar(255)"` // Snapshot data
+	Kuantitas         uint
+	JumlahRefund      uint // Harga baris dibagi proporsional sesuai kuantitas
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// FotoRetur mewakili tabel 'foto_retur'
+type FotoRetur struct {
+	ID        uint   `gorm:"primaryKey"`
+	ReturID   uint   // Foreign key ke Retur
+	Url       string `gorm:"type:varchar(255)"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Refund mewakili tabel 'refund'.
+// Dicatat terhadap pembayaran transaksi induk saat retur disetujui atau pesanan yang sudah dibayar dibatalkan.
+type Refund struct {
+	ID           uint  `gorm:"primaryKey"`
+	ReturID      *uint `gorm:"uniqueIndex"` // Foreign key ke Retur, kosong untuk refund pembatalan
+	TransaksiID  uint  `gorm:"index"`       // Transaksi yang diretur atau dibatalkan
+	PembayaranID uint  `gorm:"index"`       // Foreign key ke Pembayaran asal
+	Jumlah       uint
+	Status       string `gorm:"type:varchar(20);default:'pending'"` // pending = menunggu dikirim ke pembeli
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// Status Refund
+const (
+	RefundPending = "pending"
+)
+
+// Jurnal mewakili tabel 'jurnal'.
+// Satu kejadian keuangan (penjualan selesai, refund, penarikan) dicatat sebagai satu jurnal
+// dengan beberapa LedgerEntry yang total debit dan kreditnya selalu sama.
+type Jurnal struct {
+	ID         uint          `gorm:"primaryKey"`
+	Referensi  string        `gorm:"type:varchar(100);uniqueIndex"` // Contoh: penjualan:12, refund:3, penarikan:5
+	Keterangan string        `gorm:"type:varchar(255)"`
+	FeeBps     uint          // Biaya platform (basis poin) yang dipakai saat jurnal dibuat
+	Entries    []LedgerEntry `gorm:"foreignKey:JurnalID"`
+	CreatedAt  time.Time
+}
+
+// LedgerEntry mewakili tabel 'ledger_entry'.
+// Saldo setiap akun dihitung murni dari jumlah kredit dikurangi debit.
+type LedgerEntry struct {
+	ID         uint   `gorm:"primaryKey"`
+	JurnalID   uint   `gorm:"index"` // Foreign key ke Jurnal
+	Akun       string `gorm:"type:varchar(50);index:idx_ledger_akun_toko"`
+	TokoID     uint   `gorm:"index:idx_ledger_akun_toko"` // Diisi untuk akun saldo toko
+	Debit      uint
+	Kredit     uint
+	Keterangan string `gorm:"type:varchar(255)"`
+	Jurnal     Jurnal `gorm:"foreignKey:JurnalID"`
+	CreatedAt  time.Time
+}
+
+// Akun pada ledger
+const (
+	AkunKasPlatform        = "platform_kas"        // Dana pembeli yang dipegang platform
+	AkunPendapatanPlatform = "platform_pendapatan" // Biaya platform dari setiap penjualan
+	AkunSaldoToko          = "toko_saldo"          // Dana milik toko yang dapat ditarik
+	AkunPromosiPlatform    = "platform_promosi"    // Potongan voucher platform yang ditanggung platform
+)
+
+// Penarikan mewakili tabel 'penarikan'.
+// Permintaan pencairan saldo toko yang diputuskan oleh admin.
+type Penarikan struct {
+	ID           uint `gorm:"primaryKey"`
+	TokoID       uint `gorm:"index"` // Foreign key ke Toko
+	Jumlah       uint
+	NamaBank     string `gorm:"type:varchar(100)"`
+	NoRekening   string `gorm:"type:varchar(50)"`
+	AtasNama     string `gorm:"type:varchar(100)"`
+	Status       string `gorm:"type:varchar(20);default:'pending';index"` // pending, approved, rejected
+	CatatanAdmin string `gorm:"type:text"`
+	ReviewedBy   uint   // Admin yang memutuskan
+	ReviewedAt   *time.Time
+	Toko         Toko `gorm:"foreignKey:TokoID"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// Status Penarikan
+const (
+	PenarikanPending  = "pending"
+	PenarikanApproved = "approved"
+	PenarikanRejected = "rejected"
+)
+
+// Voucher mewakili tabel 'voucher'.
+// Voucher platform (TokoID 0) dibuat admin dan berlaku untuk semua toko,
+// voucher toko dibuat penjual dan hanya memotong item dari tokonya.
+type Voucher struct {
+	ID            uint   `gorm:"primaryKey"`
+	Kode          string `gorm:"type:varchar(50);uniqueIndex"`
+	Nama          string `gorm:"type:varchar(255)"`
+	TokoID        uint   `gorm:"index"`            // 0 = voucher platform
+	Tipe          string `gorm:"type:varchar(20)"` // persen atau nominal
+	Nilai         uint   // Persentase (1-100) atau nominal rupiah
+	MaksDiskon    uint   // Batas potongan untuk tipe persen, 0 = tanpa batas
+	MinBelanja    uint   // Dihitung dari item yang memenuhi syarat
+	KuotaTotal    uint   // 0 = tanpa batas
+	KuotaPerUser  uint   // 0 = tanpa batas
+	Terpakai      uint   // Jumlah pemakaian seluruh user
+	BerlakuMulai  time.Time
+	BerlakuSampai time.Time
+	Aktif         bool       `gorm:"default:true"`
+	Kategori      []Kategori `gorm:"many2many:voucher_kategoris"` // Kosong = semua kategori
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
-}
\ No newline at end of file
+}
+
+// Tipe potongan voucher
+const (
+	VoucherPersen  = "persen"
+	VoucherNominal = "nominal"
+)
+
+// PemakaianVoucher mewakili tabel 'pemakaian_voucher'.
+// Mencatat potongan yang diberikan sebuah voucher pada satu transaksi induk.
+type PemakaianVoucher struct {
+	ID          uint      `gorm:"primaryKey"`
+	VoucherID   uint      `gorm:"index:idx_pemakaian_voucher_user"` // Foreign key ke Voucher
+	UserID      uint      `gorm:"index:idx_pemakaian_voucher_user"`
+	TransaksiID uint      `gorm:"index"` // Foreign key ke Transaksi induk
+	TokoID      uint      // Sama dengan Voucher.TokoID
+	Kode        string    `gorm:"type:varchar(50)"`
+	Jumlah      uint      // Total potongan
+	Transaksi   Transaksi `gorm:"foreignKey:TransaksiID"`
+	CreatedAt   time.Time
+}
+
+// KeranjangItem mewakili tabel 'keranjang_item'. Satu baris untuk setiap produk (atau varian) di keranjang user.
+type KeranjangItem struct {
+	ID          uint `gorm:"primaryKey"`
+	UserID      uint `gorm:"uniqueIndex:idx_keranjang_user_produk_varian"` // Foreign key ke User
+	ProductID   uint `gorm:"uniqueIndex:idx_keranjang_user_produk_varian"` // Foreign key ke Produk
+	VarianID    uint `gorm:"uniqueIndex:idx_keranjang_user_produk_varian"` // Varian yang dipilih, 0 bila produk tanpa varian
+	Kuantitas   uint
+	HargaSatuan uint   // Harga per unit saat item terakhir ditambahkan, untuk mendeteksi perubahan harga
+	Produk      Produk `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"` // Item ikut terhapus bila produk dihapus
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Wishlist mewakili tabel 'wishlist'. Produk yang disimpan user untuk dibeli nanti.
+type Wishlist struct {
+	ID                uint   `gorm:"primaryKey"`
+	UserID            uint   `gorm:"uniqueIndex:idx_wishlist_user_produk"` // Foreign key ke User
+	ProductID         uint   `gorm:"uniqueIndex:idx_wishlist_user_produk"` // Foreign key ke Produk
+	HargaSaatDisimpan uint   // Harga per unit sesuai tier user saat produk disimpan
+	StokSaatDisimpan  uint   // Stok produk saat disimpan
+	Produk            Produk `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"` // Ikut terhapus bila produk dihapus
+	CreatedAt         time.Time
+}
@@ -0,0 +1,180 @@
+package model
+
+import (
+	"time"
+)
+
+// Status pesanan (Transaksi)
+const (
+	StatusPendingPayment = "pending_payment"
+	StatusPaid           = "paid"
+	StatusProcessing     = "processing"
+	StatusShipped        = "shipped"
+	StatusDelivered      = "delivered"
+	StatusCompleted      = "completed"
+	StatusCancelled      = "cancelled"
+	StatusExpired        = "expired"
+)
+
+// transisiStatus berisi daftar status tujuan yang sah dari setiap status
+var transisiStatus = map[string][]string{
+	StatusPendingPayment: {StatusPaid, StatusCancelled, StatusExpired},
+	StatusPaid:           {StatusProcessing, StatusCancelled},
+	StatusProcessing:     {StatusShipped, StatusCancelled},
+	StatusShipped:        {StatusDelivered},
+	StatusDelivered:      {StatusCompleted},
+	StatusCompleted:      {},
+	StatusCancelled:      {},
+	StatusExpired:        {},
+}
+
+// IsValidStatus mengecek apakah status dikenal
+func IsValidStatus(status string) bool {
+	_, ok := transisiStatus[status]
+	return ok
+}
+
+// CanTransition mengecek apakah perpindahan status dari 'from' ke 'to' diizinkan
+func CanTransition(from, to string) bool {
+	for _, next := range transisiStatus[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// SetStatus mengubah status transaksi dan mencatat waktu transisinya
+func (t *Transaksi) SetStatus(status string, at time.Time) {
+	t.Status = status
+	switch status {
+	case StatusPaid:
+		t.PaidAt = &at
+	case StatusProcessing:
+		t.ProcessedAt = &at
+	case StatusShipped:
+		t.ShippedAt = &at
+	case StatusDelivered:
+		t.DeliveredAt = &at
+	case StatusCompleted:
+		t.CompletedAt = &at
+	case StatusCancelled:
+		t.CancelledAt = &at
+	case StatusExpired:
+		t.ExpiredAt = &at
+	}
+}
+
+// IsFinalStatus mengecek apakah status sudah tidak dapat berubah lagi
+func IsFinalStatus(status string) bool {
+	return len(transisiStatus[status]) == 0
+}
+
+// AggregateStatus menentukan status transaksi induk dari status sub-transaksinya.
+// Jika semua sub-transaksi berstatus sama, induk mengikuti status tersebut.
+// Jika semuanya sudah final namun berbeda, induk dianggap selesai bila ada
+// sub-transaksi yang selesai, dan dibatalkan bila tidak ada.
+// Selain itu status induk tidak berubah (ok = false).
+func AggregateStatus(children []Transaksi) (status string, ok bool) {
+	if len(children) == 0 {
+		return "", false
+	}
+
+	allSame, allFinal, anyCompleted := true, true, false
+	for _, child := range children {
+		if child.Status != children[0].Status {
+			allSame = false
+		}
+		if !IsFinalStatus(child.Status) {
+			allFinal = false
+		}
+		if child.Status == StatusCompleted {
+			anyCompleted = true
+		}
+	}
+
+	switch {
+	case allSame:
+		return children[0].Status, true
+	case allFinal && anyCompleted:
+		return StatusCompleted, true
+	case allFinal:
+		return StatusCancelled, true
+	}
+	return "", false
+}
+
+// IsParent mengecek apakah transaksi adalah transaksi induk dari beberapa sub-transaksi
+func (t Transaksi) IsParent() bool {
+	return t.ParentID == nil && len(t.SubTransaksi) > 0
+}
+
+// AllDetails mengumpulkan DetailTransaksi milik transaksi beserta seluruh sub-transaksinya
+func (t Transaksi) AllDetails() []DetailTransaksi {
+	details := append([]DetailTransaksi{}, t.DetailTransaksi...)
+	for _, sub := range t.SubTransaksi {
+		details = append(details, sub.DetailTransaksi...)
+	}
+	return details
+}
+
+// BagianRefund menghitung porsi baris untuk sejumlah kuantitas yang dikembalikan:
+// nilai yang menjadi hak toko (setelah diskon toko) dan diskon platform yang melekat padanya.
+// Pembeli menerima nilaiToko - diskonPlatform.
+func (d DetailTransaksi) BagianRefund(kuantitas uint) (nilaiToko uint, diskonPlatform uint) {
+	if d.Kuantitas == 0 {
+		return 0, 0
+	}
+	nilaiToko = (d.HargaTotal - d.DiskonToko) * kuantitas / d.Kuantitas
+	diskonPlatform = d.DiskonPlatform * kuantitas / d.Kuantitas
+	return nilaiToko, diskonPlatform
+}
+
+// HargaTier menentukan tier dan harga per unit produk untuk pembeli.
+// Reseller memakai harga reseller, produk tanpa harga reseller tetap memakai harga konsumen.
+func (p Produk) HargaTier(isReseller bool) (tier string, harga uint) {
+	if isReseller && p.HargaReseler > 0 {
+		return TierReseller, p.HargaReseler
+	}
+	return TierKonsumen, p.HargaKonsumen
+}
+
+// DenganVarian mengembalikan produk dengan harga dan stok milik varian.
+// Harga varian yang kosong mengikuti harga produk.
+func (p Produk) DenganVarian(v VarianProduk) Produk {
+	if v.HargaReseler != nil {
+		p.HargaReseler = *v.HargaReseler
+	}
+	if v.HargaKonsumen != nil {
+		p.HargaKonsumen = *v.HargaKonsumen
+	}
+	p.Stok = v.Stok
+	return p
+}
+
+// CariVarian mencari varian produk berdasarkan ID dari relasi Varian yang sudah dimuat
+func (p Produk) CariVarian(varianID uint) (VarianProduk, bool) {
+	for _, v := range p.Varian {
+		if v.ID == varianID {
+			return v, true
+		}
+	}
+	return VarianProduk{}, false
+}
+
+// AlamatTujuan mengembalikan snapshot alamat pengiriman transaksi.
+// Pesanan dropship dikirim ke pelanggan reseller, bukan ke alamat milik pembeli.
+func (t Transaksi) AlamatTujuan() (namaPenerima, noTelp, detailAlamat string) {
+	return t.NamaPenerima, t.NoTelpPenerima, t.AlamatPenerima
+}
+
+// SetAlamatTujuan menyalin alamat tujuan ke transaksi sebagai snapshot saat checkout
+func (t *Transaksi) SetAlamatTujuan(a Alamat) {
+	t.AlamatKirimID = a.ID
+	t.JudulAlamat = a.JudulAlamat
+	t.NamaPenerima = a.NamaPenerima
+	t.NoTelpPenerima = a.NoTelp
+	t.AlamatPenerima = a.DetailAlamat
+	t.IDProvinsiPenerima = a.IDProvinsi
+	t.IDKotaPenerima = a.IDKota
+}
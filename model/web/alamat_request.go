@@ -6,6 +6,8 @@ type AlamatCreateRequest struct {
 	NamaPenerima string `json:"nama_penerima" validate:"required"`
 	NoTelp       string `json:"no_telp" validate:"required"`
 	DetailAlamat string `json:"detail_alamat" validate:"required"`
+	IDProvinsi   string `json:"id_provinsi" validate:"required"`
+	IDKota       string `json:"id_kota" validate:"required"`
 }
 
 // Struct untuk parsing JSON body saat PUT /user/alamat/:id
@@ -14,4 +16,6 @@ type AlamatUpdateRequest struct {
 	NamaPenerima string `json:"nama_penerima"`
 	NoTelp       string `json:"no_telp"`
 	DetailAlamat string `json:"detail_alamat"`
+	IDProvinsi   string `json:"id_provinsi"`
+	IDKota       string `json:"id_kota"`
 }
\ No newline at end of file
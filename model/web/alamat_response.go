@@ -6,5 +6,7 @@ type AlamatResponse struct {
 	NamaPenerima string `json:"nama_penerima"`
 	NoTelp       string `json:"no_telp"`
 	DetailAlamat string `json:"detail_alamat"`
+	IDProvinsi   string `json:"id_provinsi,omitempty"`
+	IDKota       string `json:"id_kota,omitempty"`
 	UserID       uint   `json:"user_id"` 
 }
\ No newline at end of file
@@ -0,0 +1,22 @@
+package web
+
+// Struct untuk parsing JSON body saat POST /user/keranjang
+type KeranjangAddRequest struct {
+	ProductID uint `json:"product_id" validate:"required"`
+	VarianID  uint `json:"varian_id"`                           // Wajib bila produk memiliki varian
+	Kuantitas uint `json:"kuantitas" validate:"required,min=1"` // Ditambahkan ke kuantitas yang sudah ada
+}
+
+// Struct untuk parsing JSON body saat PUT /user/keranjang/:id
+type KeranjangUpdateRequest struct {
+	Kuantitas uint `json:"kuantitas" validate:"required,min=1"`
+}
+
+// Struct untuk parsing JSON body saat POST /user/keranjang/checkout
+type KeranjangCheckoutRequest struct {
+	ItemIDs     []uint              `json:"item_ids" validate:"required,min=1"` // Item keranjang yang dipilih
+	MethodBayar string              `json:"method_bayar" validate:"required"`
+	AlamatKirim uint                `json:"alamat_kirim" validate:"required"`
+	Pengiriman  []PengirimanRequest `json:"pengiriman" validate:"required,min=1"` // Satu opsi untuk setiap toko
+	KodeVoucher []string            `json:"kode_voucher"`
+}
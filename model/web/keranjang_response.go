@@ -0,0 +1,28 @@
+package web
+
+type KeranjangItemResponse struct {
+	ID              uint                   `json:"id"`
+	Produk          ProdukResponse         `json:"product"`
+	Varian          *VarianRingkasResponse `json:"varian,omitempty"`
+	Kuantitas       uint                   `json:"kuantitas"`
+	HargaSatuan     uint                   `json:"harga_satuan"`     // Harga per unit saat ini sesuai tier pembeli
+	HargaSebelumnya uint                   `json:"harga_sebelumnya"` // Harga per unit saat item ditambahkan
+	Subtotal        uint                   `json:"subtotal"`
+	HargaBerubah    bool                   `json:"harga_berubah"`
+	StokCukup       bool                   `json:"stok_cukup"`
+	Pesan           string                 `json:"pesan,omitempty"` // Alasan item belum dapat di-checkout atau perlu diperhatikan
+}
+
+// Item keranjang dikelompokkan per toko, sesuai sub-pesanan yang akan terbentuk saat checkout
+type KeranjangTokoResponse struct {
+	Toko     TokoResponse            `json:"toko"`
+	Items    []KeranjangItemResponse `json:"items"`
+	Subtotal uint                    `json:"subtotal"`
+}
+
+type KeranjangResponse struct {
+	Toko      []KeranjangTokoResponse `json:"toko"`
+	TotalItem uint                    `json:"total_item"` // Jumlah kuantitas seluruh item
+	Total     uint                    `json:"total"`
+	SiapBayar bool                    `json:"siap_bayar"` // Semua item stoknya cukup
+}
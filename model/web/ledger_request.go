@@ -0,0 +1,14 @@
+package web
+
+// Struct untuk parsing JSON body saat POST /toko/my/penarikan
+type PenarikanCreateRequest struct {
+	Jumlah     uint   `json:"jumlah" validate:"required,min=1"`
+	NamaBank   string `json:"nama_bank" validate:"required"`
+	NoRekening string `json:"no_rekening" validate:"required"`
+	AtasNama   string `json:"atas_nama" validate:"required"`
+}
+
+// Struct untuk parsing JSON body saat admin menyetujui atau menolak penarikan
+type PenarikanDecisionRequest struct {
+	Catatan string `json:"catatan"` // Wajib saat penarikan ditolak
+}
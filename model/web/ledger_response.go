@@ -0,0 +1,49 @@
+package web
+
+import "time"
+
+// Struct untuk respons GET /toko/my/saldo
+type SaldoResponse struct {
+	Saldo         int64 `json:"saldo"`          // Dihitung dari seluruh entry ledger toko
+	SaldoDitahan  int64 `json:"saldo_ditahan"`  // Penarikan yang menunggu keputusan admin
+	SaldoTersedia int64 `json:"saldo_tersedia"` // Saldo yang masih dapat ditarik
+}
+
+type LedgerEntryResponse struct {
+	ID         uint      `json:"id"`
+	Referensi  string    `json:"referensi"`
+	Keterangan string    `json:"keterangan"`
+	Debit      uint      `json:"debit"`
+	Kredit     uint      `json:"kredit"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Struct untuk respons GET /toko/my/saldo/riwayat dengan pagination
+type PaginatedLedgerEntryResponse struct {
+	Page       int                   `json:"page"`
+	Limit      int                   `json:"limit"`
+	TotalItems int64                 `json:"total_items"`
+	TotalPages int                   `json:"total_pages"`
+	Data       []LedgerEntryResponse `json:"data"`
+}
+
+type PenarikanResponse struct {
+	ID           uint       `json:"id"`
+	TokoID       uint       `json:"toko_id"`
+	NamaToko     string     `json:"nama_toko"`
+	Jumlah       uint       `json:"jumlah"`
+	NamaBank     string     `json:"nama_bank"`
+	NoRekening   string     `json:"no_rekening"`
+	AtasNama     string     `json:"atas_nama"`
+	Status       string     `json:"status"`
+	CatatanAdmin string     `json:"catatan_admin,omitempty"`
+	ReviewedAt   *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// Struct untuk respons daftar penarikan dengan pagination
+type PaginatedPenarikanResponse struct {
+	Page  int                 `json:"page"`
+	Limit int                 `json:"limit"`
+	Data  []PenarikanResponse `json:"data"`
+}
@@ -6,6 +6,7 @@ type ProdukCreateRequest struct {
 	HargaReseler  uint   `validate:"required"`
 	HargaKonsumen uint   `validate:"required"`
 	Stok          uint   `validate:"required"`
+	Berat         uint   // Dalam gram, opsional
 	Deskripsi     string `validate:"required"`
 }
 
@@ -15,5 +16,27 @@ type ProdukUpdateRequest struct {
 	HargaReseler  uint
 	HargaKonsumen uint
 	Stok          uint
+	Berat         uint
 	Deskripsi     string
-}
\ No newline at end of file
+}
+
+// Struct untuk parsing JSON body saat POST /product/:id/opsi
+type OpsiCreateRequest struct {
+	Nama  string   `json:"nama"`  // Contoh "Warna"
+	Nilai []string `json:"nilai"` // Contoh ["Merah", "Biru"]
+}
+
+type VarianCreateRequest struct {
+	SKU           string
+	NilaiOpsiIDs  []uint // Satu nilai untuk setiap opsi produk
+	Stok          uint
+	HargaReseler  *uint // Opsional, kosong = mengikuti harga produk
+	HargaKonsumen *uint // Opsional, kosong = mengikuti harga produk
+}
+
+type VarianUpdateRequest struct {
+	SKU           string
+	Stok          *uint
+	HargaReseler  *uint
+	HargaKonsumen *uint
+}
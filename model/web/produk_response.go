@@ -7,20 +7,55 @@ type FotoProdukResponse struct {
 }
 
 type ProdukResponse struct {
-	ID            uint                 `json:"id"`
-	NamaProduk    string               `json:"nama_produk"`
-	Slug          string               `json:"slug"`
-	HargaReseler  uint                 `json:"harga_reseler"`
-	HargaKonsumen uint                 `json:"harga_konsumen"`
-	Stok          uint                 `json:"stok"`
-	Deskripsi     string               `json:"deskripsi"`
-	Toko          TokoResponse         `json:"toko"`     // Relasi
-	Category      KategoriResponse     `json:"category"` // Relasi
-	Photos        []FotoProdukResponse `json:"photos"`   // Relasi
+	ID            uint                   `json:"id"`
+	NamaProduk    string                 `json:"nama_produk"`
+	Slug          string                 `json:"slug"`
+	HargaReseler  *uint                  `json:"harga_reseler,omitempty"` // Hanya untuk reseller, admin, dan pemilik toko
+	HargaKonsumen uint                   `json:"harga_konsumen"`
+	Stok          uint                   `json:"stok"`
+	Berat         uint                   `json:"berat"` // Dalam gram
+	Deskripsi     string                 `json:"deskripsi"`
+	Toko          TokoResponse           `json:"toko"`             // Relasi
+	Category      KategoriResponse       `json:"category"`         // Relasi
+	Photos        []FotoProdukResponse   `json:"photos"`           // Relasi
+	Opsi          []OpsiProdukResponse   `json:"opsi,omitempty"`   // Kelompok pilihan varian
+	Varian        []VarianProdukResponse `json:"varian,omitempty"` // Harga sudah memperhitungkan harga produk
+}
+
+type NilaiOpsiResponse struct {
+	ID    uint   `json:"id"`
+	Nilai string `json:"nilai"`
+}
+
+type OpsiProdukResponse struct {
+	ID    uint                `json:"id"`
+	Nama  string              `json:"nama"`
+	Nilai []NilaiOpsiResponse `json:"nilai"`
+}
+
+type VarianProdukResponse struct {
+	ID            uint                `json:"id"`
+	SKU           string              `json:"sku"`
+	Nama          string              `json:"nama"`
+	NilaiOpsi     []NilaiOpsiResponse `json:"nilai_opsi"`
+	HargaReseler  *uint               `json:"harga_reseler,omitempty"` // Hanya untuk reseller, admin, dan pemilik toko
+	HargaKonsumen uint                `json:"harga_konsumen"`
+	Stok          uint                `json:"stok"`
+	UrlFoto       string              `json:"url_foto"`
+}
+
+// Varian yang dipilih pada item keranjang atau transaksi
+type VarianRingkasResponse struct {
+	ID   uint   `json:"id"`
+	SKU  string `json:"sku"`
+	Nama string `json:"nama"`
 }
 
 type PaginatedProdukResponse struct {
-	Page  int              `json:"page"`
-	Limit int              `json:"limit"`
-	Data  []ProdukResponse `json:"data"`
+	Page       int              `json:"page"`
+	Limit      int              `json:"limit"`
+	TotalItems int64            `json:"total_items"`
+	TotalPages int              `json:"total_pages"`
+	NextCursor string           `json:"next_cursor,omitempty"` // Hanya pada mode cursor
+	Data       []ProdukResponse `json:"data"`
 }
\ No newline at end of file
@@ -0,0 +1,11 @@
+package web
+
+// Struct untuk parsing JSON body saat POST /user/reseller
+type ResellerApplyRequest struct {
+	Alasan string `json:"alasan" validate:"required"`
+}
+
+// Struct untuk parsing JSON body saat admin menyetujui atau menolak pengajuan
+type ResellerDecisionRequest struct {
+	Catatan string `json:"catatan"` // Wajib saat pengajuan ditolak
+}
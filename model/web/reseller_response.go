@@ -0,0 +1,38 @@
+package web
+
+import "time"
+
+type PengajuanResellerResponse struct {
+	ID           uint       `json:"id"`
+	UserID       uint       `json:"user_id"`
+	NamaUser     string     `json:"nama_user,omitempty"`
+	Alasan       string     `json:"alasan"`
+	Status       string     `json:"status"`
+	CatatanAdmin string     `json:"catatan_admin,omitempty"`
+	ReviewedAt   *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// Struct untuk respons GET /admin/reseller dengan pagination
+type PaginatedPengajuanResellerResponse struct {
+	Page  int                         `json:"page"`
+	Limit int                         `json:"limit"`
+	Data  []PengajuanResellerResponse `json:"data"`
+}
+
+type MarginPeriodeResponse struct {
+	Periode        string `json:"periode"`
+	JumlahPesanan  int64  `json:"jumlah_pesanan"`
+	TotalPenjualan uint64 `json:"total_penjualan"`
+	TotalModal     uint64 `json:"total_modal"`
+	TotalMargin    uint64 `json:"total_margin"`
+}
+
+// Struct untuk respons GET /user/reseller/margin
+type MarginReportResponse struct {
+	Periode        string                  `json:"periode"` // harian, bulanan, tahunan
+	TotalPenjualan uint64                  `json:"total_penjualan"`
+	TotalModal     uint64                  `json:"total_modal"`
+	TotalMargin    uint64                  `json:"total_margin"`
+	Data           []MarginPeriodeResponse `json:"data"`
+}
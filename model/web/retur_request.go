@@ -0,0 +1,18 @@
+package web
+
+type ReturItemRequest struct {
+	DetailTransaksiID uint `json:"detail_transaksi_id" validate:"required"`
+	Kuantitas         uint `json:"kuantitas" validate:"required,min=1"`
+}
+
+// Struct untuk parsing form-data saat POST /trx/:id/retur.
+// Field 'items' dikirim sebagai teks JSON, foto bukti dikirim sebagai 'photos'.
+type ReturCreateRequest struct {
+	Alasan string             `json:"alasan" validate:"required"`
+	Items  []ReturItemRequest `json:"items" validate:"required,min=1"`
+}
+
+// Struct untuk parsing JSON body saat penjual menyetujui atau menolak retur
+type ReturDecisionRequest struct {
+	Catatan string `json:"catatan"` // Wajib saat retur ditolak
+}
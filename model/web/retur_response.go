@@ -0,0 +1,49 @@
+package web
+
+import "time"
+
+type ReturItemResponse struct {
+	ID                uint   `json:"id"`
+	DetailTransaksiID uint   `json:"detail_transaksi_id"`
+	ProductID         uint   `json:"product_id"`
+	NamaProduk        string `json:"nama_produk"`
+	Kuantitas         uint   `json:"kuantitas"`
+	JumlahRefund      uint   `json:"jumlah_refund"`
+}
+
+type FotoReturResponse struct {
+	ID  uint   `json:"id"`
+	Url string `json:"url"`
+}
+
+type RefundResponse struct {
+	ID           uint      `json:"id"`
+	PembayaranID uint      `json:"pembayaran_id"`
+	Jumlah       uint      `json:"jumlah"`
+	Status       string    `json:"status"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type ReturResponse struct {
+	ID             uint                `json:"id"`
+	TransaksiID    uint                `json:"transaksi_id"`
+	KodeInvoice    string              `json:"kode_invoice"`
+	TokoID         uint                `json:"toko_id"`
+	UserID         uint                `json:"user_id"`
+	Alasan         string              `json:"alasan"`
+	Status         string              `json:"status"`
+	CatatanPenjual string              `json:"catatan_penjual,omitempty"`
+	Items          []ReturItemResponse `json:"items"`
+	Photos         []FotoReturResponse `json:"photos"`
+	Refund         *RefundResponse     `json:"refund,omitempty"`
+	ApprovedAt     *time.Time          `json:"approved_at,omitempty"`
+	RejectedAt     *time.Time          `json:"rejected_at,omitempty"`
+	CreatedAt      time.Time           `json:"created_at"`
+}
+
+// Struct untuk respons GET /toko/my/retur dengan pagination
+type PaginatedReturResponse struct {
+	Page  int             `json:"page"`
+	Limit int             `json:"limit"`
+	Data  []ReturResponse `json:"data"`
+}
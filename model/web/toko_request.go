@@ -1,5 +1,7 @@
 package web
 
 type TokoUpdateRequest struct {
-	NamaToko string `json:"nama_toko" validate:"required"`
+	NamaToko   string `json:"nama_toko" validate:"required"`
+	IDProvinsi string `json:"id_provinsi"` // Kota asal pengiriman
+	IDKota     string `json:"id_kota"`
 }
\ No newline at end of file
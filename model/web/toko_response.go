@@ -1,14 +1,19 @@
 package web
 
 type TokoResponse struct {
-	ID       uint   `json:"id"`
-	NamaToko string `json:"nama_toko"`
-	UrlFoto  string `json:"url_foto"`
-	UserID   uint   `json:"user_id,omitempty"` // Hanya diisi untuk GetMyToko
+	ID         uint   `json:"id"`
+	NamaToko   string `json:"nama_toko"`
+	UrlFoto    string `json:"url_foto"`
+	UserID     uint   `json:"user_id,omitempty"` // Hanya diisi untuk GetMyToko
+	IDProvinsi string `json:"id_provinsi,omitempty"`
+	IDKota     string `json:"id_kota,omitempty"`
 }
 
 type PaginatedTokoResponse struct {
-	Page  int            `json:"page"`
-	Limit int            `json:"limit"`
-	Data  []TokoResponse `json:"data"`
+	Page       int            `json:"page"`
+	Limit      int            `json:"limit"`
+	TotalItems int64          `json:"total_items"`
+	TotalPages int            `json:"total_pages"`
+	NextCursor string         `json:"next_cursor,omitempty"` // Hanya pada mode cursor
+	Data       []TokoResponse `json:"data"`
 }
\ No newline at end of file
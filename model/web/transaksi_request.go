@@ -1,12 +1,51 @@
 package web
 
 type DetailTransaksiRequest struct {
-	ProductID uint `json:"product_id" validate:"required"`
-	Kuantitas uint `json:"kuantitas" validate:"required,min=1"`
+	ProductID   uint  `json:"product_id" validate:"required"`
+	VarianID    uint  `json:"varian_id"` // Wajib bila produk memiliki varian
+	Kuantitas   uint  `json:"kuantitas" validate:"required,min=1"`
+	HargaJual   uint  `json:"harga_jual"`   // Wajib pada mode dropship, minimal harga reseller
+	HargaSatuan *uint `json:"harga_satuan"` // Opsional, harga per unit yang ditampilkan ke pembeli; checkout ditolak bila berbeda
+}
+
+// Data pelanggan reseller pada mode dropship
+type DropshipRequest struct {
+	NamaPenerima string `json:"nama_penerima" validate:"required"`
+	NoTelp       string `json:"no_telp" validate:"required"`
+	DetailAlamat string `json:"detail_alamat" validate:"required"`
+	IDProvinsi   string `json:"id_provinsi" validate:"required"` // Dipakai untuk menghitung ongkos kirim
+	IDKota       string `json:"id_kota" validate:"required"`
+}
+
+// Opsi pengiriman yang dipilih pembeli untuk satu toko
+type PengirimanRequest struct {
+	TokoID uint   `json:"toko_id" validate:"required"`
+	Kode   string `json:"kode" validate:"required"` // Kode opsi dari POST /trx/quote, contoh "jne_reg"
 }
 
 type TransaksiCreateRequest struct {
 	MethodBayar  string                   `json:"method_bayar" validate:"required"`
-	AlamatKirim  uint                     `json:"alamat_kirim" validate:"required"`
+	AlamatKirim  uint                     `json:"alamat_kirim"` // Wajib kecuali mode dropship
+	Dropship     *DropshipRequest         `json:"dropship"`     // Diisi untuk pesanan dropship oleh reseller
 	DetailTrx    []DetailTransaksiRequest `json:"detail_trx" validate:"required,min=1"`
-}
\ No newline at end of file
+	Pengiriman   []PengirimanRequest      `json:"pengiriman" validate:"required,min=1"` // Satu opsi untuk setiap toko
+	KodeVoucher  []string                 `json:"kode_voucher"` // Maksimal satu voucher platform dan satu per toko
+}
+
+// Struct untuk parsing JSON body saat POST /trx/quote
+type OngkirQuoteRequest struct {
+	AlamatKirim uint                     `json:"alamat_kirim"` // Wajib kecuali mode dropship
+	Dropship    *DropshipRequest         `json:"dropship"`
+	DetailTrx   []DetailTransaksiRequest `json:"detail_trx" validate:"required,min=1"`
+}
+
+// Struct untuk parsing JSON body saat PUT /trx/:id/status dan /toko/my/orders/:id/status
+type TransaksiStatusUpdateRequest struct {
+	Status string `json:"status" validate:"required"`
+	NoResi string `json:"no_resi"` // Wajib saat status menjadi 'shipped'
+}
+
+// Struct untuk parsing JSON body saat POST /trx/:id/cancel
+type TransaksiCancelRequest struct {
+	Alasan string `json:"alasan" validate:"required"`
+}
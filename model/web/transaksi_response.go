@@ -1,23 +1,118 @@
 package web
 
+import "time"
+
 type DetailTransaksiResponse struct {
-	Produk     ProdukResponse `json:"product"` 
-	Toko       TokoResponse   `json:"toko"`    
-	Kuantitas  uint           `json:"kuantitas"`
-	HargaTotal uint           `json:"harga_total"`
+	ID          uint                   `json:"id"`
+	TransaksiID uint                   `json:"transaksi_id"` // Sub-transaksi pemilik baris, dipakai saat mengajukan retur
+	Produk      ProdukResponse         `json:"product"`
+	Varian      *VarianRingkasResponse `json:"varian,omitempty"` // Varian yang dibeli, dari snapshot saat checkout
+	Toko        TokoResponse           `json:"toko"`
+	Kuantitas   uint                   `json:"kuantitas"`
+	HargaSatuan uint                   `json:"harga_satuan"`
+	HargaTotal  uint                   `json:"harga_total"`
+	TierHarga   string                 `json:"tier_harga"`           // konsumen atau reseller
+	HargaJual   uint                   `json:"harga_jual,omitempty"` // Hanya pada pesanan dropship
+	Margin      uint                   `json:"margin,omitempty"`
+	Diskon      uint                   `json:"diskon,omitempty"` // Bagian potongan voucher pada baris ini
+}
+
+// Snapshot produk pada saat checkout untuk satu item transaksi
+type ProdukSnapshotResponse struct {
+	ID                uint                   `json:"id"`
+	DetailTransaksiID uint                   `json:"detail_transaksi_id"`
+	TransaksiID       uint                   `json:"transaksi_id"`
+	ProductID         uint                   `json:"product_id"` // Produk asli, bisa sudah diubah atau dihapus
+	NamaProduk        string                 `json:"nama_produk"`
+	Slug              string                 `json:"slug"`
+	HargaReseler      *uint                  `json:"harga_reseler,omitempty"` // Hanya pada pembelian reseller
+	HargaKonsumen     uint                   `json:"harga_konsumen"`
+	Berat             uint                   `json:"berat"`
+	Deskripsi         string                 `json:"deskripsi"`
+	Toko              TokoResponse           `json:"toko"`
+	Category          KategoriResponse       `json:"category"`
+	Varian            *VarianRingkasResponse `json:"varian,omitempty"`
+	CreatedAt         time.Time              `json:"created_at"` // Waktu checkout
+}
+
+// Baris checkout yang harga atau stoknya berubah sejak ditampilkan ke pembeli
+type PerubahanCheckoutResponse struct {
+	ProductID    uint   `json:"product_id"`
+	VarianID     uint   `json:"varian_id,omitempty"`
+	NamaProduk   string `json:"nama_produk"` // Diikuti nama varian bila ada
+	Kuantitas    uint   `json:"kuantitas"`
+	HargaLama    uint   `json:"harga_lama"` // Harga per unit yang dikirim klien
+	HargaBaru    uint   `json:"harga_baru"` // Harga per unit saat ini
+	StokTersisa  uint   `json:"stok_tersisa"`
+	HargaBerubah bool   `json:"harga_berubah"`
+	StokCukup    bool   `json:"stok_cukup"`
+}
+
+type PembayaranResponse struct {
+	Provider  string     `json:"provider"`
+	Method    string     `json:"method"`
+	Reference string     `json:"reference,omitempty"`
+	Instruksi string     `json:"instruksi,omitempty"`
+	Jumlah    uint       `json:"jumlah"`
+	Status    string     `json:"status"`
+	PaidAt    *time.Time `json:"paid_at,omitempty"`
 }
 
 type TransaksiResponse struct {
-	ID            uint                      `json:"id"`
-	HargaTotal    uint                      `json:"harga_total"`
-	KodeInvoice   string                    `json:"kode_invoice"`
-	MethodBayar   string                    `json:"method_bayar"`
-	AlamatKirim   AlamatResponse            `json:"alamat_kirim"` 
-	DetailTrx     []DetailTransaksiResponse `json:"detail_trx"`
+	ID            uint                       `json:"id"`
+	ParentID      *uint                      `json:"parent_id,omitempty"`
+	TokoID        uint                       `json:"toko_id,omitempty"`
+	Subtotal      uint                       `json:"subtotal"`
+	OngkosKirim   uint                       `json:"ongkos_kirim"`
+	Diskon        uint                       `json:"diskon"`
+	HargaTotal    uint                       `json:"harga_total"`
+	KodeInvoice   string                     `json:"kode_invoice"`
+	MethodBayar   string                     `json:"method_bayar"`
+	Status        string                     `json:"status"`
+	NoResi        string                     `json:"no_resi,omitempty"`
+	Kurir         string                     `json:"kurir,omitempty"` // Opsi pengiriman, hanya pada sub-pesanan
+	LayananKirim  string                     `json:"layanan_kirim,omitempty"`
+	EstimasiKirim string                     `json:"estimasi_kirim,omitempty"`
+	BeratTotal    uint                       `json:"berat_total,omitempty"` // Dalam gram
+	CancelledBy   uint                       `json:"cancelled_by,omitempty"`
+	CancelReason  string                     `json:"cancel_reason,omitempty"`
+	Dropship      bool                       `json:"dropship"`
+	AlamatKirim   AlamatResponse             `json:"alamat_kirim"`
+	DetailTrx     []DetailTransaksiResponse  `json:"detail_trx"`
+	SubOrders     []TransaksiResponse        `json:"sub_orders,omitempty"` // Sub-transaksi per toko
+	Pembayaran    *PembayaranResponse        `json:"pembayaran,omitempty"`
+	Voucher       []PemakaianVoucherResponse `json:"voucher,omitempty"` // Hanya pada transaksi induk
+	PaidAt        *time.Time                 `json:"paid_at,omitempty"`
+	ProcessedAt   *time.Time                 `json:"processed_at,omitempty"`
+	ShippedAt     *time.Time                 `json:"shipped_at,omitempty"`
+	DeliveredAt   *time.Time                 `json:"delivered_at,omitempty"`
+	CompletedAt   *time.Time                 `json:"completed_at,omitempty"`
+	CancelledAt   *time.Time                 `json:"cancelled_at,omitempty"`
+	ExpiredAt     *time.Time                 `json:"expired_at,omitempty"`
+	CreatedAt     time.Time                  `json:"created_at"`
+}
+
+type OpsiPengirimanResponse struct {
+	Kode     string `json:"kode"`
+	Kurir    string `json:"kurir"`
+	Layanan  string `json:"layanan"`
+	Biaya    uint   `json:"biaya"`
+	Estimasi string `json:"estimasi"`
+}
+
+// Struct untuk respons POST /trx/quote, satu per toko di keranjang
+type OngkirTokoResponse struct {
+	TokoID     uint                     `json:"toko_id"`
+	NamaToko   string                   `json:"nama_toko"`
+	BeratTotal uint                     `json:"berat_total"` // Dalam gram
+	Opsi       []OpsiPengirimanResponse `json:"opsi"`
 }
 
 type PaginatedTransaksiResponse struct {
-	Page  int                 `json:"page"`
-	Limit int                 `json:"limit"`
-	Data  []TransaksiResponse `json:"data"`
+	Page       int                 `json:"page"`
+	Limit      int                 `json:"limit"`
+	TotalItems int64               `json:"total_items"`
+	TotalPages int                 `json:"total_pages"`
+	NextCursor string              `json:"next_cursor,omitempty"` // Hanya pada mode cursor
+	Data       []TransaksiResponse `json:"data"`
 }
\ No newline at end of file
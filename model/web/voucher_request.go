@@ -0,0 +1,32 @@
+package web
+
+import "time"
+
+// Struct untuk parsing JSON body saat POST /admin/voucher dan /toko/my/voucher
+type VoucherCreateRequest struct {
+	Kode          string    `json:"kode" validate:"required"`
+	Nama          string    `json:"nama" validate:"required"`
+	Tipe          string    `json:"tipe" validate:"required"` // persen atau nominal
+	Nilai         uint      `json:"nilai" validate:"required,min=1"`
+	MaksDiskon    uint      `json:"maks_diskon"` // Hanya untuk tipe persen, 0 = tanpa batas
+	MinBelanja    uint      `json:"min_belanja"`
+	KuotaTotal    uint      `json:"kuota_total"`    // 0 = tanpa batas
+	KuotaPerUser  uint      `json:"kuota_per_user"` // 0 = tanpa batas
+	BerlakuMulai  time.Time `json:"berlaku_mulai" validate:"required"`
+	BerlakuSampai time.Time `json:"berlaku_sampai" validate:"required"`
+	KategoriIDs   []uint    `json:"kategori_ids"` // Kosong = semua kategori
+}
+
+// Struct untuk parsing JSON body saat PUT voucher. Field kosong tidak diubah.
+type VoucherUpdateRequest struct {
+	Nama          string     `json:"nama"`
+	Nilai         uint       `json:"nilai"`
+	MaksDiskon    *uint      `json:"maks_diskon"`
+	MinBelanja    *uint      `json:"min_belanja"`
+	KuotaTotal    *uint      `json:"kuota_total"`
+	KuotaPerUser  *uint      `json:"kuota_per_user"`
+	BerlakuMulai  *time.Time `json:"berlaku_mulai"`
+	BerlakuSampai *time.Time `json:"berlaku_sampai"`
+	Aktif         *bool      `json:"aktif"`
+	KategoriIDs   *[]uint    `json:"kategori_ids"` // Array kosong menghapus batasan kategori
+}
@@ -0,0 +1,51 @@
+package web
+
+import "time"
+
+type VoucherResponse struct {
+	ID            uint               `json:"id"`
+	Kode          string             `json:"kode"`
+	Nama          string             `json:"nama"`
+	TokoID        uint               `json:"toko_id"` // 0 = voucher platform
+	Tipe          string             `json:"tipe"`
+	Nilai         uint               `json:"nilai"`
+	MaksDiskon    uint               `json:"maks_diskon"`
+	MinBelanja    uint               `json:"min_belanja"`
+	KuotaTotal    uint               `json:"kuota_total"`
+	KuotaPerUser  uint               `json:"kuota_per_user"`
+	Terpakai      uint               `json:"terpakai"`
+	TotalDiskon   *uint64            `json:"total_diskon,omitempty"` // Hanya pada detail voucher
+	BerlakuMulai  time.Time          `json:"berlaku_mulai"`
+	BerlakuSampai time.Time          `json:"berlaku_sampai"`
+	Aktif         bool               `json:"aktif"`
+	Kategori      []KategoriResponse `json:"kategori"`
+	CreatedAt     time.Time          `json:"created_at"`
+}
+
+type PaginatedVoucherResponse struct {
+	Page  int               `json:"page"`
+	Limit int               `json:"limit"`
+	Data  []VoucherResponse `json:"data"`
+}
+
+// Potongan voucher pada sebuah transaksi
+type PemakaianVoucherResponse struct {
+	ID          uint      `json:"id"`
+	VoucherID   uint      `json:"voucher_id"`
+	Kode        string    `json:"kode"`
+	TokoID      uint      `json:"toko_id"`
+	Jumlah      uint      `json:"jumlah"`
+	UserID      uint      `json:"user_id,omitempty"`
+	TransaksiID uint      `json:"transaksi_id,omitempty"`
+	KodeInvoice string    `json:"kode_invoice,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Struct untuk respons riwayat pemakaian voucher dengan pagination
+type PaginatedPemakaianVoucherResponse struct {
+	Page       int                        `json:"page"`
+	Limit      int                        `json:"limit"`
+	TotalItems int64                      `json:"total_items"`
+	TotalPages int                        `json:"total_pages"`
+	Data       []PemakaianVoucherResponse `json:"data"`
+}
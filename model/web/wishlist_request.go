@@ -0,0 +1,6 @@
+package web
+
+// Struct untuk parsing JSON body saat POST /user/wishlist
+type WishlistAddRequest struct {
+	ProductID uint `json:"product_id" validate:"required"`
+}
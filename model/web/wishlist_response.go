@@ -0,0 +1,21 @@
+package web
+
+import "time"
+
+type WishlistResponse struct {
+	ID                uint           `json:"id"`
+	Produk            ProdukResponse `json:"product"`      // Stok dan harga terkini
+	HargaSatuan       uint           `json:"harga_satuan"` // Harga per unit saat ini sesuai tier user
+	HargaSaatDisimpan uint           `json:"harga_saat_disimpan"`
+	HargaTurun        bool           `json:"harga_turun"`
+	StokHabis         bool           `json:"stok_habis"`
+	CreatedAt         time.Time      `json:"created_at"`
+}
+
+type PaginatedWishlistResponse struct {
+	Page       int                `json:"page"`
+	Limit      int                `json:"limit"`
+	TotalItems int64              `json:"total_items"`
+	TotalPages int                `json:"total_pages"`
+	Data       []WishlistResponse `json:"data"`
+}
@@ -0,0 +1,31 @@
+package payment
+
+import (
+	"errors"
+)
+
+// CODProvider menangani pembayaran di tempat (Cash On Delivery).
+// Tidak ada tagihan ke pihak ketiga dan tidak ada callback.
+type CODProvider struct{}
+
+func NewCODProvider() *CODProvider {
+	return &CODProvider{}
+}
+
+func (p *CODProvider) Name() string {
+	return "cod"
+}
+
+func (p *CODProvider) Methods() []string {
+	return []string{MethodCOD}
+}
+
+func (p *CODProvider) CreatePayment(request ChargeRequest) (ChargeResult, error) {
+	return ChargeResult{
+		Instruksi: "Bayar tunai kepada kurir saat pesanan diterima",
+	}, nil
+}
+
+func (p *CODProvider) ParseCallback(body []byte, signature string) (CallbackResult, error) {
+	return CallbackResult{}, errors.New("Provider cod tidak menerima callback")
+}
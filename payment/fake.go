@@ -0,0 +1,77 @@
+package payment
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// FakeProvider adalah provider bawaan untuk development dan pengujian.
+// Tagihan langsung dibuat tanpa memanggil pihak ketiga, dan callback
+// ditandatangani dengan HMAC-SHA256 menggunakan secret yang sama.
+type FakeProvider struct {
+	secret []byte
+}
+
+func NewFakeProvider(secret string) *FakeProvider {
+	return &FakeProvider{secret: []byte(secret)}
+}
+
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *FakeProvider) Methods() []string {
+	return []string{MethodBankTransfer, MethodVirtualAccount, MethodEWallet}
+}
+
+func (p *FakeProvider) CreatePayment(request ChargeRequest) (ChargeResult, error) {
+	return ChargeResult{
+		Reference: "FAKE-" + request.KodeInvoice,
+		Instruksi: fmt.Sprintf("Bayar Rp%d melalui %s dengan referensi FAKE-%s", request.Jumlah, request.Method, request.KodeInvoice),
+	}, nil
+}
+
+func (p *FakeProvider) ParseCallback(body []byte, signature string) (CallbackResult, error) {
+	var result CallbackResult
+	if len(p.secret) == 0 {
+		return result, errors.New("Provider fake belum dikonfigurasi")
+	}
+
+	expected, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, p.mac(body)) {
+		return result, ErrInvalidSignature
+	}
+
+	if err := json.Unmarshal(body, &result); err != nil {
+		return result, errors.New("Format callback tidak valid")
+	}
+	if result.Status != StatusPaid && result.Status != StatusFailed {
+		return result, fmt.Errorf("Status callback tidak valid: %s", result.Status)
+	}
+	return result, nil
+}
+
+// Sign menghasilkan signature untuk body callback
+func (p *FakeProvider) Sign(body []byte) string {
+	return hex.EncodeToString(p.mac(body))
+}
+
+// BuildCallback membuat body dan signature callback yang valid,
+// sehingga pengujian dapat menandai transaksi sebagai lunas atau gagal.
+func (p *FakeProvider) BuildCallback(result CallbackResult) ([]byte, string, error) {
+	body, err := json.Marshal(result)
+	if err != nil {
+		return nil, "", err
+	}
+	return body, p.Sign(body), nil
+}
+
+func (p *FakeProvider) mac(body []byte) []byte {
+	h := hmac.New(sha256.New, p.secret)
+	h.Write(body)
+	return h.Sum(nil)
+}
@@ -0,0 +1,112 @@
+package payment
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Metode pembayaran yang dikenal
+const (
+	MethodBankTransfer   = "bank_transfer"
+	MethodVirtualAccount = "virtual_account"
+	MethodEWallet        = "e_wallet"
+	MethodCOD            = "cod"
+)
+
+// Status hasil pembayaran yang dikirim provider melalui callback
+const (
+	StatusPaid   = "paid"
+	StatusFailed = "failed"
+)
+
+// ErrInvalidSignature dikembalikan saat signature callback tidak cocok
+var ErrInvalidSignature = errors.New("Signature callback tidak valid")
+
+// ChargeRequest adalah data tagihan yang dikirim ke provider saat checkout
+type ChargeRequest struct {
+	KodeInvoice string // Unik per checkout, dipakai sebagai kunci idempotensi di provider
+	Method      string
+	Jumlah      uint
+}
+
+// ChargeResult adalah tagihan yang dibuat provider
+type ChargeResult struct {
+	Reference string // ID tagihan di sisi provider
+	Instruksi string // Instruksi pembayaran untuk pembeli (no. VA, rekening, dsb.)
+}
+
+// CallbackResult adalah isi webhook yang sudah diverifikasi
+type CallbackResult struct {
+	KodeInvoice string `json:"kode_invoice"`
+	Reference   string `json:"reference"`
+	Status      string `json:"status"` // StatusPaid atau StatusFailed
+	Jumlah      uint   `json:"jumlah"`
+}
+
+// PaymentProvider adalah kontrak untuk setiap penyedia pembayaran
+type PaymentProvider interface {
+	// Name adalah nama provider, dipakai pada URL callback
+	Name() string
+	// Methods adalah daftar metode pembayaran yang ditangani provider
+	Methods() []string
+	// CreatePayment membuat tagihan untuk sebuah transaksi. Permintaan ulang dengan
+	// KodeInvoice yang sama harus mengembalikan tagihan yang sama, bukan tagihan baru.
+	CreatePayment(request ChargeRequest) (ChargeResult, error)
+	// ParseCallback memverifikasi signature dan membaca isi webhook
+	ParseCallback(body []byte, signature string) (CallbackResult, error)
+}
+
+// Registry memetakan nama provider dan metode pembayaran ke provider-nya
+type Registry struct {
+	providers map[string]PaymentProvider
+	methods   map[string]PaymentProvider
+}
+
+func NewRegistry(providers ...PaymentProvider) *Registry {
+	registry := &Registry{
+		providers: map[string]PaymentProvider{},
+		methods:   map[string]PaymentProvider{},
+	}
+	for _, provider := range providers {
+		registry.Register(provider)
+	}
+	return registry
+}
+
+// Register mendaftarkan provider beserta metode-metodenya.
+// Metode yang sudah terdaftar akan diambil alih oleh provider terakhir.
+func (r *Registry) Register(provider PaymentProvider) {
+	r.providers[provider.Name()] = provider
+	for _, method := range provider.Methods() {
+		r.methods[method] = provider
+	}
+}
+
+// Provider mencari provider berdasarkan nama
+func (r *Registry) Provider(name string) (PaymentProvider, error) {
+	provider, ok := r.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("Provider pembayaran %s tidak ditemukan", name)
+	}
+	return provider, nil
+}
+
+// ProviderForMethod mencari provider yang menangani sebuah metode pembayaran
+func (r *Registry) ProviderForMethod(method string) (PaymentProvider, error) {
+	provider, ok := r.methods[method]
+	if !ok {
+		return nil, fmt.Errorf("Metode pembayaran tidak dikenal: %s", method)
+	}
+	return provider, nil
+}
+
+// Methods mengembalikan semua metode pembayaran yang terdaftar
+func (r *Registry) Methods() []string {
+	var methods []string
+	for method := range r.methods {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
@@ -12,6 +12,7 @@ type AlamatRepository interface {
 	FindByID(alamatID uint) (model.Alamat, error)
 	Update(alamat model.Alamat) (model.Alamat, error)
 	Delete(alamatID uint) error
+	CountOpenOrders(alamatID uint) (int64, error)
 }
 
 type alamatRepository struct {
@@ -62,4 +63,14 @@ func (r *alamatRepository) Delete(alamatID uint) error {
 		return err
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// CountOpenOrders menghitung pesanan yang belum selesai dan dikirim ke alamat ini
+func (r *alamatRepository) CountOpenOrders(alamatID uint) (int64, error) {
+	var total int64
+	err := r.db.Model(&model.Transaksi{}).
+		Where("alamat_kirim_id = ? AND parent_id IS NULL", alamatID).
+		Where("status NOT IN ?", []string{model.StatusCompleted, model.StatusCancelled, model.StatusExpired}).
+		Count(&total).Error
+	return total, err
+}
@@ -65,9 +65,11 @@ func (r *authRepository) Register(user model.User) (model.User, error) {
 		namaToko := fmt.Sprintf("toko-%s", strings.ToLower(strings.ReplaceAll(user.Nama, " ", "-")))
 
 		toko := model.Toko{
-			UserID:   user.ID,
-			NamaToko: namaToko,
-			UrlFoto:  "", // Default foto kosong
+			UserID:     user.ID,
+			NamaToko:   namaToko,
+			UrlFoto:    "", // Default foto kosong
+			IDProvinsi: user.IDProvinsi, // Kota asal pengiriman mengikuti domisili pemilik
+			IDKota:     user.IDKota,
 		}
 
 		if err := tx.Create(&toko).Error; err != nil {
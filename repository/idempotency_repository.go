@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"github.com/Debjth19/go-evermos/model"
+
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IdempotencyRepository interface {
+	Create(record *model.IdempotencyRecord) (bool, error)
+	TakeOverExpired(record *model.IdempotencyRecord, now time.Time) (bool, error)
+	FindByUserAndKey(userID uint, key string) (model.IdempotencyRecord, error)
+	Complete(recordID uint, leaseToken string, responseCode int, responseBody string, expiresAt time.Time) error
+	Delete(recordID uint, leaseToken string) error
+	DeleteExpired(now time.Time) (int64, error)
+}
+
+type idempotencyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyRepository(db *gorm.DB) IdempotencyRepository {
+	return &idempotencyRepository{db}
+}
+
+// Create menyimpan record baru. Mengembalikan false jika (user, key) sudah ada,
+// sehingga hanya satu request yang dapat mengklaim sebuah key.
+func (r *idempotencyRepository) Create(record *model.IdempotencyRecord) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// TakeOverExpired mengklaim ulang record (user, key) yang sudah kedaluwarsa: lease processing yang
+// ditinggalkan request yang gagal di tengah jalan, atau respons completed yang melewati masa simpannya.
+// Klaim dilakukan dengan satu UPDATE bersyarat sehingga hanya satu request yang berhasil.
+func (r *idempotencyRepository) TakeOverExpired(record *model.IdempotencyRecord, now time.Time) (bool, error) {
+	result := r.db.Model(&model.IdempotencyRecord{}).
+		Where("user_id = ? AND idempotency_key = ? AND expires_at <= ?", record.UserID, record.IdempotencyKey, now).
+		Updates(map[string]interface{}{
+			"request_hash":  record.RequestHash,
+			"status":        record.Status,
+			"response_code": 0,
+			"response_body": "",
+			"lease_token":   record.LeaseToken,
+			"expires_at":    record.ExpiresAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	claimed, err := r.FindByUserAndKey(record.UserID, record.IdempotencyKey)
+	if err != nil {
+		return false, err
+	}
+	record.ID = claimed.ID
+	return true, nil
+}
+
+// FindByUserAndKey mengambil record berdasarkan user dan key
+func (r *idempotencyRepository) FindByUserAndKey(userID uint, key string) (model.IdempotencyRecord, error) {
+	var record model.IdempotencyRecord
+	err := r.db.Where("user_id = ? AND idempotency_key = ?", userID, key).First(&record).Error
+	return record, err
+}
+
+// Complete menyimpan respons yang akan diputar ulang untuk request berikutnya hingga expiresAt.
+// Tidak berpengaruh bila lease sudah diambil alih request lain.
+func (r *idempotencyRepository) Complete(recordID uint, leaseToken string, responseCode int, responseBody string, expiresAt time.Time) error {
+	return r.db.Model(&model.IdempotencyRecord{}).Where("id = ? AND lease_token = ?", recordID, leaseToken).Updates(map[string]interface{}{
+		"status":        model.IdempotencyCompleted,
+		"response_code": responseCode,
+		"response_body": responseBody,
+		"expires_at":    expiresAt,
+	}).Error
+}
+
+// Delete melepas key agar request dapat dicoba ulang, selama lease masih dipegang leaseToken
+func (r *idempotencyRepository) Delete(recordID uint, leaseToken string) error {
+	return r.db.Where("id = ? AND lease_token = ?", recordID, leaseToken).Delete(&model.IdempotencyRecord{}).Error
+}
+
+// DeleteExpired menghapus record yang sudah kedaluwarsa dan mengembalikan jumlahnya
+func (r *idempotencyRepository) DeleteExpired(now time.Time) (int64, error) {
+	result := r.db.Where("expires_at <= ?", now).Delete(&model.IdempotencyRecord{})
+	return result.RowsAffected, result.Error
+}
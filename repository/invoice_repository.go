@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"github.com/Debjth19/go-evermos/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InvoiceRepository interface {
+	NextSequence(scope string) (uint64, error)
+}
+
+type invoiceRepository struct {
+	db *gorm.DB
+}
+
+func NewInvoiceRepository(db *gorm.DB) InvoiceRepository {
+	return &invoiceRepository{db}
+}
+
+// NextSequence mengambil nomor urut berikutnya untuk sebuah scope.
+// Berjalan di DB transaction tersendiri agar baris penghitung tidak terkunci
+// selama checkout berlangsung; nomor dari checkout yang gagal tidak dipakai ulang.
+func (r *invoiceRepository) NextSequence(scope string) (uint64, error) {
+	var sequence model.InvoiceSequence
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Buat baris penghitung jika belum ada, atau naikkan nilainya.
+		// Baris tetap terkunci sampai transaction selesai.
+		err := tx.Clauses(clause.OnConflict{
+			DoUpdates: clause.Assignments(map[string]interface{}{"nilai": gorm.Expr("nilai + 1")}),
+		}).Create(&model.InvoiceSequence{Scope: scope, Nilai: 1}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("scope = ?", scope).First(&sequence).Error
+	})
+	return sequence.Nilai, err
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"github.com/Debjth19/go-evermos/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type KeranjangRepository interface {
+	FindByUserID(userID uint) ([]model.KeranjangItem, error)
+	FindByID(itemID uint) (model.KeranjangItem, error)
+	FindByUserAndProduk(userID uint, produkID uint, varianID uint) (model.KeranjangItem, error)
+	FindByIDs(userID uint, itemIDs []uint) ([]model.KeranjangItem, error)
+	Create(item *model.KeranjangItem) error
+	Update(item *model.KeranjangItem) error
+	Delete(itemID uint) error
+	DeleteByIDs(tx *gorm.DB, userID uint, itemIDs []uint) (int64, error)
+}
+
+type keranjangRepository struct {
+	db *gorm.DB
+}
+
+func NewKeranjangRepository(db *gorm.DB) KeranjangRepository {
+	return &keranjangRepository{db}
+}
+
+// preloads adalah helper agar data produk setiap item ikut terambil
+func (r *keranjangRepository) preloads() *gorm.DB {
+	return r.db.
+		Preload("Produk").
+		Preload("Produk.Toko").
+		Preload("Produk.Category").
+		Preload("Produk.FotoProduk").
+		Preload("Produk.Varian.NilaiOpsi")
+}
+
+// FindByUserID mengambil seluruh isi keranjang user, urut sesuai waktu ditambahkan
+func (r *keranjangRepository) FindByUserID(userID uint) ([]model.KeranjangItem, error) {
+	var items []model.KeranjangItem
+	err := r.preloads().Where("user_id = ?", userID).Order("id asc").Find(&items).Error
+	return items, err
+}
+
+// FindByID mengambil satu item keranjang
+func (r *keranjangRepository) FindByID(itemID uint) (model.KeranjangItem, error) {
+	var item model.KeranjangItem
+	err := r.db.First(&item, itemID).Error
+	return item, err
+}
+
+// FindByUserAndProduk mengambil item keranjang user untuk sebuah produk dan varian
+func (r *keranjangRepository) FindByUserAndProduk(userID uint, produkID uint, varianID uint) (model.KeranjangItem, error) {
+	var item model.KeranjangItem
+	err := r.db.Where("user_id = ? AND product_id = ? AND varian_id = ?", userID, produkID, varianID).First(&item).Error
+	return item, err
+}
+
+// FindByIDs mengambil item-item keranjang milik user berdasarkan ID-nya
+func (r *keranjangRepository) FindByIDs(userID uint, itemIDs []uint) ([]model.KeranjangItem, error) {
+	var items []model.KeranjangItem
+	err := r.db.Where("user_id = ? AND id IN ?", userID, itemIDs).Order("id asc").Find(&items).Error
+	return items, err
+}
+
+// Create menyimpan item baru ke keranjang
+func (r *keranjangRepository) Create(item *model.KeranjangItem) error {
+	return r.db.Omit(clause.Associations).Create(item).Error
+}
+
+// Update menyimpan perubahan kuantitas dan harga item (tanpa relasi)
+func (r *keranjangRepository) Update(item *model.KeranjangItem) error {
+	return r.db.Omit(clause.Associations).Save(item).Error
+}
+
+// Delete menghapus satu item dari keranjang
+func (r *keranjangRepository) Delete(itemID uint) error {
+	return r.db.Delete(&model.KeranjangItem{}, itemID).Error
+}
+
+// DeleteByIDs menghapus item-item keranjang milik user di dalam DB transaction
+// dan mengembalikan jumlah baris yang benar-benar terhapus
+func (r *keranjangRepository) DeleteByIDs(tx *gorm.DB, userID uint, itemIDs []uint) (int64, error) {
+	result := tx.Where("user_id = ? AND id IN ?", userID, itemIDs).Delete(&model.KeranjangItem{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"github.com/Debjth19/go-evermos/helpers"
+	"github.com/Debjth19/go-evermos/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LedgerRepository interface {
+	CreateJurnal(tx *gorm.DB, jurnal *model.Jurnal) error
+	FindJurnalByReferensi(tx *gorm.DB, referensi string) (model.Jurnal, error)
+	SaldoToko(tx *gorm.DB, tokoID uint) (int64, error)
+	FindEntriesByToko(tokoID uint, pagination helpers.Pagination) ([]model.LedgerEntry, int64, error)
+	LockToko(tx *gorm.DB, tokoID uint) error
+
+	CreatePenarikan(tx *gorm.DB, penarikan *model.Penarikan) error
+	FindPenarikanByIDForUpdate(tx *gorm.DB, penarikanID uint) (model.Penarikan, error)
+	FindPenarikanByID(penarikanID uint) (model.Penarikan, error)
+	FindPenarikanByToko(tokoID uint, pagination helpers.Pagination) ([]model.Penarikan, error)
+	FindAllPenarikan(pagination helpers.Pagination, status string) ([]model.Penarikan, error)
+	UpdatePenarikan(tx *gorm.DB, penarikan *model.Penarikan) error
+	SumPenarikanPending(tx *gorm.DB, tokoID uint) (int64, error)
+}
+
+type ledgerRepository struct {
+	db *gorm.DB
+}
+
+func NewLedgerRepository(db *gorm.DB) LedgerRepository {
+	return &ledgerRepository{db}
+}
+
+// CreateJurnal menyimpan jurnal beserta seluruh entry-nya
+func (r *ledgerRepository) CreateJurnal(tx *gorm.DB, jurnal *model.Jurnal) error {
+	return tx.Create(jurnal).Error
+}
+
+// FindJurnalByReferensi mengambil jurnal berdasarkan referensi kejadiannya
+func (r *ledgerRepository) FindJurnalByReferensi(tx *gorm.DB, referensi string) (model.Jurnal, error) {
+	var jurnal model.Jurnal
+	err := tx.Where("referensi = ?", referensi).First(&jurnal).Error
+	return jurnal, err
+}
+
+// SaldoToko menghitung saldo toko dari seluruh entry ledger-nya (kredit - debit)
+func (r *ledgerRepository) SaldoToko(tx *gorm.DB, tokoID uint) (int64, error) {
+	var saldo int64
+	err := tx.Model(&model.LedgerEntry{}).
+		Select("COALESCE(SUM(CAST(kredit AS SIGNED) - CAST(debit AS SIGNED)), 0)").
+		Where("akun = ? AND toko_id = ?", model.AkunSaldoToko, tokoID).
+		Scan(&saldo).Error
+	return saldo, err
+}
+
+// FindEntriesByToko mengambil riwayat mutasi saldo toko, terbaru lebih dulu
+func (r *ledgerRepository) FindEntriesByToko(tokoID uint, pagination helpers.Pagination) ([]model.LedgerEntry, int64, error) {
+	var entries []model.LedgerEntry
+	var total int64
+
+	where := func(query *gorm.DB) *gorm.DB {
+		return query.Where("akun = ? AND toko_id = ?", model.AkunSaldoToko, tokoID)
+	}
+	if err := where(r.db.Model(&model.LedgerEntry{})).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Terapkan pagination
+	offset := (pagination.Page - 1) * pagination.Limit
+	err := where(r.db.Preload("Jurnal")).
+		Order("id desc").
+		Limit(pagination.Limit).Offset(offset).
+		Find(&entries).Error
+	return entries, total, err
+}
+
+// LockToko mengunci baris toko agar pengecekan saldo dan penarikan tidak berjalan bersamaan
+func (r *ledgerRepository) LockToko(tx *gorm.DB, tokoID uint) error {
+	var toko model.Toko
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").First(&toko, tokoID).Error
+}
+
+// CreatePenarikan menyimpan permintaan penarikan baru
+func (r *ledgerRepository) CreatePenarikan(tx *gorm.DB, penarikan *model.Penarikan) error {
+	return tx.Create(penarikan).Error
+}
+
+// FindPenarikanByIDForUpdate mengambil penarikan dan mengunci barisnya
+func (r *ledgerRepository) FindPenarikanByIDForUpdate(tx *gorm.DB, penarikanID uint) (model.Penarikan, error) {
+	var penarikan model.Penarikan
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&penarikan, penarikanID).Error
+	return penarikan, err
+}
+
+// FindPenarikanByID mengambil penarikan beserta tokonya
+func (r *ledgerRepository) FindPenarikanByID(penarikanID uint) (model.Penarikan, error) {
+	var penarikan model.Penarikan
+	err := r.db.Preload("Toko").First(&penarikan, penarikanID).Error
+	return penarikan, err
+}
+
+// FindPenarikanByToko mengambil riwayat penarikan sebuah toko
+func (r *ledgerRepository) FindPenarikanByToko(tokoID uint, pagination helpers.Pagination) ([]model.Penarikan, error) {
+	var penarikans []model.Penarikan
+
+	offset := (pagination.Page - 1) * pagination.Limit
+	err := r.db.Preload("Toko").
+		Where("toko_id = ?", tokoID).
+		Order("created_at desc").
+		Limit(pagination.Limit).Offset(offset).
+		Find(&penarikans).Error
+	return penarikans, err
+}
+
+// FindAllPenarikan mengambil penarikan semua toko untuk admin, dengan filter status opsional
+func (r *ledgerRepository) FindAllPenarikan(pagination helpers.Pagination, status string) ([]model.Penarikan, error) {
+	var penarikans []model.Penarikan
+
+	query := r.db.Preload("Toko")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	// Terapkan pagination, penarikan paling lama tampil lebih dulu
+	offset := (pagination.Page - 1) * pagination.Limit
+	err := query.Order("created_at asc").
+		Limit(pagination.Limit).Offset(offset).
+		Find(&penarikans).Error
+	return penarikans, err
+}
+
+// UpdatePenarikan menyimpan perubahan pada penarikan tanpa menyentuh relasinya
+func (r *ledgerRepository) UpdatePenarikan(tx *gorm.DB, penarikan *model.Penarikan) error {
+	return tx.Omit(clause.Associations).Save(penarikan).Error
+}
+
+// SumPenarikanPending menjumlahkan penarikan toko yang masih menunggu keputusan
+func (r *ledgerRepository) SumPenarikanPending(tx *gorm.DB, tokoID uint) (int64, error) {
+	var total int64
+	err := tx.Model(&model.Penarikan{}).
+		Select("COALESCE(SUM(jumlah), 0)").
+		Where("toko_id = ? AND status = ?", tokoID, model.PenarikanPending).
+		Scan(&total).Error
+	return total, err
+}
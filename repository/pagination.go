@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"github.com/Debjth19/go-evermos/helpers"
+
+	"gorm.io/gorm"
+)
+
+// setelahCursor menyaring baris yang berada setelah posisi cursor pada urutan (kolom, kolomID).
+// naik menandakan urutan menaik; nilai adalah nilai kolom urutan pada item terakhir.
+func setelahCursor(query *gorm.DB, kolom string, kolomID string, naik bool, nilai interface{}, id uint) *gorm.DB {
+	op := "<"
+	if naik {
+		op = ">"
+	}
+	return query.Where("("+kolom+" "+op+" ? OR ("+kolom+" = ? AND "+kolomID+" "+op+" ?))", nilai, nilai, id)
+}
+
+// ambilHalaman menjalankan query yang sudah terurut untuk satu halaman.
+// Mode halaman memakai LIMIT/OFFSET. Mode cursor mengambil satu baris ekstra untuk
+// mengetahui adanya halaman berikutnya, lalu mengisi pagination.NextCursor dari item terakhir.
+func ambilHalaman[T any](query *gorm.DB, pagination *helpers.Pagination, cursorDari func(T) (helpers.Cursor, error)) ([]T, error) {
+	var rows []T
+	if !pagination.ModeCursor {
+		err := query.Limit(pagination.Limit).Offset(pagination.Offset()).Find(&rows).Error
+		return rows, err
+	}
+
+	if err := query.Limit(pagination.Limit + 1).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) > pagination.Limit {
+		rows = rows[:pagination.Limit]
+		cursor, err := cursorDari(rows[len(rows)-1])
+		if err != nil {
+			return nil, err
+		}
+		pagination.SetNextCursor(cursor)
+	}
+	return rows, nil
+}
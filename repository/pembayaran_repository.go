@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"github.com/Debjth19/go-evermos/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PembayaranRepository interface {
+	Create(tx *gorm.DB, pembayaran *model.Pembayaran) error
+	FindByTransaksiIDForUpdate(tx *gorm.DB, trxID uint) (model.Pembayaran, error)
+	Update(tx *gorm.DB, pembayaran *model.Pembayaran) error
+	CreateRefund(tx *gorm.DB, refund *model.Refund) error
+}
+
+type pembayaranRepository struct {
+	db *gorm.DB
+}
+
+func NewPembayaranRepository(db *gorm.DB) PembayaranRepository {
+	return &pembayaranRepository{db}
+}
+
+// Create menyimpan data pembayaran baru
+func (r *pembayaranRepository) Create(tx *gorm.DB, pembayaran *model.Pembayaran) error {
+	return tx.Create(pembayaran).Error
+}
+
+// FindByTransaksiIDForUpdate mengambil pembayaran sebuah transaksi dan mengunci barisnya
+func (r *pembayaranRepository) FindByTransaksiIDForUpdate(tx *gorm.DB, trxID uint) (model.Pembayaran, error) {
+	var pembayaran model.Pembayaran
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("transaksi_id = ?", trxID).First(&pembayaran).Error
+	return pembayaran, err
+}
+
+// Update menyimpan perubahan pada pembayaran
+func (r *pembayaranRepository) Update(tx *gorm.DB, pembayaran *model.Pembayaran) error {
+	return tx.Save(pembayaran).Error
+}
+
+// CreateRefund menyimpan catatan refund terhadap sebuah pembayaran
+func (r *pembayaranRepository) CreateRefund(tx *gorm.DB, refund *model.Refund) error {
+	return tx.Create(refund).Error
+}
@@ -4,10 +4,21 @@ import (
 	"github.com/Debjth19/go-evermos/helpers"
 	"github.com/Debjth19/go-evermos/model"
 
+	"strconv"
+
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
+// Urutan yang didukung daftar produk (query param 'sort')
+const (
+	UrutanTerbaru        = "terbaru" // Bawaan
+	UrutanHargaTerendah  = "harga_terendah"
+	UrutanHargaTertinggi = "harga_tertinggi"
+	UrutanTerlaris       = "terlaris"
+	UrutanNama           = "nama"
+)
+
 // Struct untuk filter
 type ProdukFilter struct {
 	NamaProduk string
@@ -15,11 +26,21 @@ type ProdukFilter struct {
 	TokoID     uint
 	MinHarga   uint
 	MaxHarga   uint
+	Urutan     string // Salah satu konstanta Urutan*, kosong = terbaru
+}
+
+// IsValidUrutan mengecek apakah nilai sort dikenal
+func IsValidUrutan(urutan string) bool {
+	switch urutan {
+	case "", UrutanTerbaru, UrutanHargaTerendah, UrutanHargaTertinggi, UrutanTerlaris, UrutanNama:
+		return true
+	}
+	return false
 }
 
 type ProdukRepository interface {
 	Create(produk model.Produk, fotoUrls []string) (model.Produk, error)
-	FindAll(pagination helpers.Pagination, filter ProdukFilter) ([]model.Produk, error)
+	FindAll(pagination *helpers.Pagination, filter ProdukFilter) ([]model.Produk, error)
 	FindByID(produkID uint) (model.Produk, error)
 	Update(produk model.Produk) (model.Produk, error)
 	Delete(produkID uint) error
@@ -28,6 +49,10 @@ type ProdukRepository interface {
 
 	FindByIDForUpdate(tx *gorm.DB, produkID uint) (model.Produk, error)
     UpdateStok(tx *gorm.DB, produkID uint, newStok uint) error
+	FindVarianForUpdate(tx *gorm.DB, produkID uint, varianID uint) (model.VarianProduk, error)
+	UpdateStokVarian(tx *gorm.DB, varianID uint, newStok uint) error
+	CountVarian(tx *gorm.DB, produkID uint) (int64, error)
+	SlugExists(slug string, exceptID uint) (bool, error)
 }
 
 type produkRepository struct {
@@ -68,41 +93,138 @@ func (r *produkRepository) Create(produk model.Produk, fotoUrls []string) (model
 	return produk, nil
 }
 
-// FindAll mengambil semua produk dengan pagination dan filter
-func (r *produkRepository) FindAll(pagination helpers.Pagination, filter ProdukFilter) ([]model.Produk, error) {
-	var produks []model.Produk
+// FindAll mengambil produk dengan filter, urutan, dan pagination (halaman atau cursor).
+// Total produk yang cocok dengan filter dan cursor halaman berikutnya diisi ke pagination.
+func (r *produkRepository) FindAll(pagination *helpers.Pagination, filter ProdukFilter) ([]model.Produk, error) {
+	cursor, err := pagination.DecodeCursor()
+	if err != nil {
+		return nil, err
+	}
 
-	// Siapkan query
-	query := r.db.Model(&model.Produk{}).
+	// Terapkan filter
+	where := func(query *gorm.DB) *gorm.DB {
+		if filter.NamaProduk != "" {
+			query = query.Where("nama_produk LIKE ?", polaMengandung(filter.NamaProduk))
+		}
+		if filter.CategoryID != 0 {
+			query = query.Where("category_id = ?", filter.CategoryID)
+		}
+		if filter.TokoID != 0 {
+			query = query.Where("toko_id = ?", filter.TokoID)
+		}
+		if filter.MinHarga != 0 {
+			query = query.Where("harga_konsumen >= ?", filter.MinHarga)
+		}
+		if filter.MaxHarga != 0 {
+			query = query.Where("harga_konsumen <= ?", filter.MaxHarga)
+		}
+		return query
+	}
+
+	// 1. Hitung total tanpa preload
+	var total int64
+	if err := where(r.db.Model(&model.Produk{})).Count(&total).Error; err != nil {
+		return nil, err
+	}
+	pagination.SetTotal(total)
+
+	// 2. Terapkan urutan, selalu diakhiri ID agar halaman tidak berulang atau terlewat
+	query := where(r.db.Model(&model.Produk{}).
 		Preload("Toko").
 		Preload("Category").
-		Preload("FotoProduk")
+		Preload("FotoProduk").
+		Preload("Opsi.Nilai").
+		Preload("Varian.NilaiOpsi"))
+	query = r.urutkan(query, filter.Urutan)
 
-	// Terapkan filter
-	if filter.NamaProduk != "" {
-		query = query.Where("nama_produk LIKE ?", "%"+filter.NamaProduk+"%")
+	// 3. Mode cursor: lanjutkan setelah produk terakhir halaman sebelumnya
+	kolom, naik := kolomUrutan(filter.Urutan)
+	if cursor != nil {
+		nilai, err := nilaiCursorProduk(*cursor, filter.Urutan)
+		if err != nil {
+			return nil, err
+		}
+		query = setelahCursor(query, kolom, "produks.id", naik, nilai, cursor.ID)
 	}
-	if filter.CategoryID != 0 {
-		query = query.Where("category_id = ?", filter.CategoryID)
+
+	return ambilHalaman(query, pagination, func(p model.Produk) (helpers.Cursor, error) {
+		return r.cursorProduk(p, filter.Urutan)
+	})
+}
+
+// kolomUrutan mengembalikan ekspresi kolom urutan utama dan arahnya untuk setiap pilihan sort
+func kolomUrutan(urutan string) (kolom string, naik bool) {
+	switch urutan {
+	case UrutanHargaTerendah:
+		return "produks.harga_konsumen", true
+	case UrutanHargaTertinggi:
+		return "produks.harga_konsumen", false
+	case UrutanNama:
+		return "produks.nama_produk", true
+	case UrutanTerlaris:
+		return "COALESCE(penjualan.terjual, 0)", false
+	default:
+		return "produks.created_at", false
 	}
-	if filter.TokoID != 0 {
-		query = query.Where("toko_id = ?", filter.TokoID)
+}
+
+// urutkan menerapkan ORDER BY sesuai pilihan sort dengan ID produk sebagai penentu akhir
+func (r *produkRepository) urutkan(query *gorm.DB, urutan string) *gorm.DB {
+	kolom, naik := kolomUrutan(urutan)
+	arah := " desc"
+	if naik {
+		arah = " asc"
 	}
-	if filter.MinHarga != 0 {
-		query = query.Where("harga_konsumen >= ?", filter.MinHarga)
+	if urutan == UrutanTerlaris {
+		query = query.Select("produks.*").
+			Joins("LEFT JOIN (?) AS penjualan ON penjualan.product_id = produks.id", r.terjual())
 	}
-	if filter.MaxHarga != 0 {
-		query = query.Where("harga_konsumen <= ?", filter.MaxHarga)
+	return query.Order(kolom + arah).Order("produks.id" + arah)
+}
+
+// terjual menghitung jumlah terjual per produk dari seluruh pesanan yang tidak dibatalkan atau kedaluwarsa
+func (r *produkRepository) terjual() *gorm.DB {
+	return r.db.Table("detail_transaksis").
+		Select("detail_transaksis.product_id, SUM(detail_transaksis.kuantitas) AS terjual").
+		Joins("JOIN transaksis ON transaksis.id = detail_transaksis.transaksi_id").
+		Where("transaksis.status NOT IN ?", []string{model.StatusCancelled, model.StatusExpired}).
+		Group("detail_transaksis.product_id")
+}
+
+// cursorProduk membuat cursor dari nilai kolom urutan produk terakhir pada halaman
+func (r *produkRepository) cursorProduk(p model.Produk, urutan string) (helpers.Cursor, error) {
+	cursor := helpers.Cursor{ID: p.ID}
+	switch urutan {
+	case UrutanHargaTerendah, UrutanHargaTertinggi:
+		cursor.Nilai = strconv.FormatUint(uint64(p.HargaKonsumen), 10)
+	case UrutanNama:
+		cursor.Nilai = p.NamaProduk
+	case UrutanTerlaris:
+		var terjual uint64
+		err := r.db.Table("(?) AS penjualan", r.terjual()).
+			Select("COALESCE(SUM(penjualan.terjual), 0)").
+			Where("penjualan.product_id = ?", p.ID).
+			Scan(&terjual).Error
+		if err != nil {
+			return cursor, err
+		}
+		cursor.Nilai = strconv.FormatUint(terjual, 10)
+	default:
+		return helpers.CursorWaktu(p.CreatedAt, p.ID), nil
 	}
+	return cursor, nil
+}
 
-	// Terapkan pagination 
-	offset := (pagination.Page - 1) * pagination.Limit
-	err := query.Limit(pagination.Limit).Offset(offset).Find(&produks).Error
-	if err != nil {
-		return produks, err
+// nilaiCursorProduk membaca nilai kolom urutan dari cursor sesuai tipe kolomnya
+func nilaiCursorProduk(cursor helpers.Cursor, urutan string) (interface{}, error) {
+	switch urutan {
+	case UrutanHargaTerendah, UrutanHargaTertinggi, UrutanTerlaris:
+		return cursor.Angka()
+	case UrutanNama:
+		return cursor.Nilai, nil
+	default:
+		return cursor.Waktu()
 	}
-	
-	return produks, nil
 }
 
 // FindByID mengambil produk tunggal
@@ -112,13 +234,15 @@ func (r *produkRepository) FindByID(produkID uint) (model.Produk, error) {
 		Preload("Toko").
 		Preload("Category").
 		Preload("FotoProduk").
+		Preload("Opsi.Nilai").
+		Preload("Varian.NilaiOpsi").
 		Where("id = ?", produkID).First(&produk).Error
 	return produk, err
 }
 
-// Update menyimpan perubahan pada produk
+// Update menyimpan perubahan pada produk (tanpa relasi)
 func (r *produkRepository) Update(produk model.Produk) (model.Produk, error) {
-	err := r.db.Save(&produk).Error
+	err := r.db.Omit(clause.Associations).Save(&produk).Error
 	return produk, err
 }
 
@@ -139,7 +263,11 @@ func (r *produkRepository) Delete(produkID uint) error {
 		if err := tx.Where("product_id = ?", produkID).Delete(&model.FotoProduk{}).Error; err != nil {
 			return err
 		}
-		// 2. Hapus Produk
+		// 2. Hapus varian beserta opsinya
+		if err := deleteVarianProduk(tx, produkID); err != nil {
+			return err
+		}
+		// 3. Hapus Produk
 		if err := tx.Delete(&model.Produk{}, produkID).Error; err != nil {
 			return err
 		}
@@ -158,4 +286,32 @@ func (r *produkRepository) FindByIDForUpdate(tx *gorm.DB, produkID uint) (model.
 // UpdateStok hanya memperbarui stok
 func (r *produkRepository) UpdateStok(tx *gorm.DB, produkID uint, newStok uint) error {
 	return tx.Model(&model.Produk{}).Where("id = ?", produkID).Update("stok", newStok).Error
-}
\ No newline at end of file
+}
+
+// FindVarianForUpdate mengambil varian milik produk dan mengunci barisnya.
+// Dipanggil setelah baris produk dikunci agar urutan kunci selalu produk lalu varian.
+func (r *produkRepository) FindVarianForUpdate(tx *gorm.DB, produkID uint, varianID uint) (model.VarianProduk, error) {
+	var varian model.VarianProduk
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND product_id = ?", varianID, produkID).First(&varian).Error
+	return varian, err
+}
+
+// UpdateStokVarian hanya memperbarui stok varian
+func (r *produkRepository) UpdateStokVarian(tx *gorm.DB, varianID uint, newStok uint) error {
+	return tx.Model(&model.VarianProduk{}).Where("id = ?", varianID).Update("stok", newStok).Error
+}
+
+// CountVarian menghitung jumlah varian sebuah produk
+func (r *produkRepository) CountVarian(tx *gorm.DB, produkID uint) (int64, error) {
+	var total int64
+	err := tx.Model(&model.VarianProduk{}).Where("product_id = ?", produkID).Count(&total).Error
+	return total, err
+}
+
+// SlugExists mengecek apakah slug sudah dipakai produk lain
+func (r *produkRepository) SlugExists(slug string, exceptID uint) (bool, error) {
+	var total int64
+	err := r.db.Model(&model.Produk{}).Where("slug = ? AND id <> ?", slug, exceptID).Count(&total).Error
+	return total > 0, err
+}
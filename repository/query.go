@@ -0,0 +1,12 @@
+package repository
+
+import "strings"
+
+// escapeLike meloloskan karakter wildcard LIKE (%, _) dan karakter escape-nya sendiri
+var escapeLike = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// polaMengandung membuat pola LIKE untuk mencari teks yang mengandung kata secara harfiah,
+// sehingga input seperti "50%" atau "a_b" tidak diperlakukan sebagai wildcard
+func polaMengandung(kata string) string {
+	return "%" + escapeLike.Replace(kata) + "%"
+}
@@ -0,0 +1,18 @@
+package repository
+
+import "testing"
+
+func TestPolaMengandung(t *testing.T) {
+	tests := map[string]string{
+		"kaos":      "%kaos%",
+		"diskon50%": `%diskon50\%%`,
+		"INV_01":    `%INV\_01%`,
+		`a\b`:       `%a\\b%`,
+		"":          "%%",
+	}
+	for kata, ingin := range tests {
+		if pola := polaMengandung(kata); pola != ingin {
+			t.Errorf("polaMengandung(%q) = %q, ingin %q", kata, pola, ingin)
+		}
+	}
+}
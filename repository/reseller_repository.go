@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"github.com/Debjth19/go-evermos/helpers"
+	"github.com/Debjth19/go-evermos/model"
+
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MarginReportRow adalah ringkasan pesanan dropship seorang reseller dalam satu periode
+type MarginReportRow struct {
+	Periode        string
+	JumlahPesanan  int64
+	TotalPenjualan uint64 // Total harga jual ke pelanggan
+	TotalModal     uint64 // Total yang dibayar reseller
+	TotalMargin    uint64
+}
+
+type ResellerRepository interface {
+	Create(pengajuan *model.PengajuanReseller) error
+	FindLatestByUserID(userID uint) (model.PengajuanReseller, error)
+	FindByID(pengajuanID uint) (model.PengajuanReseller, error)
+	FindByIDForUpdate(tx *gorm.DB, pengajuanID uint) (model.PengajuanReseller, error)
+	FindAll(pagination helpers.Pagination, status string) ([]model.PengajuanReseller, error)
+	Update(tx *gorm.DB, pengajuan *model.PengajuanReseller) error
+	UpdateUserRole(tx *gorm.DB, userID uint, role string) error
+	MarginReport(userID uint, formatPeriode string, dari, sampai time.Time) ([]MarginReportRow, error)
+}
+
+type resellerRepository struct {
+	db *gorm.DB
+}
+
+func NewResellerRepository(db *gorm.DB) ResellerRepository {
+	return &resellerRepository{db}
+}
+
+// Create menyimpan pengajuan reseller baru
+func (r *resellerRepository) Create(pengajuan *model.PengajuanReseller) error {
+	return r.db.Create(pengajuan).Error
+}
+
+// FindLatestByUserID mengambil pengajuan terakhir milik user
+func (r *resellerRepository) FindLatestByUserID(userID uint) (model.PengajuanReseller, error) {
+	var pengajuan model.PengajuanReseller
+	err := r.db.Where("user_id = ?", userID).
+		Order("id desc").
+		First(&pengajuan).Error
+	return pengajuan, err
+}
+
+// FindByID mengambil pengajuan beserta data user-nya
+func (r *resellerRepository) FindByID(pengajuanID uint) (model.PengajuanReseller, error) {
+	var pengajuan model.PengajuanReseller
+	err := r.db.Preload("User").First(&pengajuan, pengajuanID).Error
+	return pengajuan, err
+}
+
+// FindByIDForUpdate mengambil pengajuan dan mengunci barisnya
+func (r *resellerRepository) FindByIDForUpdate(tx *gorm.DB, pengajuanID uint) (model.PengajuanReseller, error) {
+	var pengajuan model.PengajuanReseller
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&pengajuan, pengajuanID).Error
+	return pengajuan, err
+}
+
+// FindAll mengambil pengajuan reseller dengan pagination dan filter status opsional
+func (r *resellerRepository) FindAll(pagination helpers.Pagination, status string) ([]model.PengajuanReseller, error) {
+	var pengajuans []model.PengajuanReseller
+
+	query := r.db.Preload("User")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	// Terapkan pagination, pengajuan paling lama tampil lebih dulu
+	offset := (pagination.Page - 1) * pagination.Limit
+	err := query.Order("created_at asc").
+		Limit(pagination.Limit).Offset(offset).
+		Find(&pengajuans).Error
+	return pengajuans, err
+}
+
+// Update menyimpan perubahan pada pengajuan tanpa menyentuh relasinya
+func (r *resellerRepository) Update(tx *gorm.DB, pengajuan *model.PengajuanReseller) error {
+	return tx.Omit(clause.Associations).Save(pengajuan).Error
+}
+
+// UpdateUserRole hanya memperbarui role user
+func (r *resellerRepository) UpdateUserRole(tx *gorm.DB, userID uint, role string) error {
+	return tx.Model(&model.User{}).Where("id = ?", userID).Update("role", role).Error
+}
+
+// MarginReport menjumlahkan margin pesanan dropship per periode (format DATE_FORMAT MySQL).
+// Pesanan yang dibatalkan atau kedaluwarsa tidak dihitung.
+func (r *resellerRepository) MarginReport(userID uint, formatPeriode string, dari, sampai time.Time) ([]MarginReportRow, error) {
+	var rows []MarginReportRow
+
+	query := r.db.Model(&model.DetailTransaksi{}).
+		Select(`DATE_FORMAT(transaksis.created_at, ?) AS periode,
+			COUNT(DISTINCT transaksis.id) AS jumlah_pesanan,
+			SUM(detail_transaksis.harga_jual * detail_transaksis.kuantitas) AS total_penjualan,
+			SUM(detail_transaksis.harga_total) AS total_modal,
+			SUM(detail_transaksis.margin) AS total_margin`, formatPeriode).
+		Joins("JOIN transaksis ON transaksis.id = detail_transaksis.transaksi_id").
+		Where("transaksis.user_id = ? AND transaksis.dropship = ?", userID, true).
+		Where("transaksis.status NOT IN ?", []string{model.StatusCancelled, model.StatusExpired})
+	if !dari.IsZero() {
+		query = query.Where("transaksis.created_at >= ?", dari)
+	}
+	if !sampai.IsZero() {
+		query = query.Where("transaksis.created_at < ?", sampai)
+	}
+
+	err := query.Group("periode").Order("periode asc").Scan(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"github.com/Debjth19/go-evermos/helpers"
+	"github.com/Debjth19/go-evermos/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReturRepository interface {
+	Create(tx *gorm.DB, retur *model.Retur) error
+	FindByID(returID uint) (model.Retur, error)
+	FindByIDForUpdate(tx *gorm.DB, returID uint) (model.Retur, error)
+	FindByUserID(userID uint) ([]model.Retur, error)
+	FindByTokoID(tokoID uint, pagination helpers.Pagination, status string) ([]model.Retur, error)
+	Update(tx *gorm.DB, retur *model.Retur) error
+	SumReturnedQty(tx *gorm.DB, detailIDs []uint) (map[uint]uint, error)
+	CreateRefund(tx *gorm.DB, refund *model.Refund) error
+}
+
+type returRepository struct {
+	db *gorm.DB
+}
+
+func NewReturRepository(db *gorm.DB) ReturRepository {
+	return &returRepository{db}
+}
+
+// Create menyimpan retur beserta item dan fotonya
+func (r *returRepository) Create(tx *gorm.DB, retur *model.Retur) error {
+	return tx.Create(retur).Error
+}
+
+// preloads adalah helper untuk query GET agar data relasinya ikut terambil
+func (r *returRepository) preloads() *gorm.DB {
+	return r.db.
+		Preload("Transaksi").
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id asc")
+		}).
+		Preload("FotoRetur").
+		Preload("Refund")
+}
+
+// FindByID mengambil satu retur beserta relasinya
+func (r *returRepository) FindByID(returID uint) (model.Retur, error) {
+	var retur model.Retur
+	err := r.preloads().First(&retur, returID).Error
+	return retur, err
+}
+
+// FindByIDForUpdate mengambil retur beserta itemnya dan mengunci barisnya
+func (r *returRepository) FindByIDForUpdate(tx *gorm.DB, returID uint) (model.Retur, error) {
+	var retur model.Retur
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("product_id asc") // Urutan tetap agar penguncian produk konsisten
+		}).
+		First(&retur, returID).Error
+	return retur, err
+}
+
+// FindByUserID mengambil semua retur yang diajukan seorang pembeli
+func (r *returRepository) FindByUserID(userID uint) ([]model.Retur, error) {
+	var returs []model.Retur
+	err := r.preloads().Where("user_id = ?", userID).
+		Order("created_at desc").
+		Find(&returs).Error
+	return returs, err
+}
+
+// FindByTokoID mengambil retur yang masuk ke sebuah toko, dengan filter status opsional
+func (r *returRepository) FindByTokoID(tokoID uint, pagination helpers.Pagination, status string) ([]model.Retur, error) {
+	var returs []model.Retur
+
+	query := r.preloads().Where("toko_id = ?", tokoID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	// Terapkan pagination
+	offset := (pagination.Page - 1) * pagination.Limit
+	err := query.Order("created_at desc").
+		Limit(pagination.Limit).Offset(offset).
+		Find(&returs).Error
+	return returs, err
+}
+
+// Update menyimpan perubahan pada retur tanpa menyentuh relasinya
+func (r *returRepository) Update(tx *gorm.DB, retur *model.Retur) error {
+	return tx.Omit(clause.Associations).Save(retur).Error
+}
+
+// SumReturnedQty menghitung kuantitas yang sudah diajukan retur (dan tidak ditolak)
+// untuk setiap DetailTransaksi
+func (r *returRepository) SumReturnedQty(tx *gorm.DB, detailIDs []uint) (map[uint]uint, error) {
+	var rows []struct {
+		DetailTransaksiID uint
+		Total             uint
+	}
+	err := tx.Model(&model.ReturItem{}).
+		Select("retur_items.detail_transaksi_id, SUM(retur_items.kuantitas) AS total").
+		Joins("JOIN returs ON returs.id = retur_items.retur_id").
+		Where("retur_items.detail_transaksi_id IN ? AND returs.status <> ?", detailIDs, model.ReturRejected).
+		Group("retur_items.detail_transaksi_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	totals := map[uint]uint{}
+	for _, row := range rows {
+		totals[row.DetailTransaksiID] = row.Total
+	}
+	return totals, nil
+}
+
+// CreateRefund menyimpan catatan refund
+func (r *returRepository) CreateRefund(tx *gorm.DB, refund *model.Refund) error {
+	return tx.Create(refund).Error
+}
@@ -11,7 +11,7 @@ type TokoRepository interface {
 	FindByUserID(userID uint) (model.Toko, error)
 	FindByID(tokoID uint) (model.Toko, error)
 	Update(toko model.Toko) (model.Toko, error)
-	FindAll(pagination helpers.Pagination, search string) ([]model.Toko, error)
+	FindAll(pagination *helpers.Pagination, search string) ([]model.Toko, error)
 }
 
 type tokoRepository struct {
@@ -48,22 +48,34 @@ func (r *tokoRepository) Update(toko model.Toko) (model.Toko, error) {
 	return toko, nil
 }
 
-func (r *tokoRepository) FindAll(pagination helpers.Pagination, search string) ([]model.Toko, error) {
-	var tokos []model.Toko
-	
-	query := r.db.Model(&model.Toko{})
+// FindAll mengambil toko urut ID dengan pagination (halaman atau cursor),
+// lalu mengisi total toko yang cocok dan cursor halaman berikutnya ke pagination
+func (r *tokoRepository) FindAll(pagination *helpers.Pagination, search string) ([]model.Toko, error) {
+	cursor, err := pagination.DecodeCursor()
+	if err != nil {
+		return nil, err
+	}
 
-	// Terapkan filter 
-	if search != "" {
-		query = query.Where("nama_toko LIKE ?", "%"+search+"%")
+	// Terapkan filter
+	where := func(query *gorm.DB) *gorm.DB {
+		if search != "" {
+			query = query.Where("nama_toko LIKE ?", polaMengandung(search))
+		}
+		return query
 	}
 
-	// Terapkan pagination 
-	offset := (pagination.Page - 1) * pagination.Limit
-	err := query.Limit(pagination.Limit).Offset(offset).Find(&tokos).Error
-	if err != nil {
-		return tokos, err
+	var total int64
+	if err := where(r.db.Model(&model.Toko{})).Count(&total).Error; err != nil {
+		return nil, err
+	}
+	pagination.SetTotal(total)
+
+	// Terapkan pagination
+	query := where(r.db.Model(&model.Toko{})).Order("id asc")
+	if cursor != nil {
+		query = query.Where("id > ?", cursor.ID)
 	}
-	
-	return tokos, nil
+	return ambilHalaman(query, pagination, func(t model.Toko) (helpers.Cursor, error) {
+		return helpers.Cursor{ID: t.ID}, nil
+	})
 }
\ No newline at end of file
@@ -1,17 +1,43 @@
 package repository
 
 import (
+	"github.com/Debjth19/go-evermos/helpers"
 	"github.com/Debjth19/go-evermos/model"
 
+	"time"
+
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// Struct untuk filter
+type TransaksiFilter struct {
+	Status        string
+	TanggalDari   time.Time
+	TanggalSampai time.Time // Eksklusif
+	KodeInvoice   string    // Pencarian sebagian
+	MethodBayar   string
+	MinTotal      uint
+	MaxTotal      uint
+}
+
 type TransaksiRepository interface {
 	Create(tx *gorm.DB, transaksi *model.Transaksi) error
 	CreateDetail(tx *gorm.DB, details []model.DetailTransaksi) error
 	CreateLog(tx *gorm.DB, logs []model.LogProduk) error
-	FindMyTransactions(userID uint) ([]model.Transaksi, error)
+	FindMyTransactions(userID uint, pagination *helpers.Pagination, filter TransaksiFilter) ([]model.Transaksi, error)
 	FindMyTransactionByID(userID, trxID uint) (model.Transaksi, error)
+	FindByID(tx *gorm.DB, trxID uint) (model.Transaksi, error)
+	FindByIDForUpdate(tx *gorm.DB, trxID uint) (model.Transaksi, error)
+	FindByKodeInvoiceForUpdate(tx *gorm.DB, kodeInvoice string) (model.Transaksi, error)
+	ClaimUnpaidBefore(tx *gorm.DB, batas time.Time, lewati []uint) (model.Transaksi, error)
+	Update(tx *gorm.DB, transaksi *model.Transaksi) error
+	FindChildrenForUpdate(tx *gorm.DB, parentID uint) ([]model.Transaksi, error)
+	FindDetailsByTransaksiID(tx *gorm.DB, trxID uint) ([]model.DetailTransaksi, error)
+	FindDetailWithSnapshot(detailID uint) (model.DetailTransaksi, error)
+	FindLogsByTransaksiID(tx *gorm.DB, trxID uint) ([]model.LogProduk, error)
+	FindTokoOrders(tokoID uint, pagination *helpers.Pagination, filter TransaksiFilter) ([]model.Transaksi, error)
+	FindTokoOrderByID(tokoID, trxID uint) (model.Transaksi, error)
 }
 
 type transaksiRepository struct {
@@ -39,24 +65,58 @@ func (r *transaksiRepository) CreateLog(tx *gorm.DB, logs []model.LogProduk) err
 
 // preloads adalah helper untuk query GET agar data relasinya ikut terambil
 func (r *transaksiRepository) preloads() *gorm.DB {
-	return r.db.
-		Preload("Alamat"). // Nama relasi di model.Transaksi
-		Preload("DetailTransaksi"). // Nama relasi
-		Preload("DetailTransaksi.Produk"). // Relasi di dalam DetailTransaksi
-		Preload("DetailTransaksi.Produk.Toko").
-		Preload("DetailTransaksi.Produk.Category").
-		Preload("DetailTransaksi.Produk.FotoProduk").
-		Preload("DetailTransaksi.Toko") // Relasi Toko di DetailTransaksi
-}
-
-// FindMyTransactions mengambil semua transaksi milik user
-func (r *transaksiRepository) FindMyTransactions(userID uint) ([]model.Transaksi, error) {
-	var transaksis []model.Transaksi
-	err := r.preloads().
-		Where("user_id = ?", userID).
+	query := r.db.Preload("Pembayaran").Preload("Voucher").Preload("SubTransaksi", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id asc")
+	})
+	// Relasi dimuat untuk transaksi itu sendiri dan untuk setiap sub-transaksinya
+	for _, prefix := range []string{"", "SubTransaksi."} {
+		query = query.
+			Preload(prefix + "DetailTransaksi").                   // Nama relasi
+			Preload(prefix + "DetailTransaksi.LogProduk").         // Snapshot produk saat checkout
+			Preload(prefix + "DetailTransaksi.Produk.FotoProduk"). // Foto tetap mengikuti produk terkini
+			Preload(prefix + "DetailTransaksi.Toko").              // Relasi Toko di DetailTransaksi
+			Preload(prefix + "LogProduk")
+	}
+	return query
+}
+
+// FindMyTransactions mengambil transaksi induk milik user dengan pagination dan filter.
+// Jumlah seluruh transaksi yang cocok dengan filter diisi ke pagination.
+func (r *transaksiRepository) FindMyTransactions(userID uint, pagination *helpers.Pagination, filter TransaksiFilter) ([]model.Transaksi, error) {
+	return r.findHalaman(pagination, func(query *gorm.DB) *gorm.DB {
+		return r.applyFilter(query.Where("user_id = ? AND parent_id IS NULL", userID), filter)
+	})
+}
+
+// findHalaman menghitung total lalu mengambil satu halaman transaksi, terbaru lebih dulu.
+// Pada mode cursor halaman dilanjutkan setelah (created_at, id) transaksi terakhir.
+func (r *transaksiRepository) findHalaman(pagination *helpers.Pagination, where func(query *gorm.DB) *gorm.DB) ([]model.Transaksi, error) {
+	cursor, err := pagination.DecodeCursor()
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. Hitung total tanpa preload
+	var total int64
+	if err := where(r.db.Model(&model.Transaksi{})).Count(&total).Error; err != nil {
+		return nil, err
+	}
+	pagination.SetTotal(total)
+
+	// 2. Ambil satu halaman beserta relasinya
+	query := where(r.preloads()).
 		Order("created_at desc"). // Tampilkan yang terbaru dulu
-		Find(&transaksis).Error
-	return transaksis, err
+		Order("id desc")
+	if cursor != nil {
+		waktu, err := cursor.Waktu()
+		if err != nil {
+			return nil, err
+		}
+		query = setelahCursor(query, "created_at", "id", false, waktu, cursor.ID)
+	}
+	return ambilHalaman(query, pagination, func(t model.Transaksi) (helpers.Cursor, error) {
+		return helpers.CursorWaktu(t.CreatedAt, t.ID), nil
+	})
 }
 
 // FindMyTransactionByID mengambil satu transaksi milik user
@@ -66,4 +126,123 @@ func (r *transaksiRepository) FindMyTransactionByID(userID, trxID uint) (model.T
 		Where("user_id = ? AND id = ?", userID, trxID).
 		First(&transaksi).Error
 	return transaksi, err
-}
\ No newline at end of file
+}
+
+// FindByID mengambil transaksi tanpa relasi
+func (r *transaksiRepository) FindByID(tx *gorm.DB, trxID uint) (model.Transaksi, error) {
+	var transaksi model.Transaksi
+	err := tx.Where("id = ?", trxID).First(&transaksi).Error
+	return transaksi, err
+}
+
+// FindByIDForUpdate mengambil transaksi dan mengunci barisnya
+func (r *transaksiRepository) FindByIDForUpdate(tx *gorm.DB, trxID uint) (model.Transaksi, error) {
+	var transaksi model.Transaksi
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", trxID).First(&transaksi).Error
+	return transaksi, err
+}
+
+// FindByKodeInvoiceForUpdate mengambil transaksi berdasarkan kode invoice dan mengunci barisnya
+func (r *transaksiRepository) FindByKodeInvoiceForUpdate(tx *gorm.DB, kodeInvoice string) (model.Transaksi, error) {
+	var transaksi model.Transaksi
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("kode_invoice = ?", kodeInvoice).First(&transaksi).Error
+	return transaksi, err
+}
+
+// ClaimUnpaidBefore mengambil dan mengunci satu transaksi induk yang belum dibayar sejak sebelum 'batas',
+// yaitu yang masih memiliki tagihan pembayaran 'pending', selain transaksi dengan ID dalam 'lewati'.
+// Baris yang sedang dikunci proses lain dilewati (SKIP LOCKED) sehingga beberapa instance server
+// dapat menjalankan worker kedaluwarsa bersamaan tanpa memproses transaksi yang sama.
+func (r *transaksiRepository) ClaimUnpaidBefore(tx *gorm.DB, batas time.Time, lewati []uint) (model.Transaksi, error) {
+	var transaksi model.Transaksi
+	query := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("parent_id IS NULL AND status = ? AND created_at < ?", model.StatusPendingPayment, batas).
+		Where("EXISTS (SELECT 1 FROM pembayarans p WHERE p.transaksi_id = transaksis.id AND p.status = ?)", model.PembayaranPending)
+	if len(lewati) > 0 {
+		query = query.Where("transaksis.id NOT IN ?", lewati)
+	}
+	err := query.Order("id asc").First(&transaksi).Error
+	return transaksi, err
+}
+
+// Update menyimpan perubahan pada transaksi (tanpa relasi)
+func (r *transaksiRepository) Update(tx *gorm.DB, transaksi *model.Transaksi) error {
+	return tx.Omit(clause.Associations).Save(transaksi).Error
+}
+
+// FindChildrenForUpdate mengambil semua sub-transaksi dari transaksi induk dan mengunci barisnya
+func (r *transaksiRepository) FindChildrenForUpdate(tx *gorm.DB, parentID uint) ([]model.Transaksi, error) {
+	var children []model.Transaksi
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("parent_id = ?", parentID).
+		Order("id asc").
+		Find(&children).Error
+	return children, err
+}
+
+// FindDetailsByTransaksiID mengambil item-item detail sebuah transaksi
+func (r *transaksiRepository) FindDetailsByTransaksiID(tx *gorm.DB, trxID uint) ([]model.DetailTransaksi, error) {
+	var details []model.DetailTransaksi
+	err := tx.Where("transaksi_id = ?", trxID).
+		Order("product_id asc"). // Urutan tetap agar penguncian produk konsisten
+		Find(&details).Error
+	return details, err
+}
+
+// FindDetailWithSnapshot mengambil satu item transaksi beserta snapshot produknya
+func (r *transaksiRepository) FindDetailWithSnapshot(detailID uint) (model.DetailTransaksi, error) {
+	var detail model.DetailTransaksi
+	err := r.db.Preload("LogProduk").Preload("Toko").First(&detail, detailID).Error
+	return detail, err
+}
+
+// FindLogsByTransaksiID mengambil snapshot produk sebuah transaksi
+func (r *transaksiRepository) FindLogsByTransaksiID(tx *gorm.DB, trxID uint) ([]model.LogProduk, error) {
+	var logs []model.LogProduk
+	err := tx.Where("transaksi_id = ?", trxID).Find(&logs).Error
+	return logs, err
+}
+
+// applyFilter menerapkan filter transaksi ke query
+func (r *transaksiRepository) applyFilter(query *gorm.DB, filter TransaksiFilter) *gorm.DB {
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if !filter.TanggalDari.IsZero() {
+		query = query.Where("created_at >= ?", filter.TanggalDari)
+	}
+	if !filter.TanggalSampai.IsZero() {
+		query = query.Where("created_at < ?", filter.TanggalSampai)
+	}
+	if filter.KodeInvoice != "" {
+		query = query.Where("kode_invoice LIKE ?", polaMengandung(filter.KodeInvoice))
+	}
+	if filter.MethodBayar != "" {
+		query = query.Where("method_bayar = ?", filter.MethodBayar)
+	}
+	if filter.MinTotal != 0 {
+		query = query.Where("harga_total >= ?", filter.MinTotal)
+	}
+	if filter.MaxTotal != 0 {
+		query = query.Where("harga_total <= ?", filter.MaxTotal)
+	}
+	return query
+}
+
+// FindTokoOrders mengambil sub-transaksi milik sebuah toko
+func (r *transaksiRepository) FindTokoOrders(tokoID uint, pagination *helpers.Pagination, filter TransaksiFilter) ([]model.Transaksi, error) {
+	return r.findHalaman(pagination, func(query *gorm.DB) *gorm.DB {
+		return r.applyFilter(query.Where("toko_id = ?", tokoID), filter)
+	})
+}
+
+// FindTokoOrderByID mengambil satu sub-transaksi milik sebuah toko
+func (r *transaksiRepository) FindTokoOrderByID(tokoID, trxID uint) (model.Transaksi, error) {
+	var transaksi model.Transaksi
+	err := r.preloads().
+		Where("toko_id = ? AND id = ?", tokoID, trxID).
+		First(&transaksi).Error
+	return transaksi, err
+}
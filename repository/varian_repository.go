@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"github.com/Debjth19/go-evermos/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type VarianRepository interface {
+	FindOpsiByProduk(produkID uint) ([]model.OpsiProduk, error)
+	CreateOpsi(opsi *model.OpsiProduk) error
+	DeleteOpsi(opsiID uint) error
+	FindByProduk(produkID uint) ([]model.VarianProduk, error)
+	FindByID(varianID uint) (model.VarianProduk, error)
+	SKUExists(produkID uint, sku string, exceptID uint) (bool, error)
+	Create(varian *model.VarianProduk) error
+	Update(varian *model.VarianProduk, perubahan map[string]interface{}) error
+	Delete(varian model.VarianProduk) error
+}
+
+type varianRepository struct {
+	db *gorm.DB
+}
+
+func NewVarianRepository(db *gorm.DB) VarianRepository {
+	return &varianRepository{db}
+}
+
+// FindOpsiByProduk mengambil kelompok opsi produk beserta nilainya, urut sesuai pembuatan
+func (r *varianRepository) FindOpsiByProduk(produkID uint) ([]model.OpsiProduk, error) {
+	var opsi []model.OpsiProduk
+	err := r.db.Preload("Nilai").Where("product_id = ?", produkID).Order("id asc").Find(&opsi).Error
+	return opsi, err
+}
+
+// CreateOpsi menyimpan kelompok opsi beserta nilainya
+func (r *varianRepository) CreateOpsi(opsi *model.OpsiProduk) error {
+	return r.db.Create(opsi).Error
+}
+
+// DeleteOpsi menghapus kelompok opsi beserta nilainya
+func (r *varianRepository) DeleteOpsi(opsiID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("opsi_id = ?", opsiID).Delete(&model.NilaiOpsiProduk{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.OpsiProduk{}, opsiID).Error
+	})
+}
+
+// FindByProduk mengambil varian sebuah produk beserta nilai opsinya
+func (r *varianRepository) FindByProduk(produkID uint) ([]model.VarianProduk, error) {
+	var varian []model.VarianProduk
+	err := r.db.Preload("NilaiOpsi").Where("product_id = ?", produkID).Order("id asc").Find(&varian).Error
+	return varian, err
+}
+
+// FindByID mengambil satu varian beserta nilai opsinya
+func (r *varianRepository) FindByID(varianID uint) (model.VarianProduk, error) {
+	var varian model.VarianProduk
+	err := r.db.Preload("NilaiOpsi").First(&varian, varianID).Error
+	return varian, err
+}
+
+// SKUExists mengecek apakah SKU sudah dipakai varian lain pada produk yang sama
+func (r *varianRepository) SKUExists(produkID uint, sku string, exceptID uint) (bool, error) {
+	var total int64
+	err := r.db.Model(&model.VarianProduk{}).
+		Where("product_id = ? AND sku = ? AND id <> ?", produkID, sku, exceptID).
+		Count(&total).Error
+	return total > 0, err
+}
+
+// Create menyimpan varian beserta nilai opsinya, lalu menyesuaikan stok produk
+func (r *varianRepository) Create(varian *model.VarianProduk) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("NilaiOpsi.*").Create(varian).Error; err != nil {
+			return err
+		}
+		return syncStokProduk(tx, varian.ProductID)
+	})
+}
+
+// Update mengunci produk lalu varian (urutan yang sama dengan checkout), menyimpan hanya kolom
+// yang diubah, lalu menyesuaikan stok produk. Stok yang tidak diubah tetap mengikuti checkout
+// yang berjalan bersamaan. 'varian' diisi ulang dengan data terbaru.
+func (r *varianRepository) Update(varian *model.VarianProduk, perubahan map[string]interface{}) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&model.Produk{}, varian.ProductID).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&model.VarianProduk{}, varian.ID).Error; err != nil {
+			return err
+		}
+
+		if len(perubahan) > 0 {
+			if err := tx.Model(&model.VarianProduk{}).Where("id = ?", varian.ID).Updates(perubahan).Error; err != nil {
+				return err
+			}
+		}
+		if _, ok := perubahan["stok"]; ok {
+			if err := syncStokProduk(tx, varian.ProductID); err != nil {
+				return err
+			}
+		}
+		return tx.Preload("NilaiOpsi").First(varian, varian.ID).Error
+	})
+}
+
+// Delete menghapus varian beserta item keranjang yang memilihnya, lalu menyesuaikan stok produk
+func (r *varianRepository) Delete(varian model.VarianProduk) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&varian).Association("NilaiOpsi").Clear(); err != nil {
+			return err
+		}
+		if err := tx.Where("varian_id = ?", varian.ID).Delete(&model.KeranjangItem{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&model.VarianProduk{}, varian.ID).Error; err != nil {
+			return err
+		}
+		return syncStokProduk(tx, varian.ProductID)
+	})
+}
+
+// syncStokProduk menyamakan stok produk dengan jumlah stok seluruh variannya
+func syncStokProduk(tx *gorm.DB, produkID uint) error {
+	return tx.Model(&model.Produk{}).Where("id = ?", produkID).
+		Update("stok", tx.Model(&model.VarianProduk{}).Select("COALESCE(SUM(stok), 0)").Where("product_id = ?", produkID)).Error
+}
+
+// deleteVarianProduk menghapus seluruh varian dan opsi sebuah produk
+func deleteVarianProduk(tx *gorm.DB, produkID uint) error {
+	varianIDs := tx.Model(&model.VarianProduk{}).Select("id").Where("product_id = ?", produkID)
+	if err := tx.Exec("DELETE FROM varian_nilai_opsi WHERE varian_produk_id IN (?)", varianIDs).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("product_id = ?", produkID).Delete(&model.VarianProduk{}).Error; err != nil {
+		return err
+	}
+	opsiIDs := tx.Model(&model.OpsiProduk{}).Select("id").Where("product_id = ?", produkID)
+	if err := tx.Where("opsi_id IN (?)", opsiIDs).Delete(&model.NilaiOpsiProduk{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("product_id = ?", produkID).Delete(&model.OpsiProduk{}).Error
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"github.com/Debjth19/go-evermos/helpers"
+	"github.com/Debjth19/go-evermos/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type VoucherRepository interface {
+	Create(voucher *model.Voucher) error
+	FindByID(voucherID uint) (model.Voucher, error)
+	FindByKodeForUpdate(tx *gorm.DB, kode string) (model.Voucher, error)
+	KodeExists(kode string) (bool, error)
+	FindByToko(tokoID uint, pagination helpers.Pagination) ([]model.Voucher, error)
+	Update(voucher *model.Voucher) error
+	IncrementTerpakai(tx *gorm.DB, voucherID uint) error
+	DecrementTerpakai(tx *gorm.DB, voucherID uint) error
+	CountPemakaianUser(tx *gorm.DB, voucherID uint, userID uint) (int64, error)
+	CreatePemakaian(tx *gorm.DB, pemakaian []model.PemakaianVoucher) error
+	FindPemakaianByTransaksi(tx *gorm.DB, transaksiID uint) ([]model.PemakaianVoucher, error)
+	DeletePemakaian(tx *gorm.DB, pemakaianID uint) error
+	FindPemakaian(voucherID uint, pagination helpers.Pagination) ([]model.PemakaianVoucher, int64, error)
+	SumPemakaian(voucherID uint) (uint64, error)
+}
+
+type voucherRepository struct {
+	db *gorm.DB
+}
+
+func NewVoucherRepository(db *gorm.DB) VoucherRepository {
+	return &voucherRepository{db}
+}
+
+// Create menyimpan voucher beserta batasan kategorinya
+func (r *voucherRepository) Create(voucher *model.Voucher) error {
+	return r.db.Create(voucher).Error
+}
+
+// FindByID mengambil voucher beserta batasan kategorinya
+func (r *voucherRepository) FindByID(voucherID uint) (model.Voucher, error) {
+	var voucher model.Voucher
+	err := r.db.Preload("Kategori").First(&voucher, voucherID).Error
+	return voucher, err
+}
+
+// FindByKodeForUpdate mengambil voucher berdasarkan kode dan mengunci barisnya
+// agar kuota tidak terpakai melebihi batas oleh checkout yang berjalan bersamaan
+func (r *voucherRepository) FindByKodeForUpdate(tx *gorm.DB, kode string) (model.Voucher, error) {
+	var voucher model.Voucher
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Kategori").
+		Where("kode = ?", kode).
+		First(&voucher).Error
+	return voucher, err
+}
+
+// KodeExists mengecek apakah kode voucher sudah dipakai
+func (r *voucherRepository) KodeExists(kode string) (bool, error) {
+	var total int64
+	err := r.db.Model(&model.Voucher{}).Where("kode = ?", kode).Count(&total).Error
+	return total > 0, err
+}
+
+// FindByToko mengambil voucher milik sebuah toko (0 = voucher platform), terbaru lebih dulu
+func (r *voucherRepository) FindByToko(tokoID uint, pagination helpers.Pagination) ([]model.Voucher, error) {
+	var vouchers []model.Voucher
+
+	offset := (pagination.Page - 1) * pagination.Limit
+	err := r.db.Preload("Kategori").
+		Where("toko_id = ?", tokoID).
+		Order("id desc").
+		Limit(pagination.Limit).Offset(offset).
+		Find(&vouchers).Error
+	return vouchers, err
+}
+
+// Update menyimpan perubahan voucher dan mengganti batasan kategorinya.
+// Terpakai tidak ikut disimpan karena hanya diubah checkout dan pembatalan di bawah kunci baris.
+func (r *voucherRepository) Update(voucher *model.Voucher) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations, "Terpakai").Save(voucher).Error; err != nil {
+			return err
+		}
+		return tx.Model(voucher).Association("Kategori").Replace(voucher.Kategori)
+	})
+}
+
+// IncrementTerpakai menambah penghitung pemakaian voucher
+func (r *voucherRepository) IncrementTerpakai(tx *gorm.DB, voucherID uint) error {
+	return tx.Model(&model.Voucher{}).Where("id = ?", voucherID).
+		Update("terpakai", gorm.Expr("terpakai + 1")).Error
+}
+
+// DecrementTerpakai mengurangi penghitung pemakaian voucher yang dilepas kembali
+func (r *voucherRepository) DecrementTerpakai(tx *gorm.DB, voucherID uint) error {
+	return tx.Model(&model.Voucher{}).Where("id = ? AND terpakai > 0", voucherID).
+		Update("terpakai", gorm.Expr("terpakai - 1")).Error
+}
+
+// CountPemakaianUser menghitung berapa kali seorang user sudah memakai voucher
+func (r *voucherRepository) CountPemakaianUser(tx *gorm.DB, voucherID uint, userID uint) (int64, error) {
+	var total int64
+	err := tx.Model(&model.PemakaianVoucher{}).
+		Where("voucher_id = ? AND user_id = ?", voucherID, userID).
+		Count(&total).Error
+	return total, err
+}
+
+// CreatePemakaian menyimpan potongan voucher pada sebuah transaksi
+func (r *voucherRepository) CreatePemakaian(tx *gorm.DB, pemakaian []model.PemakaianVoucher) error {
+	return tx.Create(&pemakaian).Error
+}
+
+// FindPemakaianByTransaksi mengambil potongan voucher pada sebuah transaksi induk
+func (r *voucherRepository) FindPemakaianByTransaksi(tx *gorm.DB, transaksiID uint) ([]model.PemakaianVoucher, error) {
+	var pemakaian []model.PemakaianVoucher
+	err := tx.Where("transaksi_id = ?", transaksiID).Order("id asc").Find(&pemakaian).Error
+	return pemakaian, err
+}
+
+// DeletePemakaian menghapus catatan pemakaian voucher
+func (r *voucherRepository) DeletePemakaian(tx *gorm.DB, pemakaianID uint) error {
+	return tx.Delete(&model.PemakaianVoucher{}, pemakaianID).Error
+}
+
+// FindPemakaian mengambil riwayat pemakaian voucher beserta transaksinya, terbaru lebih dulu
+func (r *voucherRepository) FindPemakaian(voucherID uint, pagination helpers.Pagination) ([]model.PemakaianVoucher, int64, error) {
+	var pemakaian []model.PemakaianVoucher
+	var total int64
+
+	where := func(query *gorm.DB) *gorm.DB {
+		return query.Where("voucher_id = ?", voucherID)
+	}
+	if err := where(r.db.Model(&model.PemakaianVoucher{})).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (pagination.Page - 1) * pagination.Limit
+	err := where(r.db.Preload("Transaksi")).
+		Order("id desc").
+		Limit(pagination.Limit).Offset(offset).
+		Find(&pemakaian).Error
+	return pemakaian, total, err
+}
+
+// SumPemakaian menjumlahkan seluruh potongan yang sudah diberikan sebuah voucher
+func (r *voucherRepository) SumPemakaian(voucherID uint) (uint64, error) {
+	var total uint64
+	err := r.db.Model(&model.PemakaianVoucher{}).
+		Select("COALESCE(SUM(jumlah), 0)").
+		Where("voucher_id = ?", voucherID).
+		Scan(&total).Error
+	return total, err
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"github.com/Debjth19/go-evermos/helpers"
+	"github.com/Debjth19/go-evermos/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WishlistRepository interface {
+	FindByUserID(userID uint, pagination helpers.Pagination) ([]model.Wishlist, int64, error)
+	Exists(userID uint, produkID uint) (bool, error)
+	Create(wishlist *model.Wishlist) error
+	Delete(userID uint, produkID uint) (int64, error)
+}
+
+type wishlistRepository struct {
+	db *gorm.DB
+}
+
+func NewWishlistRepository(db *gorm.DB) WishlistRepository {
+	return &wishlistRepository{db}
+}
+
+// FindByUserID mengambil wishlist user beserta produk terkininya, terbaru lebih dulu
+func (r *wishlistRepository) FindByUserID(userID uint, pagination helpers.Pagination) ([]model.Wishlist, int64, error) {
+	var wishlists []model.Wishlist
+	var total int64
+
+	if err := r.db.Model(&model.Wishlist{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (pagination.Page - 1) * pagination.Limit
+	err := r.db.
+		Preload("Produk").
+		Preload("Produk.Toko").
+		Preload("Produk.Category").
+		Preload("Produk.FotoProduk").
+		Where("user_id = ?", userID).
+		Order("id desc").
+		Limit(pagination.Limit).Offset(offset).
+		Find(&wishlists).Error
+	return wishlists, total, err
+}
+
+// Exists mengecek apakah produk sudah ada di wishlist user
+func (r *wishlistRepository) Exists(userID uint, produkID uint) (bool, error) {
+	var total int64
+	err := r.db.Model(&model.Wishlist{}).
+		Where("user_id = ? AND product_id = ?", userID, produkID).
+		Count(&total).Error
+	return total > 0, err
+}
+
+// Create menyimpan produk ke wishlist
+func (r *wishlistRepository) Create(wishlist *model.Wishlist) error {
+	return r.db.Omit(clause.Associations).Create(wishlist).Error
+}
+
+// Delete menghapus produk dari wishlist user dan mengembalikan jumlah baris yang terhapus
+func (r *wishlistRepository) Delete(userID uint, produkID uint) (int64, error) {
+	result := r.db.Where("user_id = ? AND product_id = ?", userID, produkID).Delete(&model.Wishlist{})
+	return result.RowsAffected, result.Error
+}
@@ -16,7 +16,17 @@ func SetupRoutes(
 	tokoHandler handler.TokoHandler, 
 	kategoriHandler handler.KategoriHandler,
 	produkHandler handler.ProdukHandler,
+	varianHandler handler.VarianHandler,
 	transaksiHandler handler.TransaksiHandler,
+	pembayaranHandler handler.PembayaranHandler,
+	returHandler handler.ReturHandler,
+	resellerHandler handler.ResellerHandler,
+	ledgerHandler handler.LedgerHandler,
+	voucherHandler handler.VoucherHandler,
+	platformVoucherHandler handler.VoucherHandler,
+	keranjangHandler handler.KeranjangHandler,
+	wishlistHandler handler.WishlistHandler,
+	idempotencyMiddleware fiber.Handler,
 ) {
 	api := app.Group("/api/v1")
 
@@ -29,6 +39,9 @@ func SetupRoutes(
 	user := api.Group("/user", middleware.AuthMiddleware()) // Middleware di sini
 	user.Get("/", userHandler.GetProfile)
 	user.Put("/", userHandler.UpdateProfile)
+	user.Post("/reseller", resellerHandler.Apply)
+	user.Get("/reseller", resellerHandler.GetMyApplication)
+	user.Get("/reseller/margin", resellerHandler.GetMarginReport)
 
 	// Rute untuk Alamat
 	alamat := user.Group("/alamat") 
@@ -38,11 +51,42 @@ func SetupRoutes(
 	alamat.Put("/:id", alamatHandler.UpdateAlamat)
 	alamat.Delete("/:id", alamatHandler.DeleteAlamat)
 
+	// Rute untuk Keranjang
+	keranjang := user.Group("/keranjang")
+	keranjang.Get("/", keranjangHandler.GetKeranjang)
+	keranjang.Post("/", keranjangHandler.AddItem)
+	keranjang.Post("/checkout", idempotencyMiddleware, keranjangHandler.Checkout) // Mendukung header Idempotency-Key
+	keranjang.Put("/:id", keranjangHandler.UpdateItem)
+	keranjang.Delete("/:id", keranjangHandler.RemoveItem)
+
+	// Rute untuk Wishlist
+	wishlist := user.Group("/wishlist")
+	wishlist.Get("/", wishlistHandler.GetWishlist)
+	wishlist.Post("/", wishlistHandler.AddWishlist)
+	wishlist.Delete("/:product_id", wishlistHandler.RemoveWishlist)
+
 	// Rute untuk Toko
 	toko := api.Group("/toko")
 	
 	// Rute yang perlu autentikasi
 	toko.Get("/my", middleware.AuthMiddleware(), tokoHandler.GetMyToko)
+	toko.Get("/my/orders", middleware.AuthMiddleware(), transaksiHandler.GetTokoOrders)
+	toko.Get("/my/orders/items/:id/snapshot", middleware.AuthMiddleware(), transaksiHandler.GetTokoItemSnapshot)
+	toko.Get("/my/orders/:id/invoice.pdf", middleware.AuthMiddleware(), transaksiHandler.GetTokoInvoicePDF)
+	toko.Put("/my/orders/:id/status", middleware.AuthMiddleware(), transaksiHandler.UpdateStatusBySeller)
+	toko.Get("/my/retur", middleware.AuthMiddleware(), returHandler.GetTokoReturs)
+	toko.Get("/my/retur/:id", middleware.AuthMiddleware(), returHandler.GetTokoReturByID)
+	toko.Put("/my/retur/:id/approve", middleware.AuthMiddleware(), returHandler.ApproveRetur)
+	toko.Put("/my/retur/:id/reject", middleware.AuthMiddleware(), returHandler.RejectRetur)
+	toko.Get("/my/saldo", middleware.AuthMiddleware(), ledgerHandler.GetSaldo)
+	toko.Get("/my/saldo/riwayat", middleware.AuthMiddleware(), ledgerHandler.GetRiwayatSaldo)
+	toko.Post("/my/penarikan", middleware.AuthMiddleware(), ledgerHandler.CreatePenarikan)
+	toko.Get("/my/penarikan", middleware.AuthMiddleware(), ledgerHandler.GetMyPenarikan)
+	toko.Post("/my/voucher", middleware.AuthMiddleware(), voucherHandler.CreateVoucher)
+	toko.Get("/my/voucher", middleware.AuthMiddleware(), voucherHandler.GetVouchers)
+	toko.Get("/my/voucher/:id", middleware.AuthMiddleware(), voucherHandler.GetVoucherByID)
+	toko.Put("/my/voucher/:id", middleware.AuthMiddleware(), voucherHandler.UpdateVoucher)
+	toko.Get("/my/voucher/:id/pemakaian", middleware.AuthMiddleware(), voucherHandler.GetPemakaian)
 	toko.Put("/:id_toko", middleware.AuthMiddleware(), tokoHandler.UpdateToko)
 	
 	// Rute publik 
@@ -67,15 +111,49 @@ func SetupRoutes(
 	product.Put("/:id", middleware.AuthMiddleware(), produkHandler.UpdateProduk)
 	product.Delete("/:id", middleware.AuthMiddleware(), produkHandler.DeleteProduk)
 
-	// Rute publik 
-	product.Get("/", produkHandler.GetAllProduk)
-	product.Get("/:id", produkHandler.GetProdukByID)
+	// Opsi dan varian produk (hanya pemilik toko)
+	product.Post("/:id/opsi", middleware.AuthMiddleware(), varianHandler.CreateOpsi)
+	product.Delete("/:id/opsi/:opsi_id", middleware.AuthMiddleware(), varianHandler.DeleteOpsi)
+	product.Post("/:id/varian", middleware.AuthMiddleware(), varianHandler.CreateVarian)
+	product.Put("/:id/varian/:varian_id", middleware.AuthMiddleware(), varianHandler.UpdateVarian)
+	product.Delete("/:id/varian/:varian_id", middleware.AuthMiddleware(), varianHandler.DeleteVarian)
+
+	// Rute publik (harga reseller hanya tampil untuk reseller, admin, dan pemilik toko)
+	product.Get("/", middleware.OptionalAuthMiddleware(), produkHandler.GetAllProduk)
+	product.Get("/:id", middleware.OptionalAuthMiddleware(), produkHandler.GetProdukByID)
 
 	// Rute untuk Transaksi (Perlu Autentikasi)
 	trx := api.Group("/trx", middleware.AuthMiddleware())
-	trx.Post("/", transaksiHandler.CreateTransaksi)
+	trx.Post("/", idempotencyMiddleware, transaksiHandler.CreateTransaksi) // Mendukung header Idempotency-Key
 	trx.Get("/", transaksiHandler.GetMyTransactions)
+	trx.Post("/quote", transaksiHandler.QuoteOngkir) // Didaftarkan sebelum /:id
+	trx.Get("/retur", returHandler.GetMyReturs) // Didaftarkan sebelum /:id
+	trx.Get("/retur/:id", returHandler.GetMyReturByID)
+	trx.Get("/items/:id/snapshot", transaksiHandler.GetItemSnapshot)
 	trx.Get("/:id", transaksiHandler.GetMyTransactionByID)
+	trx.Get("/:id/invoice.pdf", transaksiHandler.GetInvoicePDF)
+	trx.Put("/:id/status", transaksiHandler.UpdateStatusByBuyer)
+	trx.Post("/:id/cancel", transaksiHandler.CancelByBuyer)
+	trx.Post("/:id/retur", returHandler.CreateRetur)
+
+	// Rute untuk Admin (Perlu Token & Role Admin)
+	admin := api.Group("/admin", middleware.AuthMiddleware(), middleware.AdminMiddleware())
+	admin.Get("/reseller", resellerHandler.GetApplications)
+	admin.Put("/reseller/:id/approve", resellerHandler.Approve)
+	admin.Put("/reseller/:id/reject", resellerHandler.Reject)
+	admin.Get("/penarikan", ledgerHandler.GetAllPenarikan)
+	admin.Put("/penarikan/:id/approve", ledgerHandler.ApprovePenarikan)
+	admin.Put("/penarikan/:id/reject", ledgerHandler.RejectPenarikan)
+	admin.Post("/voucher", platformVoucherHandler.CreateVoucher)
+	admin.Get("/voucher", platformVoucherHandler.GetVouchers)
+	admin.Get("/voucher/:id", platformVoucherHandler.GetVoucherByID)
+	admin.Put("/voucher/:id", platformVoucherHandler.UpdateVoucher)
+	admin.Get("/voucher/:id/pemakaian", platformVoucherHandler.GetPemakaian)
+
+	// Rute untuk Pembayaran (publik, callback diverifikasi dengan signature)
+	payments := api.Group("/payments")
+	payments.Get("/methods", pembayaranHandler.GetMethods)
+	payments.Post("/callback/:provider", pembayaranHandler.Callback)
 
 
 }
\ No newline at end of file
@@ -45,6 +45,8 @@ func (s *alamatService) CreateAlamat(userID uint, request web.AlamatCreateReques
 		NamaPenerima: request.NamaPenerima,
 		NoTelp:       request.NoTelp,
 		DetailAlamat: request.DetailAlamat,
+		IDProvinsi:   request.IDProvinsi,
+		IDKota:       request.IDKota,
 		UserID:       userID, // Set pemilik alamat
 	}
 
@@ -95,6 +97,12 @@ func (s *alamatService) UpdateAlamat(userID uint, alamatID uint, request web.Ala
 	if request.DetailAlamat != "" {
 		alamat.DetailAlamat = request.DetailAlamat
 	}
+	if request.IDProvinsi != "" {
+		alamat.IDProvinsi = request.IDProvinsi
+	}
+	if request.IDKota != "" {
+		alamat.IDKota = request.IDKota
+	}
 
 	// 3. Simpan perubahan
 	updatedAlamat, err := s.alamatRepository.Update(alamat)
@@ -112,7 +120,16 @@ func (s *alamatService) DeleteAlamat(userID uint, alamatID uint) error {
 		return err
 	}
 
-	// 2. Hapus alamat
+	// 2. Alamat yang masih dipakai pesanan berjalan tidak boleh dihapus
+	total, err := s.alamatRepository.CountOpenOrders(alamatID)
+	if err != nil {
+		return err
+	}
+	if total > 0 {
+		return errors.New("Penghapusan alamat tidak diizinkan: masih dipakai pesanan yang belum selesai")
+	}
+
+	// 3. Hapus alamat
 	err = s.alamatRepository.Delete(alamatID)
 	if err != nil {
 		return err
@@ -0,0 +1,130 @@
+package service_test
+
+import (
+	"github.com/Debjth19/go-evermos/model"
+	"github.com/Debjth19/go-evermos/payment"
+
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// providerPenghitung mencatat berapa kali tagihan dibuat untuk setiap kode invoice
+type providerPenghitung struct {
+	*payment.FakeProvider
+	mu       sync.Mutex
+	tagihan  map[string]int
+	gagalkan bool // CreatePayment selalu gagal
+}
+
+func newProviderPenghitung() *providerPenghitung {
+	return &providerPenghitung{FakeProvider: payment.NewFakeProvider("rahasia-hitung"), tagihan: map[string]int{}}
+}
+
+func (p *providerPenghitung) Name() string {
+	return "hitung"
+}
+
+func (p *providerPenghitung) Methods() []string {
+	return []string{"qris"}
+}
+
+func (p *providerPenghitung) CreatePayment(request payment.ChargeRequest) (payment.ChargeResult, error) {
+	if p.gagalkan {
+		return payment.ChargeResult{}, errors.New("provider tidak tersedia")
+	}
+	p.mu.Lock()
+	p.tagihan[request.KodeInvoice]++
+	p.mu.Unlock()
+	return p.FakeProvider.CreatePayment(request)
+}
+
+func TestCheckoutBersamaanTidakOversell(t *testing.T) {
+	const stok, pembeli = 3, 10
+	provider := newProviderPenghitung()
+	f := newFixture(t, stok, provider)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var berhasil []model.Transaksi
+	var gagal []error
+	mulai := make(chan struct{})
+	for i := 0; i < pembeli; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-mulai
+			transaksi, err := f.checkout("qris", 1)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				gagal = append(gagal, err)
+				return
+			}
+			berhasil = append(berhasil, transaksi)
+		}()
+	}
+	close(mulai)
+	wg.Wait()
+
+	if len(berhasil) != stok {
+		t.Fatalf("checkout berhasil = %d, ingin %d (error: %v)", len(berhasil), stok, gagal)
+	}
+	for _, err := range gagal {
+		if !strings.Contains(err.Error(), "Stok tidak mencukupi") {
+			t.Fatalf("checkout gagal bukan karena stok habis: %v", err)
+		}
+	}
+
+	var produk model.Produk
+	if err := f.db.First(&produk, f.produk.ID).Error; err != nil {
+		t.Fatalf("gagal membaca produk: %v", err)
+	}
+	if produk.Stok != 0 {
+		t.Fatalf("stok akhir = %d, ingin 0", produk.Stok)
+	}
+
+	// Setiap pesanan ditagih tepat sekali, termasuk bila checkout-nya diulang karena deadlock
+	if len(provider.tagihan) != stok {
+		t.Fatalf("tagihan dibuat untuk %d invoice, ingin %d", len(provider.tagihan), stok)
+	}
+	for _, transaksi := range berhasil {
+		if n := provider.tagihan[transaksi.KodeInvoice]; n != 1 {
+			t.Fatalf("invoice %s ditagih %d kali, ingin 1", transaksi.KodeInvoice, n)
+		}
+		if transaksi.Pembayaran == nil || transaksi.Pembayaran.Reference != "FAKE-"+transaksi.KodeInvoice {
+			t.Fatalf("referensi tagihan invoice %s tidak tersimpan", transaksi.KodeInvoice)
+		}
+	}
+}
+
+func TestCheckoutDibatalkanSaatTagihanGagal(t *testing.T) {
+	provider := newProviderPenghitung()
+	provider.gagalkan = true
+	f := newFixture(t, 5, provider)
+
+	if _, err := f.checkout("qris", 2); err == nil || !strings.Contains(err.Error(), "Gagal membuat tagihan pembayaran") {
+		t.Fatalf("error = %v, ingin gagal membuat tagihan", err)
+	}
+
+	var produk model.Produk
+	if err := f.db.First(&produk, f.produk.ID).Error; err != nil {
+		t.Fatalf("gagal membaca produk: %v", err)
+	}
+	if produk.Stok != 5 {
+		t.Fatalf("stok = %d, ingin kembali 5", produk.Stok)
+	}
+
+	var transaksi model.Transaksi
+	if err := f.db.Where("parent_id IS NULL").First(&transaksi).Error; err != nil {
+		t.Fatalf("gagal membaca transaksi: %v", err)
+	}
+	var pembayaran model.Pembayaran
+	if err := f.db.Where("transaksi_id = ?", transaksi.ID).First(&pembayaran).Error; err != nil {
+		t.Fatalf("gagal membaca pembayaran: %v", err)
+	}
+	if transaksi.Status != model.StatusCancelled || pembayaran.Status != model.PembayaranFailed {
+		t.Fatalf("transaksi %s / pembayaran %s, ingin cancelled / failed", transaksi.Status, pembayaran.Status)
+	}
+}
@@ -0,0 +1,140 @@
+package service_test
+
+import (
+	"github.com/Debjth19/go-evermos/config"
+	"github.com/Debjth19/go-evermos/database"
+	"github.com/Debjth19/go-evermos/model"
+	"github.com/Debjth19/go-evermos/model/web"
+	"github.com/Debjth19/go-evermos/payment"
+	"github.com/Debjth19/go-evermos/repository"
+	"github.com/Debjth19/go-evermos/service"
+	"github.com/Debjth19/go-evermos/shipping"
+
+	"os"
+	"testing"
+	"time"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Secret provider fake pada pengujian
+const secretUji = "rahasia-uji"
+
+// openTestDB membuka database MySQL khusus pengujian dari env TEST_DATABASE_DSN, menjalankan migrasi,
+// lalu mengosongkan seluruh tabel. Pengujian dilewati bila env tersebut tidak diisi.
+// Contoh: TEST_DATABASE_DSN="root:root@tcp(localhost:3306)/evermos_test?charset=utf8mb4&parseTime=True&loc=Local"
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN tidak diisi, pengujian dengan MySQL dilewati")
+	}
+
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("gagal membuka database uji: %v", err)
+	}
+	config.DB = db
+	database.MigrateDatabase()
+
+	tables, err := db.Migrator().GetTables()
+	if err != nil {
+		t.Fatalf("gagal membaca tabel: %v", err)
+	}
+	// Satu koneksi agar FOREIGN_KEY_CHECKS berlaku untuk setiap TRUNCATE
+	err = db.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SET FOREIGN_KEY_CHECKS = 0").Error; err != nil {
+			return err
+		}
+		for _, table := range tables {
+			if err := conn.Exec("TRUNCATE TABLE `" + table + "`").Error; err != nil {
+				return err
+			}
+		}
+		return conn.Exec("SET FOREIGN_KEY_CHECKS = 1").Error
+	})
+	if err != nil {
+		t.Fatalf("gagal mengosongkan tabel: %v", err)
+	}
+	return db
+}
+
+// fixture berisi service yang dirakit seperti main.go beserta data awal:
+// satu penjual dengan satu produk, dan satu pembeli dengan satu alamat di kota yang sama
+type fixture struct {
+	db                *gorm.DB
+	fake              *payment.FakeProvider
+	transaksiService  service.TransaksiService
+	pembayaranService service.PembayaranService
+	pembeli           model.User
+	alamat            model.Alamat
+	toko              model.Toko
+	produk            model.Produk
+}
+
+// newFixture menyiapkan fixture dengan stok produk 'stok'. Provider tambahan didaftarkan
+// setelah provider fake dan cod.
+func newFixture(t *testing.T, stok uint, providers ...payment.PaymentProvider) *fixture {
+	t.Helper()
+	db := openTestDB(t)
+	f := &fixture{db: db, fake: payment.NewFakeProvider(secretUji)}
+
+	lahir := time.Date(1995, 1, 1, 0, 0, 0, 0, time.Local)
+	penjual := model.User{Nama: "Penjual", Email: "penjual@uji.id", NoTelp: "0811", TanggalLahir: lahir, IDProvinsi: "31", IDKota: "3171"}
+	f.pembeli = model.User{Nama: "Pembeli", Email: "pembeli@uji.id", NoTelp: "0812", TanggalLahir: lahir, IDProvinsi: "31", IDKota: "3171"}
+	kategori := model.Kategori{NamaCategory: "Pakaian"}
+	for _, data := range []interface{}{&penjual, &f.pembeli, &kategori} {
+		if err := db.Create(data).Error; err != nil {
+			t.Fatalf("gagal menyiapkan data: %v", err)
+		}
+	}
+
+	f.toko = model.Toko{NamaToko: "Toko Uji", UserID: penjual.ID, IDProvinsi: "31", IDKota: "3171"}
+	if err := db.Create(&f.toko).Error; err != nil {
+		t.Fatalf("gagal menyiapkan toko: %v", err)
+	}
+	f.produk = model.Produk{
+		NamaProduk:    "Kemeja",
+		Slug:          "kemeja",
+		HargaReseler:  8000,
+		HargaKonsumen: 10000,
+		Stok:          stok,
+		Berat:         500,
+		TokoID:        f.toko.ID,
+		CategoryID:    kategori.ID,
+	}
+	f.alamat = model.Alamat{JudulAlamat: "Rumah", NamaPenerima: "Pembeli", NoTelp: "0812", DetailAlamat: "Jl. Uji 1", IDProvinsi: "31", IDKota: "3171", UserID: f.pembeli.ID}
+	for _, data := range []interface{}{&f.produk, &f.alamat} {
+		if err := db.Create(data).Error; err != nil {
+			t.Fatalf("gagal menyiapkan data: %v", err)
+		}
+	}
+
+	// Rakit service seperti main.go
+	transaksiRepository := repository.NewTransaksiRepository(db)
+	produkRepository := repository.NewProdukRepository(db)
+	kategoriRepository := repository.NewKategoriRepository(db)
+	tokoRepository := repository.NewTokoRepository(db)
+	paymentRegistry := payment.NewRegistry(append([]payment.PaymentProvider{f.fake, payment.NewCODProvider()}, providers...)...)
+	ledgerService := service.NewLedgerService(db, repository.NewLedgerRepository(db), transaksiRepository, tokoRepository)
+	voucherService := service.NewVoucherService(repository.NewVoucherRepository(db), kategoriRepository, tokoRepository)
+	f.transaksiService = service.NewTransaksiService(db, transaksiRepository, produkRepository, kategoriRepository,
+		repository.NewAlamatRepository(db), tokoRepository, repository.NewPembayaranRepository(db),
+		repository.NewInvoiceRepository(db), repository.NewUserRepository(db), ledgerService, voucherService,
+		paymentRegistry, shipping.NewLocalRateProvider(shipping.DefaultLocalRates))
+	f.pembayaranService = service.NewPembayaranService(paymentRegistry, f.transaksiService)
+	return f
+}
+
+// checkout membuat pesanan pembeli untuk produk fixture, opsional dengan kode voucher
+func (f *fixture) checkout(methodBayar string, kuantitas uint, kodeVoucher ...string) (model.Transaksi, error) {
+	return f.transaksiService.CreateTransaksi(f.pembeli.ID, web.TransaksiCreateRequest{
+		MethodBayar: methodBayar,
+		AlamatKirim: f.alamat.ID,
+		DetailTrx:   []web.DetailTransaksiRequest{{ProductID: f.produk.ID, Kuantitas: kuantitas}},
+		Pengiriman:  []web.PengirimanRequest{{TokoID: f.toko.ID, Kode: "jne_reg"}},
+		KodeVoucher: kodeVoucher,
+	})
+}
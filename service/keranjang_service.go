@@ -0,0 +1,267 @@
+package service
+
+import (
+	"github.com/Debjth19/go-evermos/helpers"
+	"github.com/Debjth19/go-evermos/model"
+	"github.com/Debjth19/go-evermos/model/web"
+	"github.com/Debjth19/go-evermos/repository"
+
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// KeranjangBaris adalah item keranjang beserta hasil validasinya terhadap produk terkini
+type KeranjangBaris struct {
+	Item         model.KeranjangItem
+	Varian       model.VarianProduk // Varian yang dipilih, kosong bila produk tanpa varian
+	TierHarga    string
+	HargaSatuan  uint // Harga per unit saat ini sesuai tier pembeli
+	Stok         uint // Stok produk atau varian yang dipilih
+	StokCukup    bool
+	HargaBerubah bool // Harga saat ini berbeda dengan saat item ditambahkan
+	VarianWajib  bool // Produk kini memiliki varian, item tanpa varian tidak dapat di-checkout
+}
+
+type KeranjangService interface {
+	GetKeranjang(userID uint) ([]KeranjangBaris, error)
+	AddItem(userID uint, request web.KeranjangAddRequest) (model.KeranjangItem, error)
+	UpdateItem(userID uint, itemID uint, request web.KeranjangUpdateRequest) (model.KeranjangItem, error)
+	RemoveItem(userID uint, itemID uint) error
+	Checkout(userID uint, request web.KeranjangCheckoutRequest) (model.Transaksi, error)
+}
+
+type keranjangService struct {
+	keranjangRepository repository.KeranjangRepository
+	produkRepository    repository.ProdukRepository
+	userRepository      repository.UserRepository // Dibutuhkan untuk menentukan tier harga pembeli
+	transaksiService    TransaksiService          // Dibutuhkan untuk membuat transaksi saat checkout
+}
+
+func NewKeranjangService(keranjangRepo repository.KeranjangRepository, produkRepo repository.ProdukRepository, userRepo repository.UserRepository, transaksiService TransaksiService) KeranjangService {
+	return &keranjangService{
+		keranjangRepository: keranjangRepo,
+		produkRepository:    produkRepo,
+		userRepository:      userRepo,
+		transaksiService:    transaksiService,
+	}
+}
+
+// GetKeranjang mengambil isi keranjang dan memvalidasi stok serta harga setiap item
+func (s *keranjangService) GetKeranjang(userID uint) ([]KeranjangBaris, error) {
+	isReseller, err := s.isReseller(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.keranjangRepository.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var baris []KeranjangBaris
+	for _, item := range items {
+		produk, varian := produkItem(item)
+		varianWajib := item.VarianID == 0 && len(item.Produk.Varian) > 0
+		tier, harga := produk.HargaTier(isReseller)
+		baris = append(baris, KeranjangBaris{
+			Item:         item,
+			Varian:       varian,
+			TierHarga:    tier,
+			HargaSatuan:  harga,
+			Stok:         produk.Stok,
+			StokCukup:    produk.Stok >= item.Kuantitas && !varianWajib,
+			HargaBerubah: harga != item.HargaSatuan,
+			VarianWajib:  varianWajib,
+		})
+	}
+	return baris, nil
+}
+
+// AddItem menambahkan produk ke keranjang, atau menambah kuantitasnya bila sudah ada
+func (s *keranjangService) AddItem(userID uint, request web.KeranjangAddRequest) (model.KeranjangItem, error) {
+	if request.ProductID == 0 || request.Kuantitas == 0 {
+		return model.KeranjangItem{}, helpers.Errorf(helpers.ErrTidakValid, "Produk dan kuantitas wajib diisi")
+	}
+
+	item, err := s.keranjangRepository.FindByUserAndProduk(userID, request.ProductID, request.VarianID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return item, err
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		item = model.KeranjangItem{UserID: userID, ProductID: request.ProductID, VarianID: request.VarianID}
+	}
+	item.Kuantitas += request.Kuantitas
+
+	if err := s.hargaTerkini(userID, &item); err != nil {
+		return item, err
+	}
+
+	if item.ID == 0 {
+		err = s.keranjangRepository.Create(&item)
+	} else {
+		err = s.keranjangRepository.Update(&item)
+	}
+	if err != nil {
+		return item, errors.New("Gagal menyimpan item keranjang")
+	}
+	return item, nil
+}
+
+// UpdateItem mengganti kuantitas sebuah item keranjang
+func (s *keranjangService) UpdateItem(userID uint, itemID uint, request web.KeranjangUpdateRequest) (model.KeranjangItem, error) {
+	if request.Kuantitas == 0 {
+		return model.KeranjangItem{}, helpers.Errorf(helpers.ErrTidakValid, "Kuantitas wajib diisi")
+	}
+
+	item, err := s.findOwned(userID, itemID)
+	if err != nil {
+		return item, err
+	}
+	item.Kuantitas = request.Kuantitas
+
+	if err := s.hargaTerkini(userID, &item); err != nil {
+		return item, err
+	}
+	if err := s.keranjangRepository.Update(&item); err != nil {
+		return item, errors.New("Gagal menyimpan item keranjang")
+	}
+	return item, nil
+}
+
+// RemoveItem menghapus sebuah item dari keranjang
+func (s *keranjangService) RemoveItem(userID uint, itemID uint) error {
+	if _, err := s.findOwned(userID, itemID); err != nil {
+		return err
+	}
+	return s.keranjangRepository.Delete(itemID)
+}
+
+// Checkout membuat transaksi dari item keranjang yang dipilih. Item tersebut dihapus
+// dari keranjang di dalam DB transaction yang sama, sehingga checkout yang gagal tidak
+// mengubah keranjang dan item yang sama tidak dapat di-checkout dua kali.
+func (s *keranjangService) Checkout(userID uint, request web.KeranjangCheckoutRequest) (model.Transaksi, error) {
+	itemIDs := uniqueIDs(request.ItemIDs)
+	if len(itemIDs) == 0 {
+		return model.Transaksi{}, helpers.Errorf(helpers.ErrTidakValid, "Item keranjang yang di-checkout wajib diisi")
+	}
+
+	items, err := s.keranjangRepository.FindByIDs(userID, itemIDs)
+	if err != nil {
+		return model.Transaksi{}, err
+	}
+	if len(items) != len(itemIDs) {
+		return model.Transaksi{}, helpers.Errorf(helpers.ErrTidakDitemukan, "Item keranjang tidak ditemukan atau bukan milik Anda")
+	}
+
+	var detailTrx []web.DetailTransaksiRequest
+	for _, item := range items {
+		// Harga yang terakhir dilihat pembeli di keranjang, checkout ditolak bila sudah berubah
+		hargaSatuan := item.HargaSatuan
+		detailTrx = append(detailTrx, web.DetailTransaksiRequest{
+			ProductID:   item.ProductID,
+			VarianID:    item.VarianID,
+			Kuantitas:   item.Kuantitas,
+			HargaSatuan: &hargaSatuan,
+		})
+	}
+
+	trxRequest := web.TransaksiCreateRequest{
+		MethodBayar: request.MethodBayar,
+		AlamatKirim: request.AlamatKirim,
+		DetailTrx:   detailTrx,
+		Pengiriman:  request.Pengiriman,
+		KodeVoucher: request.KodeVoucher,
+	}
+	return s.transaksiService.CreateTransaksiWith(userID, trxRequest, func(tx *gorm.DB) error {
+		deleted, err := s.keranjangRepository.DeleteByIDs(tx, userID, itemIDs)
+		if err != nil {
+			return errors.New("Gagal mengosongkan keranjang")
+		}
+		// Item yang sudah terhapus berarti sudah di-checkout oleh request lain
+		if deleted != int64(len(itemIDs)) {
+			return helpers.Errorf(helpers.ErrTidakDitemukan, "Item keranjang tidak ditemukan: sudah di-checkout atau dihapus")
+		}
+		return nil
+	})
+}
+
+// hargaTerkini memvalidasi stok produk atau varian dan mencatat harga per unit saat ini pada item
+func (s *keranjangService) hargaTerkini(userID uint, item *model.KeranjangItem) error {
+	produk, err := s.produkRepository.FindByID(item.ProductID)
+	if err != nil {
+		return helpers.Errorf(helpers.ErrTidakValid, "Produk dengan ID %d tidak ditemukan", item.ProductID)
+	}
+
+	nama := produk.NamaProduk
+	if item.VarianID != 0 {
+		varian, ok := produk.CariVarian(item.VarianID)
+		if !ok {
+			return helpers.Errorf(helpers.ErrTidakValid, "Varian dengan ID %d tidak ditemukan pada produk %s", item.VarianID, produk.NamaProduk)
+		}
+		nama = namaItem(produk, varian)
+		produk = produk.DenganVarian(varian)
+	} else if len(produk.Varian) > 0 {
+		return helpers.Errorf(helpers.ErrTidakValid, "Varian wajib diisi untuk produk: %s", produk.NamaProduk)
+	}
+
+	if produk.Stok < item.Kuantitas {
+		return helpers.Errorf(helpers.ErrTidakValid, "Stok tidak mencukupi untuk produk: %s (tersisa %d)", nama, produk.Stok)
+	}
+
+	isReseller, err := s.isReseller(userID)
+	if err != nil {
+		return err
+	}
+	_, item.HargaSatuan = produk.HargaTier(isReseller)
+	return nil
+}
+
+// produkItem mengembalikan produk dengan harga dan stok varian yang dipilih item.
+// Item tanpa varian pada produk yang kini bervarian dianggap tanpa stok.
+func produkItem(item model.KeranjangItem) (model.Produk, model.VarianProduk) {
+	produk := item.Produk
+	if item.VarianID == 0 {
+		if len(produk.Varian) > 0 {
+			produk.Stok = 0
+		}
+		return produk, model.VarianProduk{}
+	}
+
+	varian, ok := produk.CariVarian(item.VarianID)
+	if !ok {
+		produk.Stok = 0
+		return produk, varian
+	}
+	return produk.DenganVarian(varian), varian
+}
+
+func (s *keranjangService) isReseller(userID uint) (bool, error) {
+	user, err := s.userRepository.FindByID(userID)
+	if err != nil {
+		return false, helpers.Errorf(helpers.ErrTidakDitemukan, "User tidak ditemukan")
+	}
+	return user.Role == model.RoleReseller, nil
+}
+
+func (s *keranjangService) findOwned(userID uint, itemID uint) (model.KeranjangItem, error) {
+	item, err := s.keranjangRepository.FindByID(itemID)
+	if err != nil || item.UserID != userID {
+		return model.KeranjangItem{}, helpers.Errorf(helpers.ErrTidakDitemukan, "Item keranjang tidak ditemukan atau bukan milik Anda")
+	}
+	return item, nil
+}
+
+// uniqueIDs membuang ID yang terkirim lebih dari sekali
+func uniqueIDs(ids []uint) []uint {
+	seen := map[uint]bool{}
+	var hasil []uint
+	for _, id := range ids {
+		if id == 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		hasil = append(hasil, id)
+	}
+	return hasil
+}
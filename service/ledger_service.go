@@ -0,0 +1,355 @@
+package service
+
+import (
+	"github.com/Debjth19/go-evermos/helpers"
+	"github.com/Debjth19/go-evermos/model"
+	"github.com/Debjth19/go-evermos/model/web"
+	"github.com/Debjth19/go-evermos/repository"
+
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type LedgerService interface {
+	// Dipanggil oleh service lain di dalam DB transaction yang sedang berjalan
+	PostPenjualan(tx *gorm.DB, transaksi model.Transaksi) error
+	PostRefund(tx *gorm.DB, retur model.Retur) error
+
+	GetSaldo(userID uint) (web.SaldoResponse, error)
+	GetRiwayatSaldo(userID uint, pagination helpers.Pagination) ([]model.LedgerEntry, int64, error)
+	CreatePenarikan(userID uint, request web.PenarikanCreateRequest) (model.Penarikan, error)
+	GetMyPenarikan(userID uint, pagination helpers.Pagination) ([]model.Penarikan, error)
+	GetAllPenarikan(pagination helpers.Pagination, status string) ([]model.Penarikan, error)
+	ApprovePenarikan(adminID uint, penarikanID uint, request web.PenarikanDecisionRequest) (model.Penarikan, error)
+	RejectPenarikan(adminID uint, penarikanID uint, request web.PenarikanDecisionRequest) (model.Penarikan, error)
+}
+
+type ledgerService struct {
+	db                  *gorm.DB // Dibutuhkan untuk memulai transaction
+	ledgerRepository    repository.LedgerRepository
+	transaksiRepository repository.TransaksiRepository // Dibutuhkan untuk membaca baris pesanan yang selesai
+	tokoRepository      repository.TokoRepository      // Dibutuhkan untuk otorisasi pemilik toko
+}
+
+func NewLedgerService(db *gorm.DB, ledgerRepo repository.LedgerRepository, trxRepo repository.TransaksiRepository, tokoRepo repository.TokoRepository) LedgerService {
+	return &ledgerService{
+		db:                  db,
+		ledgerRepository:    ledgerRepo,
+		transaksiRepository: trxRepo,
+		tokoRepository:      tokoRepo,
+	}
+}
+
+// PostPenjualan mencatat hak toko atas sub-transaksi yang selesai:
+// kas platform didebit sebesar harga item dan ongkos kirim, saldo toko dikredit setelah
+// dipotong biaya platform, dan biaya tersebut dikredit ke pendapatan platform.
+// Ongkos kirim diteruskan utuh ke toko yang mengirim paket. Diskon voucher toko mengurangi
+// hak toko, sedangkan diskon voucher platform dicatat sebagai biaya promosi platform.
+func (s *ledgerService) PostPenjualan(tx *gorm.DB, transaksi model.Transaksi) error {
+	details, err := s.transaksiRepository.FindDetailsByTransaksiID(tx, transaksi.ID)
+	if err != nil {
+		return errors.New("Gagal mengambil detail transaksi")
+	}
+
+	feeBps := helpers.PlatformFeeBps()
+	jurnal := model.Jurnal{
+		Referensi:  fmt.Sprintf("penjualan:%d", transaksi.ID),
+		Keterangan: "Penjualan " + transaksi.KodeInvoice,
+		FeeBps:     feeBps,
+	}
+
+	var bruto, totalFee, totalPromosi uint
+	for _, detail := range details {
+		nilaiToko := detail.HargaTotal - detail.DiskonToko
+		fee := helpers.HitungBiayaPlatform(nilaiToko, feeBps)
+		bruto += nilaiToko - detail.DiskonPlatform // Yang benar-benar dibayar pembeli
+		totalFee += fee
+		totalPromosi += detail.DiskonPlatform
+		jurnal.Entries = append(jurnal.Entries, model.LedgerEntry{
+			Akun:       model.AkunSaldoToko,
+			TokoID:     detail.TokoID,
+			Kredit:     nilaiToko - fee,
+			Keterangan: fmt.Sprintf("Penjualan %s item #%d", transaksi.KodeInvoice, detail.ID),
+		})
+	}
+	if transaksi.OngkosKirim > 0 {
+		bruto += transaksi.OngkosKirim
+		jurnal.Entries = append(jurnal.Entries, model.LedgerEntry{
+			Akun:       model.AkunSaldoToko,
+			TokoID:     transaksi.TokoID,
+			Kredit:     transaksi.OngkosKirim,
+			Keterangan: "Ongkos kirim " + transaksi.KodeInvoice,
+		})
+	}
+	jurnal.Entries = append(jurnal.Entries,
+		model.LedgerEntry{Akun: model.AkunKasPlatform, Debit: bruto, Keterangan: "Dana pesanan " + transaksi.KodeInvoice},
+		model.LedgerEntry{Akun: model.AkunPromosiPlatform, Debit: totalPromosi, Keterangan: "Voucher platform " + transaksi.KodeInvoice},
+		model.LedgerEntry{Akun: model.AkunPendapatanPlatform, Kredit: totalFee, Keterangan: "Biaya platform " + transaksi.KodeInvoice},
+	)
+
+	return s.postJurnal(tx, &jurnal)
+}
+
+// PostRefund membalik bagian penjualan yang dikembalikan pembeli:
+// saldo toko dan pendapatan platform didebit, kas platform dikredit sebesar refund,
+// dan bagian diskon voucher platform pada item tersebut dikembalikan ke akun promosi.
+func (s *ledgerService) PostRefund(tx *gorm.DB, retur model.Retur) error {
+	// Biaya platform mengikuti tarif saat penjualan dicatat, bila sudah ada
+	feeBps := helpers.PlatformFeeBps()
+	penjualan, err := s.ledgerRepository.FindJurnalByReferensi(tx, fmt.Sprintf("penjualan:%d", retur.TransaksiID))
+	if err == nil {
+		feeBps = penjualan.FeeBps
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	jurnal := model.Jurnal{
+		Referensi:  fmt.Sprintf("refund:%d", retur.ID),
+		Keterangan: fmt.Sprintf("Refund retur #%d", retur.ID),
+		FeeBps:     feeBps,
+	}
+
+	details, err := s.transaksiRepository.FindDetailsByTransaksiID(tx, retur.TransaksiID)
+	if err != nil {
+		return errors.New("Gagal mengambil detail transaksi")
+	}
+	detailByID := map[uint]model.DetailTransaksi{}
+	for _, detail := range details {
+		detailByID[detail.ID] = detail
+	}
+
+	var total, totalFee, totalPromosi uint
+	for _, item := range retur.Items {
+		nilaiToko, diskonPlatform := detailByID[item.DetailTransaksiID].BagianRefund(item.Kuantitas)
+		fee := helpers.HitungBiayaPlatform(nilaiToko, feeBps)
+		total += nilaiToko - diskonPlatform
+		totalFee += fee
+		totalPromosi += diskonPlatform
+		jurnal.Entries = append(jurnal.Entries, model.LedgerEntry{
+			Akun:       model.AkunSaldoToko,
+			TokoID:     retur.TokoID,
+			Debit:      nilaiToko - fee,
+			Keterangan: fmt.Sprintf("Refund retur #%d item #%d", retur.ID, item.DetailTransaksiID),
+		})
+	}
+	jurnal.Entries = append(jurnal.Entries,
+		model.LedgerEntry{Akun: model.AkunPendapatanPlatform, Debit: totalFee, Keterangan: fmt.Sprintf("Pembatalan biaya platform retur #%d", retur.ID)},
+		model.LedgerEntry{Akun: model.AkunKasPlatform, Kredit: total, Keterangan: fmt.Sprintf("Refund ke pembeli retur #%d", retur.ID)},
+		model.LedgerEntry{Akun: model.AkunPromosiPlatform, Kredit: totalPromosi, Keterangan: fmt.Sprintf("Pembatalan voucher platform retur #%d", retur.ID)},
+	)
+
+	return s.postJurnal(tx, &jurnal)
+}
+
+// postJurnal memastikan jurnal seimbang lalu menyimpannya. Entry bernilai nol tidak disimpan.
+func (s *ledgerService) postJurnal(tx *gorm.DB, jurnal *model.Jurnal) error {
+	var entries []model.LedgerEntry
+	var debit, kredit uint
+	for _, entry := range jurnal.Entries {
+		if entry.Debit == 0 && entry.Kredit == 0 {
+			continue
+		}
+		debit += entry.Debit
+		kredit += entry.Kredit
+		entries = append(entries, entry)
+	}
+	if debit != kredit {
+		return fmt.Errorf("Jurnal %s tidak seimbang: debit %d, kredit %d", jurnal.Referensi, debit, kredit)
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	jurnal.Entries = entries
+	if err := s.ledgerRepository.CreateJurnal(tx, jurnal); err != nil {
+		return errors.New("Gagal mencatat jurnal " + jurnal.Referensi)
+	}
+	return nil
+}
+
+// GetSaldo menghitung saldo toko milik user dari ledger
+func (s *ledgerService) GetSaldo(userID uint) (web.SaldoResponse, error) {
+	toko, err := s.tokoRepository.FindByUserID(userID)
+	if err != nil {
+		return web.SaldoResponse{}, helpers.Errorf(helpers.ErrTidakDitemukan, "Toko Anda tidak ditemukan")
+	}
+
+	saldo, err := s.ledgerRepository.SaldoToko(s.db, toko.ID)
+	if err != nil {
+		return web.SaldoResponse{}, err
+	}
+	ditahan, err := s.ledgerRepository.SumPenarikanPending(s.db, toko.ID)
+	if err != nil {
+		return web.SaldoResponse{}, err
+	}
+
+	return web.SaldoResponse{
+		Saldo:         saldo,
+		SaldoDitahan:  ditahan,
+		SaldoTersedia: saldo - ditahan,
+	}, nil
+}
+
+// GetRiwayatSaldo mengambil mutasi saldo toko milik user
+func (s *ledgerService) GetRiwayatSaldo(userID uint, pagination helpers.Pagination) ([]model.LedgerEntry, int64, error) {
+	toko, err := s.tokoRepository.FindByUserID(userID)
+	if err != nil {
+		return nil, 0, helpers.Errorf(helpers.ErrTidakDitemukan, "Toko Anda tidak ditemukan")
+	}
+	return s.ledgerRepository.FindEntriesByToko(toko.ID, pagination)
+}
+
+// CreatePenarikan membuat permintaan penarikan selama saldo tersedia mencukupi
+func (s *ledgerService) CreatePenarikan(userID uint, request web.PenarikanCreateRequest) (model.Penarikan, error) {
+	if request.Jumlah == 0 {
+		return model.Penarikan{}, helpers.Errorf(helpers.ErrTidakValid, "Jumlah penarikan tidak valid")
+	}
+	if request.NamaBank == "" || request.NoRekening == "" || request.AtasNama == "" {
+		return model.Penarikan{}, helpers.Errorf(helpers.ErrTidakValid, "Data rekening wajib diisi")
+	}
+
+	toko, err := s.tokoRepository.FindByUserID(userID)
+	if err != nil {
+		return model.Penarikan{}, helpers.Errorf(helpers.ErrTidakDitemukan, "Toko Anda tidak ditemukan")
+	}
+
+	var penarikan model.Penarikan
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Kunci toko agar dua penarikan bersamaan tidak melebihi saldo
+		if err := s.ledgerRepository.LockToko(tx, toko.ID); err != nil {
+			return err
+		}
+		if err := s.cekSaldoTersedia(tx, toko.ID, request.Jumlah); err != nil {
+			return err
+		}
+
+		penarikan = model.Penarikan{
+			TokoID:     toko.ID,
+			Jumlah:     request.Jumlah,
+			NamaBank:   request.NamaBank,
+			NoRekening: request.NoRekening,
+			AtasNama:   request.AtasNama,
+			Status:     model.PenarikanPending,
+		}
+		if err := s.ledgerRepository.CreatePenarikan(tx, &penarikan); err != nil {
+			return errors.New("Gagal menyimpan penarikan")
+		}
+		return nil
+	})
+	if err != nil {
+		return model.Penarikan{}, err
+	}
+
+	return s.ledgerRepository.FindPenarikanByID(penarikan.ID)
+}
+
+// cekSaldoTersedia memastikan saldo toko dikurangi penarikan yang tertunda cukup untuk jumlah baru
+func (s *ledgerService) cekSaldoTersedia(tx *gorm.DB, tokoID uint, jumlah uint) error {
+	saldo, err := s.ledgerRepository.SaldoToko(tx, tokoID)
+	if err != nil {
+		return err
+	}
+	ditahan, err := s.ledgerRepository.SumPenarikanPending(tx, tokoID)
+	if err != nil {
+		return err
+	}
+	if tersedia := saldo - ditahan; int64(jumlah) > tersedia {
+		return helpers.Errorf(helpers.ErrTidakDiizinkan, "Penarikan tidak diizinkan: saldo tersedia %d tidak mencukupi", tersedia)
+	}
+	return nil
+}
+
+// GetMyPenarikan mengambil riwayat penarikan toko milik user
+func (s *ledgerService) GetMyPenarikan(userID uint, pagination helpers.Pagination) ([]model.Penarikan, error) {
+	toko, err := s.tokoRepository.FindByUserID(userID)
+	if err != nil {
+		return nil, helpers.Errorf(helpers.ErrTidakDitemukan, "Toko Anda tidak ditemukan")
+	}
+	return s.ledgerRepository.FindPenarikanByToko(toko.ID, pagination)
+}
+
+// GetAllPenarikan mengambil daftar penarikan untuk admin
+func (s *ledgerService) GetAllPenarikan(pagination helpers.Pagination, status string) ([]model.Penarikan, error) {
+	if status != "" && status != model.PenarikanPending && status != model.PenarikanApproved && status != model.PenarikanRejected {
+		return nil, helpers.Errorf(helpers.ErrTidakValid, "Status penarikan tidak valid: %s", status)
+	}
+	return s.ledgerRepository.FindAllPenarikan(pagination, status)
+}
+
+// ApprovePenarikan menyetujui penarikan dan mendebit saldo toko
+func (s *ledgerService) ApprovePenarikan(adminID uint, penarikanID uint, request web.PenarikanDecisionRequest) (model.Penarikan, error) {
+	return s.decidePenarikan(adminID, penarikanID, func(tx *gorm.DB, penarikan *model.Penarikan) error {
+		if err := s.ledgerRepository.LockToko(tx, penarikan.TokoID); err != nil {
+			return err
+		}
+
+		// Saldo bisa berkurang karena refund setelah penarikan diajukan
+		saldo, err := s.ledgerRepository.SaldoToko(tx, penarikan.TokoID)
+		if err != nil {
+			return err
+		}
+		if int64(penarikan.Jumlah) > saldo {
+			return helpers.Errorf(helpers.ErrTidakDiizinkan, "Persetujuan tidak diizinkan: saldo toko %d tidak mencukupi", saldo)
+		}
+
+		penarikan.Status = model.PenarikanApproved
+		penarikan.CatatanAdmin = request.Catatan
+		return s.postJurnal(tx, &model.Jurnal{
+			Referensi:  fmt.Sprintf("penarikan:%d", penarikan.ID),
+			Keterangan: fmt.Sprintf("Penarikan #%d ke %s %s", penarikan.ID, penarikan.NamaBank, penarikan.NoRekening),
+			Entries: []model.LedgerEntry{
+				{Akun: model.AkunSaldoToko, TokoID: penarikan.TokoID, Debit: penarikan.Jumlah, Keterangan: fmt.Sprintf("Penarikan #%d", penarikan.ID)},
+				{Akun: model.AkunKasPlatform, Kredit: penarikan.Jumlah, Keterangan: fmt.Sprintf("Pencairan penarikan #%d", penarikan.ID)},
+			},
+		})
+	})
+}
+
+// RejectPenarikan menolak penarikan dengan catatan dari admin
+func (s *ledgerService) RejectPenarikan(adminID uint, penarikanID uint, request web.PenarikanDecisionRequest) (model.Penarikan, error) {
+	if request.Catatan == "" {
+		return model.Penarikan{}, helpers.Errorf(helpers.ErrTidakValid, "Catatan penolakan wajib diisi")
+	}
+
+	return s.decidePenarikan(adminID, penarikanID, func(tx *gorm.DB, penarikan *model.Penarikan) error {
+		penarikan.Status = model.PenarikanRejected
+		penarikan.CatatanAdmin = request.Catatan
+		return nil
+	})
+}
+
+// decidePenarikan mengunci penarikan yang masih menunggu lalu menerapkan keputusan admin
+func (s *ledgerService) decidePenarikan(adminID uint, penarikanID uint, apply func(tx *gorm.DB, penarikan *model.Penarikan) error) (model.Penarikan, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		penarikan, err := s.ledgerRepository.FindPenarikanByIDForUpdate(tx, penarikanID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return helpers.Errorf(helpers.ErrTidakDitemukan, "Penarikan tidak ditemukan")
+			}
+			return err
+		}
+		if penarikan.Status != model.PenarikanPending {
+			return helpers.Errorf(helpers.ErrTidakDiizinkan, "Keputusan tidak diizinkan: penarikan sudah %s", penarikan.Status)
+		}
+
+		if err := apply(tx, &penarikan); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		penarikan.ReviewedBy = adminID
+		penarikan.ReviewedAt = &now
+		if err := s.ledgerRepository.UpdatePenarikan(tx, &penarikan); err != nil {
+			return errors.New("Gagal menyimpan keputusan penarikan")
+		}
+		return nil
+	})
+	if err != nil {
+		return model.Penarikan{}, err
+	}
+
+	return s.ledgerRepository.FindPenarikanByID(penarikanID)
+}
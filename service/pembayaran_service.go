@@ -0,0 +1,50 @@
+package service
+
+import (
+	"github.com/Debjth19/go-evermos/helpers"
+	"github.com/Debjth19/go-evermos/model"
+	"github.com/Debjth19/go-evermos/payment"
+
+	"errors"
+)
+
+type PembayaranService interface {
+	GetMethods() []string
+	HandleCallback(providerName string, body []byte, signature string) (model.Transaksi, error)
+}
+
+type pembayaranService struct {
+	paymentRegistry  *payment.Registry
+	transaksiService TransaksiService // Dibutuhkan untuk menerapkan hasil pembayaran ke transaksi
+}
+
+func NewPembayaranService(paymentRegistry *payment.Registry, transaksiService TransaksiService) PembayaranService {
+	return &pembayaranService{
+		paymentRegistry:  paymentRegistry,
+		transaksiService: transaksiService,
+	}
+}
+
+// GetMethods mengembalikan metode pembayaran yang dapat dipakai saat checkout
+func (s *pembayaranService) GetMethods() []string {
+	return s.paymentRegistry.Methods()
+}
+
+// HandleCallback memverifikasi webhook dari provider lalu menerapkan hasilnya ke transaksi.
+// Signature yang tidak cocok dikembalikan sebagai payment.ErrInvalidSignature.
+func (s *pembayaranService) HandleCallback(providerName string, body []byte, signature string) (model.Transaksi, error) {
+	provider, err := s.paymentRegistry.Provider(providerName)
+	if err != nil {
+		return model.Transaksi{}, helpers.Errorf(helpers.ErrTidakDitemukan, "%v", err)
+	}
+
+	result, err := provider.ParseCallback(body, signature)
+	if err != nil {
+		if errors.Is(err, payment.ErrInvalidSignature) {
+			return model.Transaksi{}, err
+		}
+		return model.Transaksi{}, helpers.Errorf(helpers.ErrTidakValid, "%v", err)
+	}
+
+	return s.transaksiService.ConfirmPayment(provider.Name(), result)
+}
@@ -0,0 +1,120 @@
+package service_test
+
+import (
+	"github.com/Debjth19/go-evermos/handler"
+	"github.com/Debjth19/go-evermos/model"
+	"github.com/Debjth19/go-evermos/payment"
+
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// providerLain adalah provider kedua dengan secret sendiri. Callback-nya sah untuk provider tersebut,
+// tetapi tidak boleh melunasi tagihan yang dibuat melalui provider fake.
+type providerLain struct {
+	*payment.FakeProvider
+}
+
+func (p providerLain) Name() string {
+	return "lain"
+}
+
+func (p providerLain) Methods() []string {
+	return []string{"qris"}
+}
+
+// pembuatCallback adalah provider yang dapat menandatangani body callback
+type pembuatCallback interface {
+	BuildCallback(result payment.CallbackResult) ([]byte, string, error)
+}
+
+func TestCallbackWebhook(t *testing.T) {
+	lain := providerLain{payment.NewFakeProvider("rahasia-lain")}
+	f := newFixture(t, 5, lain)
+
+	transaksi, err := f.checkout(payment.MethodBankTransfer, 1)
+	if err != nil {
+		t.Fatalf("checkout gagal: %v", err)
+	}
+
+	app := fiber.New()
+	app.Post("/api/v1/payments/callback/:provider", handler.NewPembayaranHandler(f.pembayaranService).Callback)
+
+	kirim := func(providerName string, signer pembuatCallback, result payment.CallbackResult) int {
+		t.Helper()
+		body, signature, err := signer.BuildCallback(result)
+		if err != nil {
+			t.Fatalf("gagal membuat callback: %v", err)
+		}
+		req := httptest.NewRequest("POST", "/api/v1/payments/callback/"+providerName, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Callback-Signature", signature)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("request callback gagal: %v", err)
+		}
+		return resp.StatusCode
+	}
+	statusTransaksi := func() (string, string) {
+		t.Helper()
+		var trx model.Transaksi
+		var pembayaran model.Pembayaran
+		if err := f.db.First(&trx, transaksi.ID).Error; err != nil {
+			t.Fatalf("gagal membaca transaksi: %v", err)
+		}
+		if err := f.db.Where("transaksi_id = ?", transaksi.ID).First(&pembayaran).Error; err != nil {
+			t.Fatalf("gagal membaca pembayaran: %v", err)
+		}
+		return trx.Status, pembayaran.Status
+	}
+
+	lunas := payment.CallbackResult{
+		KodeInvoice: transaksi.KodeInvoice,
+		Reference:   transaksi.Pembayaran.Reference,
+		Status:      payment.StatusPaid,
+		Jumlah:      transaksi.HargaTotal,
+	}
+
+	t.Run("provider lain ditolak", func(t *testing.T) {
+		if code := kirim("lain", lain, lunas); code != fiber.StatusBadRequest {
+			t.Fatalf("status = %d, ingin %d", code, fiber.StatusBadRequest)
+		}
+		if trx, bayar := statusTransaksi(); trx != model.StatusPendingPayment || bayar != model.PembayaranPending {
+			t.Fatalf("transaksi %s / pembayaran %s berubah oleh callback provider lain", trx, bayar)
+		}
+	})
+
+	t.Run("signature provider lain ditolak", func(t *testing.T) {
+		if code := kirim("fake", lain, lunas); code != fiber.StatusUnauthorized {
+			t.Fatalf("status = %d, ingin %d", code, fiber.StatusUnauthorized)
+		}
+	})
+
+	t.Run("referensi berbeda ditolak", func(t *testing.T) {
+		salah := lunas
+		salah.Reference = "FAKE-TAGIHAN-LAIN"
+		if code := kirim("fake", f.fake, salah); code != fiber.StatusBadRequest {
+			t.Fatalf("status = %d, ingin %d", code, fiber.StatusBadRequest)
+		}
+		if trx, bayar := statusTransaksi(); trx != model.StatusPendingPayment || bayar != model.PembayaranPending {
+			t.Fatalf("transaksi %s / pembayaran %s berubah oleh referensi yang salah", trx, bayar)
+		}
+	})
+
+	t.Run("callback sah melunasi pesanan", func(t *testing.T) {
+		if code := kirim("fake", f.fake, lunas); code != fiber.StatusOK {
+			t.Fatalf("status = %d, ingin %d", code, fiber.StatusOK)
+		}
+		if trx, bayar := statusTransaksi(); trx != model.StatusPaid || bayar != model.PembayaranPaid {
+			t.Fatalf("transaksi %s / pembayaran %s, ingin paid / paid", trx, bayar)
+		}
+
+		// Callback yang dikirim ulang tetap berhasil tanpa diproses dua kali
+		if code := kirim("fake", f.fake, lunas); code != fiber.StatusOK {
+			t.Fatalf("callback ulang: status = %d, ingin %d", code, fiber.StatusOK)
+		}
+	})
+}
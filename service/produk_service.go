@@ -7,6 +7,7 @@ import (
 	"github.com/Debjth19/go-evermos/repository"
 
 	"errors"
+	"fmt"
 	"mime/multipart"
 	"strconv"
 
@@ -16,7 +17,7 @@ import (
 
 type ProdukService interface {
 	CreateProduk(userID uint, request web.ProdukCreateRequest, files []*multipart.FileHeader) (model.Produk, error)
-	GetAllProduk(pagination helpers.Pagination, filterParams map[string]string) ([]model.Produk, error)
+	GetAllProduk(pagination *helpers.Pagination, filterParams map[string]string) ([]model.Produk, error)
 	GetProdukByID(produkID uint) (model.Produk, error)
 	UpdateProduk(userID uint, produkID uint, request web.ProdukUpdateRequest, files []*multipart.FileHeader) (model.Produk, error)
 	DeleteProduk(userID uint, produkID uint) error
@@ -71,7 +72,10 @@ func (s *produkService) CreateProduk(userID uint, request web.ProdukCreateReques
 	}
 
 	// 3. Buat slug
-	produkSlug := slug.Make(request.NamaProduk)
+	produkSlug, err := s.slugUnik(request.NamaProduk, 0)
+	if err != nil {
+		return model.Produk{}, err
+	}
 
 	// 4. Buat struct produk
 	produk := model.Produk{
@@ -80,6 +84,7 @@ func (s *produkService) CreateProduk(userID uint, request web.ProdukCreateReques
 		HargaReseler:  request.HargaReseler,
 		HargaKonsumen: request.HargaKonsumen,
 		Stok:          request.Stok,
+		Berat:         request.Berat,
 		Deskripsi:     request.Deskripsi,
 		TokoID:        toko.ID, // Set pemilik produk
 		CategoryID:    request.CategoryID,
@@ -96,9 +101,27 @@ func (s *produkService) CreateProduk(userID uint, request web.ProdukCreateReques
 	return newProduk, nil
 }
 
+// slugUnik membuat slug dari nama produk, diberi akhiran -2, -3, dan seterusnya
+// bila sudah dipakai produk lain
+func (s *produkService) slugUnik(nama string, exceptID uint) (string, error) {
+	dasar := slug.Make(nama)
+	kandidat := dasar
+	for i := 2; ; i++ {
+		ada, err := s.produkRepository.SlugExists(kandidat, exceptID)
+		if err != nil {
+			return "", err
+		}
+		if !ada {
+			return kandidat, nil
+		}
+		kandidat = fmt.Sprintf("%s-%d", dasar, i)
+	}
+}
+
 func (s *produkService) parseFilter(filterParams map[string]string) repository.ProdukFilter {
 	filter := repository.ProdukFilter{}
 	filter.NamaProduk = filterParams["nama_produk"]
+	filter.Urutan = filterParams["sort"]
 	
 	if catID, err := strconv.Atoi(filterParams["category_id"]); err == nil {
 		filter.CategoryID = uint(catID)
@@ -115,8 +138,11 @@ func (s *produkService) parseFilter(filterParams map[string]string) repository.P
 	return filter
 }
 
-func (s *produkService) GetAllProduk(pagination helpers.Pagination, filterParams map[string]string) ([]model.Produk, error) {
+func (s *produkService) GetAllProduk(pagination *helpers.Pagination, filterParams map[string]string) ([]model.Produk, error) {
 	filter := s.parseFilter(filterParams)
+	if !repository.IsValidUrutan(filter.Urutan) {
+		return nil, errors.New("Parameter sort tidak dikenal: gunakan terbaru, harga_terendah, harga_tertinggi, terlaris, atau nama")
+	}
 	return s.produkRepository.FindAll(pagination, filter)
 }
 
@@ -138,6 +164,11 @@ func (s *produkService) UpdateProduk(userID uint, produkID uint, request web.Pro
 		return produk, err
 	}
 
+	// Stok produk bervarian adalah jumlah stok variannya
+	if request.Stok != 0 && len(produk.Varian) > 0 {
+		return produk, errors.New("Stok tidak valid: produk memiliki varian, ubah stok melalui varian")
+	}
+
 	// 2. Simpan file foto BARU (jika ada)
 	var newFotoUrls []string
 	if len(files) > 0 {
@@ -158,7 +189,10 @@ func (s *produkService) UpdateProduk(userID uint, produkID uint, request web.Pro
 	// 3. Update field
 	if request.NamaProduk != "" {
 		produk.NamaProduk = request.NamaProduk
-		produk.Slug = slug.Make(request.NamaProduk) 
+		produk.Slug, err = s.slugUnik(request.NamaProduk, produk.ID)
+		if err != nil {
+			return produk, err
+		}
 	}
 	if request.CategoryID != 0 {
 		produk.CategoryID = request.CategoryID
@@ -172,6 +206,9 @@ func (s *produkService) UpdateProduk(userID uint, produkID uint, request web.Pro
 	if request.Stok != 0 {
 		produk.Stok = request.Stok
 	}
+	if request.Berat != 0 {
+		produk.Berat = request.Berat
+	}
 	if request.Deskripsi != "" {
 		produk.Deskripsi = request.Deskripsi
 	}
@@ -214,6 +251,9 @@ func (s *produkService) DeleteProduk(userID uint, produkID uint) error {
 	for _, foto := range produk.FotoProduk {
 		fotoUrls = append(fotoUrls, foto.Url)
 	}
+	for _, varian := range produk.Varian {
+		fotoUrls = append(fotoUrls, varian.UrlFoto)
+	}
 	helpers.DeleteFiles(fotoUrls, helpers.ProdukImagesPath)
 
 	// 3. Hapus produk dari DB (repository akan menangani foto)
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"github.com/Debjth19/go-evermos/helpers"
+	"github.com/Debjth19/go-evermos/model"
+	"github.com/Debjth19/go-evermos/model/web"
+	"github.com/Debjth19/go-evermos/repository"
+
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ResellerService interface {
+	Apply(userID uint, request web.ResellerApplyRequest) (model.PengajuanReseller, error)
+	GetMyApplication(userID uint) (model.PengajuanReseller, error)
+	GetApplications(pagination helpers.Pagination, status string) ([]model.PengajuanReseller, error)
+	Approve(adminID uint, pengajuanID uint, request web.ResellerDecisionRequest) (model.PengajuanReseller, error)
+	Reject(adminID uint, pengajuanID uint, request web.ResellerDecisionRequest) (model.PengajuanReseller, error)
+	GetMarginReport(userID uint, filterParams map[string]string) (web.MarginReportResponse, error)
+}
+
+// formatPeriodeMargin memetakan periode laporan margin ke format DATE_FORMAT MySQL
+var formatPeriodeMargin = map[string]string{
+	"harian":  "%Y-%m-%d",
+	"bulanan": "%Y-%m",
+	"tahunan": "%Y",
+}
+
+type resellerService struct {
+	db                 *gorm.DB // Dibutuhkan untuk memulai transaction
+	resellerRepository repository.ResellerRepository
+	userRepository     repository.UserRepository
+}
+
+func NewResellerService(db *gorm.DB, resellerRepo repository.ResellerRepository, userRepo repository.UserRepository) ResellerService {
+	return &resellerService{
+		db:                 db,
+		resellerRepository: resellerRepo,
+		userRepository:     userRepo,
+	}
+}
+
+// Apply membuat pengajuan reseller baru untuk user
+func (s *resellerService) Apply(userID uint, request web.ResellerApplyRequest) (model.PengajuanReseller, error) {
+	if request.Alasan == "" {
+		return model.PengajuanReseller{}, helpers.Errorf(helpers.ErrTidakValid, "Alasan pengajuan wajib diisi")
+	}
+
+	user, err := s.userRepository.FindByID(userID)
+	if err != nil {
+		return model.PengajuanReseller{}, helpers.Errorf(helpers.ErrTidakDitemukan, "User tidak ditemukan")
+	}
+	if user.Role != model.RoleUser {
+		return model.PengajuanReseller{}, helpers.Errorf(helpers.ErrTidakDiizinkan, "Pengajuan tidak diizinkan: akun Anda sudah berperan %s", user.Role)
+	}
+
+	// Hanya boleh ada satu pengajuan yang menunggu keputusan
+	latest, err := s.resellerRepository.FindLatestByUserID(userID)
+	if err == nil && latest.Status == model.PengajuanPending {
+		return model.PengajuanReseller{}, helpers.Errorf(helpers.ErrTidakDiizinkan, "Pengajuan tidak diizinkan: masih ada pengajuan yang menunggu keputusan")
+	}
+
+	pengajuan := model.PengajuanReseller{
+		UserID: userID,
+		Alasan: request.Alasan,
+		Status: model.PengajuanPending,
+	}
+	if err := s.resellerRepository.Create(&pengajuan); err != nil {
+		return pengajuan, errors.New("Gagal menyimpan pengajuan reseller")
+	}
+	return pengajuan, nil
+}
+
+// GetMyApplication mengambil pengajuan terakhir milik user
+func (s *resellerService) GetMyApplication(userID uint) (model.PengajuanReseller, error) {
+	pengajuan, err := s.resellerRepository.FindLatestByUserID(userID)
+	if err != nil {
+		return pengajuan, helpers.Errorf(helpers.ErrTidakDitemukan, "Pengajuan reseller tidak ditemukan")
+	}
+	return pengajuan, nil
+}
+
+// GetApplications mengambil daftar pengajuan untuk admin
+func (s *resellerService) GetApplications(pagination helpers.Pagination, status string) ([]model.PengajuanReseller, error) {
+	if status != "" && status != model.PengajuanPending && status != model.PengajuanApproved && status != model.PengajuanRejected {
+		return nil, helpers.Errorf(helpers.ErrTidakValid, "Status pengajuan tidak valid: %s", status)
+	}
+	return s.resellerRepository.FindAll(pagination, status)
+}
+
+// Approve menyetujui pengajuan dan mengubah role user menjadi reseller
+func (s *resellerService) Approve(adminID uint, pengajuanID uint, request web.ResellerDecisionRequest) (model.PengajuanReseller, error) {
+	return s.decide(adminID, pengajuanID, model.PengajuanApproved, request.Catatan)
+}
+
+// Reject menolak pengajuan dengan catatan dari admin
+func (s *resellerService) Reject(adminID uint, pengajuanID uint, request web.ResellerDecisionRequest) (model.PengajuanReseller, error) {
+	if request.Catatan == "" {
+		return model.PengajuanReseller{}, helpers.Errorf(helpers.ErrTidakValid, "Catatan penolakan wajib diisi")
+	}
+	return s.decide(adminID, pengajuanID, model.PengajuanRejected, request.Catatan)
+}
+
+// decide menerapkan keputusan admin pada pengajuan yang masih menunggu
+func (s *resellerService) decide(adminID uint, pengajuanID uint, status string, catatan string) (model.PengajuanReseller, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		pengajuan, err := s.resellerRepository.FindByIDForUpdate(tx, pengajuanID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return helpers.Errorf(helpers.ErrTidakDitemukan, "Pengajuan reseller tidak ditemukan")
+			}
+			return err
+		}
+		if pengajuan.Status != model.PengajuanPending {
+			return helpers.Errorf(helpers.ErrTidakDiizinkan, "Keputusan tidak diizinkan: pengajuan sudah %s", pengajuan.Status)
+		}
+
+		now := time.Now()
+		pengajuan.Status = status
+		pengajuan.CatatanAdmin = catatan
+		pengajuan.ReviewedBy = adminID
+		pengajuan.ReviewedAt = &now
+		if err := s.resellerRepository.Update(tx, &pengajuan); err != nil {
+			return errors.New("Gagal menyimpan keputusan pengajuan")
+		}
+
+		if status == model.PengajuanApproved {
+			if err := s.resellerRepository.UpdateUserRole(tx, pengajuan.UserID, model.RoleReseller); err != nil {
+				return errors.New("Gagal mengubah role user")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return model.PengajuanReseller{}, err
+	}
+
+	return s.resellerRepository.FindByID(pengajuanID)
+}
+
+// GetMarginReport merangkum margin pesanan dropship reseller per periode
+func (s *resellerService) GetMarginReport(userID uint, filterParams map[string]string) (web.MarginReportResponse, error) {
+	report := web.MarginReportResponse{Periode: "bulanan", Data: []web.MarginPeriodeResponse{}}
+
+	user, err := s.userRepository.FindByID(userID)
+	if err != nil {
+		return report, helpers.Errorf(helpers.ErrTidakDitemukan, "User tidak ditemukan")
+	}
+	if user.Role != model.RoleReseller {
+		return report, helpers.Errorf(helpers.ErrAksesDitolak, "Akses ditolak: Laporan margin hanya untuk reseller")
+	}
+
+	// 1. Validasi periode dan rentang tanggal
+	if periode := filterParams["periode"]; periode != "" {
+		report.Periode = periode
+	}
+	format, ok := formatPeriodeMargin[report.Periode]
+	if !ok {
+		return report, helpers.Errorf(helpers.ErrTidakValid, "Periode tidak valid: %s, gunakan harian, bulanan, atau tahunan", report.Periode)
+	}
+
+	var dari, sampai time.Time
+	if tanggal := filterParams["tanggal_dari"]; tanggal != "" {
+		if dari, err = time.ParseInLocation("2006-01-02", tanggal, time.Local); err != nil {
+			return report, helpers.Errorf(helpers.ErrTidakValid, "Format tanggal_dari tidak valid, gunakan yyyy-mm-dd")
+		}
+	}
+	if tanggal := filterParams["tanggal_sampai"]; tanggal != "" {
+		if sampai, err = time.ParseInLocation("2006-01-02", tanggal, time.Local); err != nil {
+			return report, helpers.Errorf(helpers.ErrTidakValid, "Format tanggal_sampai tidak valid, gunakan yyyy-mm-dd")
+		}
+		sampai = sampai.AddDate(0, 0, 1) // Inklusif sampai akhir hari
+	}
+
+	// 2. Ambil ringkasan per periode
+	rows, err := s.resellerRepository.MarginReport(userID, format, dari, sampai)
+	if err != nil {
+		return report, err
+	}
+
+	for _, row := range rows {
+		report.TotalPenjualan += row.TotalPenjualan
+		report.TotalModal += row.TotalModal
+		report.TotalMargin += row.TotalMargin
+		report.Data = append(report.Data, web.MarginPeriodeResponse{
+			Periode:        row.Periode,
+			JumlahPesanan:  row.JumlahPesanan,
+			TotalPenjualan: row.TotalPenjualan,
+			TotalModal:     row.TotalModal,
+			TotalMargin:    row.TotalMargin,
+		})
+	}
+	return report, nil
+}
@@ -0,0 +1,319 @@
+package service
+
+import (
+	"github.com/Debjth19/go-evermos/helpers"
+	"github.com/Debjth19/go-evermos/model"
+	"github.com/Debjth19/go-evermos/model/web"
+	"github.com/Debjth19/go-evermos/repository"
+
+	"errors"
+	"mime/multipart"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ReturService interface {
+	CreateRetur(userID uint, trxID uint, request web.ReturCreateRequest, files []*multipart.FileHeader) (model.Retur, error)
+	GetMyReturs(userID uint) ([]model.Retur, error)
+	GetMyReturByID(userID uint, returID uint) (model.Retur, error)
+	GetTokoReturs(userID uint, pagination helpers.Pagination, status string) ([]model.Retur, error)
+	GetTokoReturByID(userID uint, returID uint) (model.Retur, error)
+	ApproveRetur(userID uint, returID uint, request web.ReturDecisionRequest) (model.Retur, error)
+	RejectRetur(userID uint, returID uint, request web.ReturDecisionRequest) (model.Retur, error)
+}
+
+// Status sub-transaksi yang boleh diajukan retur (barang sudah diterima pembeli)
+var returEligibleStatuses = []string{model.StatusDelivered, model.StatusCompleted}
+
+type returService struct {
+	db                   *gorm.DB // Dibutuhkan untuk memulai transaction
+	returRepository      repository.ReturRepository
+	transaksiRepository  repository.TransaksiRepository // Dibutuhkan untuk cek kepemilikan & baris pesanan
+	produkRepository     repository.ProdukRepository    // Dibutuhkan untuk mengembalikan stok
+	tokoRepository       repository.TokoRepository      // Dibutuhkan untuk otorisasi penjual
+	pembayaranRepository repository.PembayaranRepository
+	ledgerService        LedgerService // Dibutuhkan untuk mendebit saldo toko saat refund
+}
+
+func NewReturService(db *gorm.DB, returRepo repository.ReturRepository, trxRepo repository.TransaksiRepository, produkRepo repository.ProdukRepository, tokoRepo repository.TokoRepository, pembayaranRepo repository.PembayaranRepository, ledgerService LedgerService) ReturService {
+	return &returService{
+		db:                   db,
+		returRepository:      returRepo,
+		transaksiRepository:  trxRepo,
+		produkRepository:     produkRepo,
+		tokoRepository:       tokoRepo,
+		pembayaranRepository: pembayaranRepo,
+		ledgerService:        ledgerService,
+	}
+}
+
+// CreateRetur membuat pengajuan retur untuk baris-baris DetailTransaksi pada satu sub-transaksi
+func (s *returService) CreateRetur(userID uint, trxID uint, request web.ReturCreateRequest, files []*multipart.FileHeader) (model.Retur, error) {
+	// 1. Validasi request
+	if request.Alasan == "" {
+		return model.Retur{}, helpers.Errorf(helpers.ErrTidakValid, "Alasan retur wajib diisi")
+	}
+	if len(request.Items) == 0 {
+		return model.Retur{}, helpers.Errorf(helpers.ErrTidakValid, "Item retur wajib diisi")
+	}
+	if len(files) == 0 {
+		return model.Retur{}, helpers.Errorf(helpers.ErrTidakValid, "Foto bukti retur wajib diisi")
+	}
+	seen := map[uint]bool{}
+	for _, item := range request.Items {
+		if item.Kuantitas == 0 {
+			return model.Retur{}, helpers.Errorf(helpers.ErrTidakValid, "Kuantitas retur tidak valid")
+		}
+		if seen[item.DetailTransaksiID] {
+			return model.Retur{}, helpers.Errorf(helpers.ErrTidakValid, "Item retur tidak valid: detail_transaksi_id %d duplikat", item.DetailTransaksiID)
+		}
+		seen[item.DetailTransaksiID] = true
+	}
+
+	// 2. Simpan foto bukti
+	fotoUrls, err := helpers.SaveUploadedFiles(files, helpers.ReturImagesPath)
+	if err != nil {
+		return model.Retur{}, errors.New("Gagal menyimpan foto bukti retur")
+	}
+
+	var retur model.Retur
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// 3. Kunci sub-transaksi agar pengajuan retur bersamaan tidak melebihi kuantitas
+		transaksi, err := s.transaksiRepository.FindByIDForUpdate(tx, trxID)
+		if err != nil || transaksi.UserID != userID {
+			return helpers.Errorf(helpers.ErrTidakDitemukan, "Transaksi tidak ditemukan atau bukan milik Anda")
+		}
+		if transaksi.TokoID == 0 {
+			return helpers.Errorf(helpers.ErrTidakValid, "Retur tidak valid: ajukan retur melalui sub-pesanan per toko")
+		}
+		if !containsStatus(returEligibleStatuses, transaksi.Status) {
+			return helpers.Errorf(helpers.ErrTidakDiizinkan, "Retur tidak diizinkan: pesanan berstatus %s", transaksi.Status)
+		}
+
+		// 4. Ambil baris pesanan, snapshot produk, dan kuantitas yang sudah diretur
+		details, err := s.transaksiRepository.FindDetailsByTransaksiID(tx, transaksi.ID)
+		if err != nil {
+			return errors.New("Gagal mengambil detail transaksi")
+		}
+		detailByID := map[uint]model.DetailTransaksi{}
+		var detailIDs []uint
+		for _, detail := range details {
+			detailByID[detail.ID] = detail
+			detailIDs = append(detailIDs, detail.ID)
+		}
+
+		logs, err := s.transaksiRepository.FindLogsByTransaksiID(tx, transaksi.ID)
+		if err != nil {
+			return errors.New("Gagal mengambil snapshot produk")
+		}
+		namaProduk := map[uint]string{} // Per snapshot, diikuti nama varian bila ada
+		for _, log := range logs {
+			namaProduk[log.ID] = log.NamaProduk
+			if log.NamaVarian != "" {
+				namaProduk[log.ID] += " (" + log.NamaVarian + ")"
+			}
+		}
+
+		returned, err := s.returRepository.SumReturnedQty(tx, detailIDs)
+		if err != nil {
+			return errors.New("Gagal menghitung kuantitas retur")
+		}
+
+		// 5. Susun item retur
+		var items []model.ReturItem
+		for _, item := range request.Items {
+			detail, ok := detailByID[item.DetailTransaksiID]
+			if !ok {
+				return helpers.Errorf(helpers.ErrTidakValid, "Item retur tidak valid: detail_transaksi_id %d bukan bagian dari pesanan ini", item.DetailTransaksiID)
+			}
+			if returned[detail.ID]+item.Kuantitas > detail.Kuantitas {
+				return helpers.Errorf(helpers.ErrTidakValid, "Kuantitas retur tidak valid: sisa yang dapat diretur untuk detail_transaksi_id %d adalah %d", detail.ID, detail.Kuantitas-returned[detail.ID])
+			}
+
+			items = append(items, model.ReturItem{
+				DetailTransaksiID: detail.ID,
+				ProductID:         detail.ProductID,
+				VarianID:          detail.VarianID,
+				NamaProduk:        namaProduk[detail.LogProdukID],
+				Kuantitas:         item.Kuantitas,
+				JumlahRefund:      refundBaris(detail, item.Kuantitas),
+			})
+		}
+
+		var fotos []model.FotoRetur
+		for _, url := range fotoUrls {
+			fotos = append(fotos, model.FotoRetur{Url: url})
+		}
+
+		// 6. Simpan retur beserta item dan fotonya
+		retur = model.Retur{
+			TransaksiID: transaksi.ID,
+			UserID:      userID,
+			TokoID:      transaksi.TokoID,
+			Alasan:      request.Alasan,
+			Status:      model.ReturRequested,
+			Items:       items,
+			FotoRetur:   fotos,
+		}
+		if err := s.returRepository.Create(tx, &retur); err != nil {
+			return errors.New("Gagal menyimpan retur")
+		}
+		return nil
+	})
+
+	if err != nil {
+		// Hapus foto yang sudah terlanjur disimpan
+		helpers.DeleteFiles(fotoUrls, helpers.ReturImagesPath)
+		return model.Retur{}, err
+	}
+
+	return s.returRepository.FindByID(retur.ID)
+}
+
+// refundBaris menghitung dana yang dikembalikan ke pembeli: harga baris setelah potongan voucher,
+// proporsional terhadap kuantitas yang diretur
+func refundBaris(detail model.DetailTransaksi, kuantitas uint) uint {
+	nilaiToko, diskonPlatform := detail.BagianRefund(kuantitas)
+	return nilaiToko - diskonPlatform
+}
+
+// GetMyReturs mengambil semua retur milik pembeli
+func (s *returService) GetMyReturs(userID uint) ([]model.Retur, error) {
+	return s.returRepository.FindByUserID(userID)
+}
+
+// GetMyReturByID mengambil satu retur milik pembeli
+func (s *returService) GetMyReturByID(userID uint, returID uint) (model.Retur, error) {
+	retur, err := s.returRepository.FindByID(returID)
+	if err != nil || retur.UserID != userID {
+		return model.Retur{}, helpers.Errorf(helpers.ErrTidakDitemukan, "Retur tidak ditemukan atau bukan milik Anda")
+	}
+	return retur, nil
+}
+
+// GetTokoReturs mengambil retur yang masuk ke toko milik user
+func (s *returService) GetTokoReturs(userID uint, pagination helpers.Pagination, status string) ([]model.Retur, error) {
+	toko, err := s.tokoRepository.FindByUserID(userID)
+	if err != nil {
+		return nil, helpers.Errorf(helpers.ErrTidakDitemukan, "Toko Anda tidak ditemukan")
+	}
+
+	if status != "" && status != model.ReturRequested && status != model.ReturApproved && status != model.ReturRejected {
+		return nil, helpers.Errorf(helpers.ErrTidakValid, "Status retur tidak valid: %s", status)
+	}
+
+	return s.returRepository.FindByTokoID(toko.ID, pagination, status)
+}
+
+// GetTokoReturByID mengambil satu retur yang masuk ke toko milik user
+func (s *returService) GetTokoReturByID(userID uint, returID uint) (model.Retur, error) {
+	toko, err := s.tokoRepository.FindByUserID(userID)
+	if err != nil {
+		return model.Retur{}, helpers.Errorf(helpers.ErrTidakDitemukan, "Toko Anda tidak ditemukan")
+	}
+
+	retur, err := s.returRepository.FindByID(returID)
+	if err != nil || retur.TokoID != toko.ID {
+		return model.Retur{}, helpers.Errorf(helpers.ErrTidakDitemukan, "Retur tidak ditemukan atau bukan milik toko Anda")
+	}
+	return retur, nil
+}
+
+// ApproveRetur menyetujui retur: stok dikembalikan dan refund dicatat terhadap pembayaran asal
+func (s *returService) ApproveRetur(userID uint, returID uint, request web.ReturDecisionRequest) (model.Retur, error) {
+	return s.decide(userID, returID, func(tx *gorm.DB, retur *model.Retur, now time.Time) error {
+		// 1. Kembalikan stok produk
+		var total uint
+		for _, item := range retur.Items {
+			total += item.JumlahRefund
+
+			if err := kembalikanStok(tx, s.produkRepository, item.ProductID, item.VarianID, item.Kuantitas); err != nil {
+				return err
+			}
+		}
+
+		// 2. Catat refund terhadap pembayaran transaksi induk
+		transaksi, err := s.transaksiRepository.FindByID(tx, retur.TransaksiID)
+		if err != nil {
+			return errors.New("Transaksi retur tidak ditemukan")
+		}
+		pembayaranTrxID := transaksi.ID
+		if transaksi.ParentID != nil {
+			pembayaranTrxID = *transaksi.ParentID
+		}
+		pembayaran, err := s.pembayaranRepository.FindByTransaksiIDForUpdate(tx, pembayaranTrxID)
+		if err != nil {
+			return errors.New("Pembayaran transaksi tidak ditemukan")
+		}
+
+		refund := model.Refund{
+			ReturID:      &retur.ID,
+			TransaksiID:  retur.TransaksiID,
+			PembayaranID: pembayaran.ID,
+			Jumlah:       total,
+			Status:       model.RefundPending,
+		}
+		if err := s.returRepository.CreateRefund(tx, &refund); err != nil {
+			return errors.New("Gagal menyimpan refund")
+		}
+		if err := s.ledgerService.PostRefund(tx, *retur); err != nil {
+			return err
+		}
+
+		// 3. Tandai retur disetujui
+		retur.Status = model.ReturApproved
+		retur.ApprovedAt = &now
+		retur.CatatanPenjual = request.Catatan
+		return nil
+	})
+}
+
+// RejectRetur menolak retur dengan catatan dari penjual
+func (s *returService) RejectRetur(userID uint, returID uint, request web.ReturDecisionRequest) (model.Retur, error) {
+	if request.Catatan == "" {
+		return model.Retur{}, helpers.Errorf(helpers.ErrTidakValid, "Catatan penolakan wajib diisi")
+	}
+
+	return s.decide(userID, returID, func(tx *gorm.DB, retur *model.Retur, now time.Time) error {
+		retur.Status = model.ReturRejected
+		retur.RejectedAt = &now
+		retur.CatatanPenjual = request.Catatan
+		return nil
+	})
+}
+
+// decide mengunci retur, memastikan retur milik toko user dan masih menunggu keputusan,
+// lalu menerapkan keputusan penjual di dalam satu DB transaction
+func (s *returService) decide(userID uint, returID uint, apply func(tx *gorm.DB, retur *model.Retur, now time.Time) error) (model.Retur, error) {
+	toko, err := s.tokoRepository.FindByUserID(userID)
+	if err != nil {
+		return model.Retur{}, helpers.Errorf(helpers.ErrTidakDitemukan, "Toko Anda tidak ditemukan")
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		retur, err := s.returRepository.FindByIDForUpdate(tx, returID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return helpers.Errorf(helpers.ErrTidakDitemukan, "Retur tidak ditemukan")
+			}
+			return err
+		}
+		if retur.TokoID != toko.ID {
+			return helpers.Errorf(helpers.ErrAksesDitolak, "Akses ditolak: Retur ini bukan milik toko Anda")
+		}
+		if retur.Status != model.ReturRequested {
+			return helpers.Errorf(helpers.ErrTidakDiizinkan, "Keputusan retur tidak diizinkan: retur sudah %s", retur.Status)
+		}
+
+		if err := apply(tx, &retur, time.Now()); err != nil {
+			return err
+		}
+		return s.returRepository.Update(tx, &retur)
+	})
+	if err != nil {
+		return model.Retur{}, err
+	}
+
+	return s.returRepository.FindByID(returID)
+}
@@ -19,7 +19,7 @@ import (
 type TokoService interface {
 	GetMyToko(userID uint) (model.Toko, error)
 	GetTokoByID(tokoID uint) (model.Toko, error)
-	GetAllToko(pagination helpers.Pagination, search string) ([]model.Toko, error)
+	GetAllToko(pagination *helpers.Pagination, search string) ([]model.Toko, error)
 	UpdateToko(userID uint, tokoID uint, request web.TokoUpdateRequest, file *multipart.FileHeader) (model.Toko, error)
 }
 
@@ -72,8 +72,8 @@ func (s *tokoService) GetTokoByID(tokoID uint) (model.Toko, error) {
 	return toko, nil
 }
 
-// GetAllToko mengambil semua toko dengan pagination dan filter
-func (s *tokoService) GetAllToko(pagination helpers.Pagination, search string) ([]model.Toko, error) {
+// GetAllToko mengambil semua toko dengan pagination dan filter; total dan cursor berikutnya diisi ke pagination
+func (s *tokoService) GetAllToko(pagination *helpers.Pagination, search string) ([]model.Toko, error) {
 	tokos, err := s.tokoRepository.FindAll(pagination, search)
 	if err != nil {
 		return tokos, err
@@ -129,6 +129,14 @@ func (s *tokoService) UpdateToko(userID uint, tokoID uint, request web.TokoUpdat
 		toko.NamaToko = request.NamaToko
 	}
 
+	// Kota asal pengiriman (jika diisi)
+	if request.IDProvinsi != "" {
+		toko.IDProvinsi = request.IDProvinsi
+	}
+	if request.IDKota != "" {
+		toko.IDKota = request.IDKota
+	}
+
 	// 4. Simpan perubahan ke database
 	updatedToko, err := s.tokoRepository.Update(toko)
 	if err != nil {
@@ -0,0 +1,91 @@
+package service_test
+
+import (
+	"github.com/Debjth19/go-evermos/model"
+	"github.com/Debjth19/go-evermos/model/web"
+	"github.com/Debjth19/go-evermos/payment"
+
+	"testing"
+)
+
+// bayar melunasi tagihan pesanan melalui provider fake
+func (f *fixture) bayar(t *testing.T, transaksi model.Transaksi) {
+	t.Helper()
+	_, err := f.transaksiService.ConfirmPayment(f.fake.Name(), payment.CallbackResult{
+		KodeInvoice: transaksi.KodeInvoice,
+		Reference:   transaksi.Pembayaran.Reference,
+		Status:      payment.StatusPaid,
+		Jumlah:      transaksi.HargaTotal,
+	})
+	if err != nil {
+		t.Fatalf("pembayaran gagal: %v", err)
+	}
+}
+
+func TestCancelPesananSudahDibayar(t *testing.T) {
+	f := newFixture(t, 5)
+
+	transaksi, err := f.checkout(payment.MethodBankTransfer, 2)
+	if err != nil {
+		t.Fatalf("checkout gagal: %v", err)
+	}
+	f.bayar(t, transaksi)
+
+	batal, err := f.transaksiService.CancelByBuyer(f.pembeli.ID, transaksi.ID, web.TransaksiCancelRequest{Alasan: "Berubah pikiran"})
+	if err != nil {
+		t.Fatalf("pembatalan pesanan yang sudah dibayar gagal: %v", err)
+	}
+	if batal.Status != model.StatusCancelled {
+		t.Fatalf("status = %s, ingin %s", batal.Status, model.StatusCancelled)
+	}
+
+	var produk model.Produk
+	if err := f.db.First(&produk, f.produk.ID).Error; err != nil {
+		t.Fatalf("gagal membaca produk: %v", err)
+	}
+	if produk.Stok != 5 {
+		t.Fatalf("stok = %d, ingin 5 setelah pembatalan", produk.Stok)
+	}
+
+	// Refund dicatat per sub-pesanan terhadap pembayaran transaksi induk
+	sub := transaksi.SubTransaksi[0]
+	var refunds []model.Refund
+	if err := f.db.Find(&refunds).Error; err != nil {
+		t.Fatalf("gagal membaca refund: %v", err)
+	}
+	if len(refunds) != 1 {
+		t.Fatalf("jumlah refund = %d, ingin 1", len(refunds))
+	}
+	refund := refunds[0]
+	if refund.TransaksiID != sub.ID || refund.PembayaranID != transaksi.Pembayaran.ID || refund.ReturID != nil {
+		t.Fatalf("refund %+v tidak merujuk sub-pesanan %d dan pembayaran %d", refund, sub.ID, transaksi.Pembayaran.ID)
+	}
+	if refund.Jumlah != sub.HargaTotal || refund.Status != model.RefundPending {
+		t.Fatalf("refund %d (%s), ingin %d (%s)", refund.Jumlah, refund.Status, sub.HargaTotal, model.RefundPending)
+	}
+}
+
+func TestCancelPesananSudahDikirimDitolak(t *testing.T) {
+	f := newFixture(t, 5)
+
+	transaksi, err := f.checkout(payment.MethodBankTransfer, 1)
+	if err != nil {
+		t.Fatalf("checkout gagal: %v", err)
+	}
+	f.bayar(t, transaksi)
+	sub := transaksi.SubTransaksi[0]
+	if err := f.db.Model(&model.Transaksi{}).Where("id = ?", sub.ID).Update("status", model.StatusShipped).Error; err != nil {
+		t.Fatalf("gagal mengirim pesanan: %v", err)
+	}
+
+	if _, err := f.transaksiService.CancelByBuyer(f.pembeli.ID, sub.ID, web.TransaksiCancelRequest{Alasan: "Terlambat"}); err == nil {
+		t.Fatal("pesanan yang sudah dikirim tidak boleh dibatalkan")
+	}
+	var jumlah int64
+	if err := f.db.Model(&model.Refund{}).Count(&jumlah).Error; err != nil {
+		t.Fatalf("gagal membaca refund: %v", err)
+	}
+	if jumlah != 0 {
+		t.Fatalf("jumlah refund = %d, ingin 0", jumlah)
+	}
+}
@@ -1,12 +1,17 @@
 package service
 
 import (
+	"github.com/Debjth19/go-evermos/helpers"
 	"github.com/Debjth19/go-evermos/model"
 	"github.com/Debjth19/go-evermos/model/web"
+	"github.com/Debjth19/go-evermos/payment"
 	"github.com/Debjth19/go-evermos/repository"
+	"github.com/Debjth19/go-evermos/shipping"
 
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"time"
 
 	"gorm.io/gorm"
@@ -14,78 +19,260 @@ import (
 
 type TransaksiService interface {
 	CreateTransaksi(userID uint, request web.TransaksiCreateRequest) (model.Transaksi, error)
-	GetMyTransactions(userID uint) ([]model.Transaksi, error)
+	CreateTransaksiWith(userID uint, request web.TransaksiCreateRequest, finalize func(tx *gorm.DB) error) (model.Transaksi, error)
+	QuoteOngkir(userID uint, request web.OngkirQuoteRequest) ([]web.OngkirTokoResponse, error)
+	GetMyTransactions(userID uint, pagination *helpers.Pagination, filterParams map[string]string) ([]model.Transaksi, error)
 	GetMyTransactionByID(userID uint, trxID uint) (model.Transaksi, error)
+	UpdateStatusByBuyer(userID uint, trxID uint, request web.TransaksiStatusUpdateRequest) (model.Transaksi, error)
+	UpdateStatusBySeller(userID uint, trxID uint, request web.TransaksiStatusUpdateRequest) (model.Transaksi, error)
+	CancelByBuyer(userID uint, trxID uint, request web.TransaksiCancelRequest) (model.Transaksi, error)
+	GetTokoOrders(userID uint, pagination *helpers.Pagination, filterParams map[string]string) ([]model.Transaksi, error)
+	ConfirmPayment(providerName string, result payment.CallbackResult) (model.Transaksi, error)
+	ExpireNextUnpaid(batas time.Time, lewati []uint) (uint, error)
+	GetInvoicePDF(userID uint, trxID uint) (model.Transaksi, []byte, error)
+	GetTokoInvoicePDF(userID uint, trxID uint) (model.Transaksi, []byte, error)
+	GetItemSnapshot(userID uint, detailID uint) (model.DetailTransaksi, error)
+	GetTokoItemSnapshot(userID uint, detailID uint) (model.DetailTransaksi, error)
 }
 
+// Status yang boleh dituju oleh masing-masing pihak.
+// Paid hanya diubah melalui callback pembayaran, cancelled dan expired oleh sistem.
+var (
+	buyerStatusTargets  = []string{model.StatusDelivered, model.StatusCompleted}
+	sellerStatusTargets = []string{model.StatusProcessing, model.StatusShipped, model.StatusDelivered}
+)
+
+// PerubahanCheckoutError dikembalikan CreateTransaksi bila harga per unit yang dikirim klien
+// berbeda dengan harga saat ini. Berisi setiap baris yang harga atau stoknya berubah agar
+// aplikasi dapat meminta konfirmasi pembeli.
+type PerubahanCheckoutError struct {
+	Items []web.PerubahanCheckoutResponse
+}
+
+func (e *PerubahanCheckoutError) Error() string {
+	return "Checkout tidak diizinkan: harga atau stok produk berubah, periksa kembali pesanan Anda"
+}
+
+// checkoutMaxAttempts membatasi percobaan ulang checkout yang gagal karena deadlock atau lock wait timeout
+const checkoutMaxAttempts = 3
+
 type transaksiService struct {
-	db                  *gorm.DB // Dibutuhkan untuk memulai transaction
-	transaksiRepository repository.TransaksiRepository
-	produkRepository    repository.ProdukRepository // Dibutuhkan untuk cek stok & update
-	alamatRepository    repository.AlamatRepository // Dibutuhkan untuk cek kepemilikan alamat
+	db                   *gorm.DB // Dibutuhkan untuk memulai transaction
+	transaksiRepository  repository.TransaksiRepository
+	produkRepository     repository.ProdukRepository   // Dibutuhkan untuk cek stok & update
+	kategoriRepository   repository.KategoriRepository // Dibutuhkan untuk snapshot nama kategori
+	alamatRepository     repository.AlamatRepository   // Dibutuhkan untuk cek kepemilikan alamat
+	tokoRepository       repository.TokoRepository     // Dibutuhkan untuk otorisasi penjual
+	pembayaranRepository repository.PembayaranRepository
+	invoiceRepository    repository.InvoiceRepository  // Dibutuhkan untuk nomor urut kode invoice
+	userRepository       repository.UserRepository     // Dibutuhkan untuk menentukan tier harga pembeli
+	ledgerService        LedgerService                 // Dibutuhkan untuk mencatat hak toko saat pesanan selesai
+	voucherService       VoucherService                // Dibutuhkan untuk menerapkan voucher saat checkout
+	paymentRegistry      *payment.Registry             // Dibutuhkan untuk validasi metode bayar & membuat tagihan
+	shippingProvider     shipping.ShippingRateProvider // Dibutuhkan untuk menghitung ongkos kirim
 }
 
-func NewTransaksiService(db *gorm.DB, trxRepo repository.TransaksiRepository, produkRepo repository.ProdukRepository, alamatRepo repository.AlamatRepository) TransaksiService {
+func NewTransaksiService(db *gorm.DB, trxRepo repository.TransaksiRepository, produkRepo repository.ProdukRepository, kategoriRepo repository.KategoriRepository, alamatRepo repository.AlamatRepository, tokoRepo repository.TokoRepository, pembayaranRepo repository.PembayaranRepository, invoiceRepo repository.InvoiceRepository, userRepo repository.UserRepository, ledgerService LedgerService, voucherService VoucherService, paymentRegistry *payment.Registry, shippingProvider shipping.ShippingRateProvider) TransaksiService {
 	return &transaksiService{
-		db:                  db,
-		transaksiRepository: trxRepo,
-		produkRepository:    produkRepo,
-		alamatRepository:    alamatRepo,
+		db:                   db,
+		transaksiRepository:  trxRepo,
+		produkRepository:     produkRepo,
+		kategoriRepository:   kategoriRepo,
+		alamatRepository:     alamatRepo,
+		tokoRepository:       tokoRepo,
+		pembayaranRepository: pembayaranRepo,
+		invoiceRepository:    invoiceRepo,
+		userRepository:       userRepo,
+		ledgerService:        ledgerService,
+		voucherService:       voucherService,
+		paymentRegistry:      paymentRegistry,
+		shippingProvider:     shippingProvider,
 	}
 }
 
-// CreateTransaksi menangani semua logika pembuatan transaksi
+// CreateTransaksi menangani semua logika pembuatan transaksi.
+// Item dikelompokkan per toko: satu transaksi induk yang dibayar sekali oleh pembeli,
+// dan satu sub-transaksi per toko dengan subtotal, status, dan pengirimannya sendiri.
 func (s *transaksiService) CreateTransaksi(userID uint, request web.TransaksiCreateRequest) (model.Transaksi, error) {
+	return s.CreateTransaksiWith(userID, request, nil)
+}
+
+// CreateTransaksiWith membuat transaksi seperti CreateTransaksi, lalu menjalankan finalize
+// di dalam DB transaction yang sama (misalnya mengosongkan keranjang), sehingga transaksi
+// batal dibuat bila finalize gagal.
+func (s *transaksiService) CreateTransaksiWith(userID uint, request web.TransaksiCreateRequest, finalize func(tx *gorm.DB) error) (model.Transaksi, error) {
 	var transaksi model.Transaksi
-	var hargaTotalTransaksi uint = 0
 
-	err := s.db.Transaction(func(tx *gorm.DB) error {
-		// 1. Verifikasi Alamat Kirim
-		alamat, err := s.alamatRepository.FindByID(request.AlamatKirim)
-		if err != nil {
-			return errors.New("Alamat kirim tidak ditemukan")
+	// Metode bayar harus ditangani oleh salah satu provider
+	provider, err := s.paymentRegistry.ProviderForMethod(request.MethodBayar)
+	if err != nil {
+		return transaksi, helpers.Errorf(helpers.ErrTidakValid, "%v", err)
+	}
+
+	// Pesanan COD tidak menunggu pembayaran dan langsung diproses penjual
+	statusAwal := model.StatusPendingPayment
+	if request.MethodBayar == payment.MethodCOD {
+		statusAwal = model.StatusProcessing
+	}
+	now := time.Now()
+
+	// Reseller yang sudah disetujui dikenakan harga reseller
+	user, err := s.userRepository.FindByID(userID)
+	if err != nil {
+		return transaksi, helpers.Errorf(helpers.ErrTidakDitemukan, "User tidak ditemukan")
+	}
+	isReseller := user.Role == model.RoleReseller
+
+	// Mode dropship hanya untuk reseller dan membutuhkan data pelanggan
+	dropship := request.Dropship
+	if dropship != nil {
+		if !isReseller {
+			return transaksi, helpers.Errorf(helpers.ErrAksesDitolak, "Akses ditolak: Mode dropship hanya untuk reseller")
+		}
+		if dropship.NamaPenerima == "" || dropship.NoTelp == "" || dropship.DetailAlamat == "" || dropship.IDKota == "" {
+			return transaksi, helpers.Errorf(helpers.ErrTidakValid, "Data penerima dropship wajib diisi")
 		}
-		// Cek kepemilikan alamat
-		if alamat.UserID != userID {
-			return errors.New("Akses ditolak: Alamat kirim bukan milik Anda")
+	}
+
+	// Baris dengan produk yang sama digabung, lalu diurutkan berdasarkan ID produk
+	// agar checkout yang berjalan bersamaan selalu mengunci produk dengan urutan yang sama
+	items, err := gabungItem(request.DetailTrx)
+	if err != nil {
+		return transaksi, err
+	}
+
+	// Kode invoice diambil sebelum checkout dimulai
+	kodeInvoice, err := s.nextKodeInvoice(now)
+	if err != nil {
+		return transaksi, err
+	}
+
+	err = helpers.TransactionWithRetry(s.db, checkoutMaxAttempts, func(tx *gorm.DB) error {
+		// 1. Verifikasi Alamat Kirim (pesanan dropship dikirim ke alamat pelanggan reseller)
+		alamat, err := s.alamatTujuan(userID, request.AlamatKirim, dropship)
+		if err != nil {
+			return err
 		}
+		tujuan := shipping.Lokasi{IDProvinsi: alamat.IDProvinsi, IDKota: alamat.IDKota}
 
-		// Siapkan slice untuk detail dan log
-		var details []model.DetailTransaksi
-		var logs []model.LogProduk
+		// Siapkan detail dan log, dikelompokkan per toko
+		var tokoIDs []uint // Urutan toko sesuai kemunculan pertama produknya
+		details := map[uint][]model.DetailTransaksi{}
+		logs := map[uint][]model.LogProduk{}
+		berat := map[uint]uint{}          // Berat paket per toko dalam gram
+		kategoriProduk := map[uint]uint{} // Kategori setiap produk, dipakai untuk syarat voucher
+		namaKategori := map[uint]string{} // Nama kategori untuk snapshot produk
+		var hargaTotalTransaksi uint = 0
+
+		// Bila klien mengirim harga per unit, seluruh baris yang berubah dikumpulkan lebih dulu
+		cekPerubahan := adaHargaDiharapkan(items)
+		var perubahan []web.PerubahanCheckoutResponse
 
 		// 2. Loop setiap item produk di keranjang
-		for _, item := range request.DetailTrx {
+		for _, item := range items {
 			produk, err := s.produkRepository.FindByIDForUpdate(tx, item.ProductID)
 			if err != nil {
-				return fmt.Errorf("Produk dengan ID %d tidak ditemukan", item.ProductID)
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return helpers.Errorf(helpers.ErrTidakValid, "Produk dengan ID %d tidak ditemukan", item.ProductID)
+				}
+				return err // Deadlock dan lock wait timeout diteruskan agar checkout diulang
+			}
+
+			// Produk bervarian memakai harga dan stok varian yang dipilih, varian dikunci setelah produknya
+			stokProduk := produk.Stok
+			varian, err := s.varianCheckout(tx, produk, item.VarianID)
+			if err != nil {
+				return err
+			}
+			if varian.ID != 0 {
+				produk = produk.DenganVarian(varian)
+			}
+			nama := namaItem(produk, varian)
+
+			// Tentukan tier harga, produk tanpa harga reseller tetap memakai harga konsumen
+			tierHarga, hargaSatuan := produk.HargaTier(isReseller)
+
+			if cekPerubahan {
+				hargaBerubah := item.HargaSatuan != nil && *item.HargaSatuan != hargaSatuan
+				stokCukup := produk.Stok >= item.Kuantitas
+				if hargaBerubah || !stokCukup {
+					hargaLama := hargaSatuan
+					if item.HargaSatuan != nil {
+						hargaLama = *item.HargaSatuan
+					}
+					perubahan = append(perubahan, web.PerubahanCheckoutResponse{
+						ProductID:    produk.ID,
+						VarianID:     varian.ID,
+						NamaProduk:   nama,
+						Kuantitas:    item.Kuantitas,
+						HargaLama:    hargaLama,
+						HargaBaru:    hargaSatuan,
+						StokTersisa:  produk.Stok,
+						HargaBerubah: hargaBerubah,
+						StokCukup:    stokCukup,
+					})
+					continue
+				}
 			}
 
 			// Cek Stok
 			if produk.Stok < item.Kuantitas {
-				return fmt.Errorf("Stok tidak mencukupi untuk produk: %s", produk.NamaProduk)
+				return helpers.Errorf(helpers.ErrTidakValid, "Stok tidak mencukupi untuk produk: %s", nama)
+			}
+
+			// Pada dropship, reseller menentukan harga jual ke pelanggannya sendiri
+			var hargaJual, margin uint
+			if dropship != nil {
+				if item.HargaJual < hargaSatuan {
+					return helpers.Errorf(helpers.ErrTidakValid, "Harga jual tidak valid untuk produk %s: minimal %d", nama, hargaSatuan)
+				}
+				hargaJual = item.HargaJual
+				margin = (hargaJual - hargaSatuan) * item.Kuantitas
 			}
 
 			// Hitung harga total untuk item ini
-			hargaTotalItem := produk.HargaKonsumen * item.Kuantitas
+			hargaTotalItem := hargaSatuan * item.Kuantitas
 			hargaTotalTransaksi += hargaTotalItem
 
-			// Kurangi stok
-			newStok := produk.Stok - item.Kuantitas
+			// Kurangi stok, stok produk bervarian (jumlah stok varian) ikut berkurang
+			if varian.ID != 0 {
+				if err := s.produkRepository.UpdateStokVarian(tx, varian.ID, varian.Stok-item.Kuantitas); err != nil {
+					return fmt.Errorf("Gagal update stok untuk: %s", nama)
+				}
+			}
+			var newStok uint
+			if stokProduk > item.Kuantitas {
+				newStok = stokProduk - item.Kuantitas
+			}
 			if err := s.produkRepository.UpdateStok(tx, produk.ID, newStok); err != nil {
-				return fmt.Errorf("Gagal update stok untuk: %s", produk.NamaProduk)
+				return fmt.Errorf("Gagal update stok untuk: %s", nama)
+			}
+
+			if _, ok := details[produk.TokoID]; !ok {
+				tokoIDs = append(tokoIDs, produk.TokoID)
+			}
+			berat[produk.TokoID] += produk.Berat * item.Kuantitas
+			kategoriProduk[produk.ID] = produk.CategoryID
+			if _, ok := namaKategori[produk.CategoryID]; !ok {
+				kategori, _ := s.kategoriRepository.FindByID(produk.CategoryID) // Kategori yang sudah dihapus disimpan tanpa nama
+				namaKategori[produk.CategoryID] = kategori.NamaCategory
 			}
 
 			// Siapkan data DetailTransaksi
-			details = append(details, model.DetailTransaksi{
-				// TransaksiID akan diisi nanti setelah Transaksi dibuat
+			details[produk.TokoID] = append(details[produk.TokoID], model.DetailTransaksi{
+				// TransaksiID akan diisi nanti setelah sub-transaksi dibuat
 				ProductID:   produk.ID,
+				VarianID:    varian.ID,
 				TokoID:      produk.TokoID,
 				Kuantitas:   item.Kuantitas,
+				HargaSatuan: hargaSatuan,
 				HargaTotal:  hargaTotalItem,
+				TierHarga:   tierHarga,
+				HargaJual:   hargaJual,
+				Margin:      margin,
 			})
 
-			logs = append(logs, model.LogProduk{
+			logs[produk.TokoID] = append(logs[produk.TokoID], model.LogProduk{
 				// TransaksiID akan diisi nanti
 				ProductID:     produk.ID,
 				NamaProduk:    produk.NamaProduk,
@@ -95,55 +282,467 @@ func (s *transaksiService) CreateTransaksi(userID uint, request web.TransaksiCre
 				Deskripsi:     produk.Deskripsi,
 				TokoID:        produk.TokoID,
 				CategoryID:    produk.CategoryID,
+				NamaCategory:  namaKategori[produk.CategoryID],
+				Berat:         produk.Berat,
+				VarianID:      varian.ID,
+				SKU:           varian.SKU,
+				NamaVarian:    varian.Nama,
 			})
 		}
 
-		// 3. Buat Transaksi utama
-		kodeInvoice := fmt.Sprintf("INV-%d-%d", userID, time.Now().Unix())
+		if len(perubahan) > 0 {
+			return &PerubahanCheckoutError{Items: perubahan}
+		}
+
+		// 3. Tentukan opsi pengiriman yang dipilih untuk setiap toko
+		pengiriman, err := s.pilihPengiriman(request.Pengiriman, tokoIDs, berat, tujuan)
+		if err != nil {
+			return err
+		}
+		var ongkosKirim uint
+		for _, opsi := range pengiriman {
+			ongkosKirim += opsi.Biaya
+		}
+
+		// 4. Terapkan voucher, potongannya dibagi ke setiap baris DetailTransaksi
+		var lines []*model.DetailTransaksi
+		for _, tokoID := range tokoIDs {
+			for j := range details[tokoID] {
+				lines = append(lines, &details[tokoID][j])
+			}
+		}
+		pemakaian, err := s.voucherService.TerapkanVoucher(tx, userID, request.KodeVoucher, lines, kategoriProduk, now)
+		if err != nil {
+			return err
+		}
+		var diskon uint
+		for _, p := range pemakaian {
+			diskon += p.Jumlah
+		}
+
+		// 5. Buat Transaksi induk
 		transaksi = model.Transaksi{
-			HargaTotal:    hargaTotalTransaksi,
-			KodeInvoice:   kodeInvoice,
-			MethodBayar:   request.MethodBayar,
-			AlamatKirimID: request.AlamatKirim,
-			UserID:        userID,
+			Subtotal:    hargaTotalTransaksi,
+			OngkosKirim: ongkosKirim,
+			Diskon:      diskon,
+			HargaTotal:  hargaTotalTransaksi + ongkosKirim - diskon,
+			KodeInvoice: kodeInvoice,
+			MethodBayar: request.MethodBayar,
+			Dropship:    dropship != nil,
+			UserID:      userID,
 		}
-		
+		transaksi.SetAlamatTujuan(alamat)
+		transaksi.SetStatus(statusAwal, now)
+
 		if err := s.transaksiRepository.Create(tx, &transaksi); err != nil {
 			return errors.New("Gagal membuat transaksi")
 		}
 
-		// 4. Update TransaksiID di details dan logs
-		for i := range details {
-			details[i].TransaksiID = transaksi.ID
+		// Catat potongan voucher pada transaksi induk
+		if len(pemakaian) > 0 {
+			for j := range pemakaian {
+				pemakaian[j].TransaksiID = transaksi.ID
+			}
+			if err := s.voucherService.CatatPemakaian(tx, pemakaian); err != nil {
+				return err
+			}
+			transaksi.Voucher = pemakaian
 		}
-		for i := range logs {
-			logs[i].TransaksiID = transaksi.ID
+
+		// 6. Buat sub-transaksi per toko beserta detail dan log-nya
+		parentID := transaksi.ID
+		for i, tokoID := range tokoIDs {
+			var subtotal, subDiskon uint
+			for _, detail := range details[tokoID] {
+				subtotal += detail.HargaTotal
+				subDiskon += detail.DiskonToko + detail.DiskonPlatform
+			}
+
+			opsi := pengiriman[tokoID]
+			sub := model.Transaksi{
+				ParentID:      &parentID,
+				TokoID:        tokoID,
+				Subtotal:      subtotal,
+				OngkosKirim:   opsi.Biaya,
+				Diskon:        subDiskon,
+				HargaTotal:    subtotal + opsi.Biaya - subDiskon,
+				Kurir:         opsi.Kurir,
+				LayananKirim:  opsi.Layanan,
+				EstimasiKirim: opsi.Estimasi,
+				BeratTotal:    berat[tokoID],
+				KodeInvoice:   fmt.Sprintf("%s-%d", kodeInvoice, i+1),
+				MethodBayar:   request.MethodBayar,
+				Dropship:      transaksi.Dropship,
+				UserID:        userID,
+			}
+			sub.SetAlamatTujuan(alamat)
+			sub.SetStatus(statusAwal, now)
+			if err := s.transaksiRepository.Create(tx, &sub); err != nil {
+				return errors.New("Gagal membuat sub-transaksi")
+			}
+
+			// Simpan LogProduk lebih dulu agar setiap DetailTransaksi menunjuk snapshot-nya
+			for j := range logs[tokoID] {
+				logs[tokoID][j].TransaksiID = sub.ID
+			}
+			if err := s.transaksiRepository.CreateLog(tx, logs[tokoID]); err != nil {
+				return errors.New("Gagal menyimpan log produk")
+			}
+
+			// Simpan DetailTransaksi, baris ke-j berpasangan dengan log ke-j
+			for j := range details[tokoID] {
+				details[tokoID][j].TransaksiID = sub.ID
+				details[tokoID][j].LogProdukID = logs[tokoID][j].ID
+			}
+			if err := s.transaksiRepository.CreateDetail(tx, details[tokoID]); err != nil {
+				return errors.New("Gagal menyimpan detail transaksi")
+			}
+
+			transaksi.SubTransaksi = append(transaksi.SubTransaksi, sub)
 		}
 
-		// 5. Simpan DetailTransaksi
-		if err := s.transaksiRepository.CreateDetail(tx, details); err != nil {
-			return errors.New("Gagal menyimpan detail transaksi")
+		// Langkah tambahan dari pemanggil dijalankan di dalam DB transaction yang sama
+		if finalize != nil {
+			if err := finalize(tx); err != nil {
+				return err
+			}
 		}
 
-		// 6. Simpan LogProduk
-		if err := s.transaksiRepository.CreateLog(tx, logs); err != nil {
-			return errors.New("Gagal menyimpan log produk")
+		// 7. Simpan pembayaran, referensi tagihan diisi setelah tagihan dibuat di provider
+		pembayaran := model.Pembayaran{
+			TransaksiID: transaksi.ID,
+			Provider:    provider.Name(),
+			Method:      transaksi.MethodBayar,
+			Jumlah:      transaksi.HargaTotal,
+			Status:      model.PembayaranPending,
+		}
+		if err := s.pembayaranRepository.Create(tx, &pembayaran); err != nil {
+			return errors.New("Gagal menyimpan data pembayaran")
 		}
+		transaksi.Pembayaran = &pembayaran
 
 		return nil
-	}) 
+	})
 
 	if err != nil {
 		return model.Transaksi{}, err
 	}
 
-	// Kembalikan ID transaksi yang baru dibuat
+	// 8. Buat tagihan di provider setelah pesanan tersimpan. Langkah ini berada di luar DB transaction
+	// yang dapat diulang sehingga satu checkout hanya membuat satu tagihan, dengan kode invoice
+	// sebagai kunci idempotensi di provider.
+	charge, err := provider.CreatePayment(payment.ChargeRequest{
+		KodeInvoice: transaksi.KodeInvoice,
+		Method:      transaksi.MethodBayar,
+		Jumlah:      transaksi.HargaTotal,
+	})
+	if err == nil {
+		err = s.simpanTagihan(transaksi.Pembayaran, charge)
+	}
+	if err != nil {
+		// Pesanan tanpa tagihan tidak dapat dibayar, stok dan voucher-nya dikembalikan
+		if errBatal := s.batalkanCheckout(transaksi.ID); errBatal != nil {
+			return model.Transaksi{}, fmt.Errorf("Gagal membuat tagihan pembayaran: %v, pembatalan pesanan %s gagal: %v", err, transaksi.KodeInvoice, errBatal)
+		}
+		return model.Transaksi{}, errors.New("Gagal membuat tagihan pembayaran: " + err.Error())
+	}
+
+	// Kembalikan transaksi induk yang baru dibuat
 	return transaksi, nil
 }
 
-// GetMyTransactions mengambil semua transaksi milik user
-func (s *transaksiService) GetMyTransactions(userID uint) ([]model.Transaksi, error) {
-	transaksis, err := s.transaksiRepository.FindMyTransactions(userID)
+// simpanTagihan melengkapi pembayaran dengan tagihan dari provider. Referensi yang sudah diisi
+// callback yang datang lebih dulu tidak ditimpa, begitu pula status pembayarannya.
+func (s *transaksiService) simpanTagihan(pembayaran *model.Pembayaran, charge payment.ChargeResult) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		tersimpan, err := s.pembayaranRepository.FindByTransaksiIDForUpdate(tx, pembayaran.TransaksiID)
+		if err != nil {
+			return errors.New("Data pembayaran tidak ditemukan")
+		}
+		if tersimpan.Reference == "" {
+			tersimpan.Reference = charge.Reference
+		}
+		tersimpan.Instruksi = charge.Instruksi
+		if err := s.pembayaranRepository.Update(tx, &tersimpan); err != nil {
+			return errors.New("Gagal menyimpan data pembayaran")
+		}
+		*pembayaran = tersimpan
+		return nil
+	})
+}
+
+// batalkanCheckout membatalkan pesanan yang tagihannya gagal dibuat di provider:
+// stok dan voucher dikembalikan, lalu pembayarannya ditandai gagal
+func (s *transaksiService) batalkanCheckout(trxID uint) error {
+	return helpers.TransactionWithRetry(s.db, checkoutMaxAttempts, func(tx *gorm.DB) error {
+		transaksi, err := s.transaksiRepository.FindByIDForUpdate(tx, trxID)
+		if err != nil {
+			return err
+		}
+		if err := s.cancelTransaksi(tx, &transaksi, nil, 0, "Gagal membuat tagihan pembayaran"); err != nil {
+			return err
+		}
+
+		pembayaran, err := s.pembayaranRepository.FindByTransaksiIDForUpdate(tx, trxID)
+		if err != nil {
+			return err
+		}
+		pembayaran.Status = model.PembayaranFailed
+		return s.pembayaranRepository.Update(tx, &pembayaran)
+	})
+}
+
+// gabungItem menggabungkan baris keranjang dengan produk dan varian yang sama, lalu mengurutkannya
+// berdasarkan ID produk dan ID varian. Pada dropship, harga jual baris yang digabung harus sama.
+func gabungItem(items []web.DetailTransaksiRequest) ([]web.DetailTransaksiRequest, error) {
+	type kunci struct{ produkID, varianID uint }
+	posisi := map[kunci]int{}
+	var hasil []web.DetailTransaksiRequest
+	for _, item := range items {
+		if i, ok := posisi[kunci{item.ProductID, item.VarianID}]; ok {
+			if hasil[i].HargaJual != item.HargaJual {
+				return nil, helpers.Errorf(helpers.ErrTidakValid, "Harga jual tidak valid: produk dengan ID %d memiliki harga jual berbeda", item.ProductID)
+			}
+			if item.HargaSatuan != nil {
+				if hasil[i].HargaSatuan != nil && *hasil[i].HargaSatuan != *item.HargaSatuan {
+					return nil, helpers.Errorf(helpers.ErrTidakValid, "Harga satuan tidak valid: produk dengan ID %d memiliki harga satuan berbeda", item.ProductID)
+				}
+				hasil[i].HargaSatuan = item.HargaSatuan
+			}
+			hasil[i].Kuantitas += item.Kuantitas
+			continue
+		}
+		posisi[kunci{item.ProductID, item.VarianID}] = len(hasil)
+		hasil = append(hasil, item)
+	}
+
+	sort.Slice(hasil, func(i, j int) bool {
+		if hasil[i].ProductID != hasil[j].ProductID {
+			return hasil[i].ProductID < hasil[j].ProductID
+		}
+		return hasil[i].VarianID < hasil[j].VarianID
+	})
+	return hasil, nil
+}
+
+// varianCheckout mengunci varian yang dipilih pada baris checkout. Produk bervarian wajib
+// memilih varian, sedangkan produk tanpa varian menghasilkan VarianProduk kosong.
+func (s *transaksiService) varianCheckout(tx *gorm.DB, produk model.Produk, varianID uint) (model.VarianProduk, error) {
+	if varianID == 0 {
+		jumlah, err := s.produkRepository.CountVarian(tx, produk.ID)
+		if err != nil {
+			return model.VarianProduk{}, err
+		}
+		if jumlah > 0 {
+			return model.VarianProduk{}, helpers.Errorf(helpers.ErrTidakValid, "Varian wajib diisi untuk produk: %s", produk.NamaProduk)
+		}
+		return model.VarianProduk{}, nil
+	}
+
+	varian, err := s.produkRepository.FindVarianForUpdate(tx, produk.ID, varianID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return varian, helpers.Errorf(helpers.ErrTidakValid, "Varian dengan ID %d tidak ditemukan pada produk %s", varianID, produk.NamaProduk)
+		}
+		return varian, err
+	}
+	return varian, nil
+}
+
+// namaItem menggabungkan nama produk dan nama varian, contoh "Hijab Segi Empat (Merah / M)"
+func namaItem(produk model.Produk, varian model.VarianProduk) string {
+	if varian.ID == 0 {
+		return produk.NamaProduk
+	}
+	return produk.NamaProduk + " (" + varian.Nama + ")"
+}
+
+// adaHargaDiharapkan mengecek apakah klien mengirim harga per unit pada salah satu baris
+func adaHargaDiharapkan(items []web.DetailTransaksiRequest) bool {
+	for _, item := range items {
+		if item.HargaSatuan != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// QuoteOngkir menghitung pilihan kurir untuk setiap toko di keranjang sebelum pesanan dibuat
+func (s *transaksiService) QuoteOngkir(userID uint, request web.OngkirQuoteRequest) ([]web.OngkirTokoResponse, error) {
+	if len(request.DetailTrx) == 0 {
+		return nil, helpers.Errorf(helpers.ErrTidakValid, "Detail transaksi wajib diisi")
+	}
+	if request.Dropship != nil && request.Dropship.IDKota == "" {
+		return nil, helpers.Errorf(helpers.ErrTidakValid, "Kota penerima dropship wajib diisi")
+	}
+
+	tujuan, err := s.lokasiTujuan(userID, request.AlamatKirim, request.Dropship)
+	if err != nil {
+		return nil, err
+	}
+
+	// Hitung berat paket per toko
+	var tokoIDs []uint
+	berat := map[uint]uint{}
+	for _, item := range request.DetailTrx {
+		if item.Kuantitas == 0 {
+			return nil, helpers.Errorf(helpers.ErrTidakValid, "Kuantitas tidak valid untuk produk dengan ID %d", item.ProductID)
+		}
+		produk, err := s.produkRepository.FindByID(item.ProductID)
+		if err != nil {
+			return nil, helpers.Errorf(helpers.ErrTidakValid, "Produk dengan ID %d tidak ditemukan", item.ProductID)
+		}
+		if _, ok := berat[produk.TokoID]; !ok {
+			tokoIDs = append(tokoIDs, produk.TokoID)
+		}
+		berat[produk.TokoID] += produk.Berat * item.Kuantitas
+	}
+
+	var quotes []web.OngkirTokoResponse
+	for _, tokoID := range tokoIDs {
+		toko, asal, err := s.lokasiToko(tokoID)
+		if err != nil {
+			return nil, err
+		}
+		options, err := s.shippingProvider.Rates(shipping.RateRequest{Asal: asal, Tujuan: tujuan, BeratGram: berat[tokoID]})
+		if err != nil {
+			return nil, err
+		}
+
+		quote := web.OngkirTokoResponse{
+			TokoID:     toko.ID,
+			NamaToko:   toko.NamaToko,
+			BeratTotal: berat[tokoID],
+			Opsi:       []web.OpsiPengirimanResponse{},
+		}
+		for _, opsi := range options {
+			quote.Opsi = append(quote.Opsi, web.OpsiPengirimanResponse{
+				Kode:     opsi.Kode,
+				Kurir:    opsi.Kurir,
+				Layanan:  opsi.Layanan,
+				Biaya:    opsi.Biaya,
+				Estimasi: opsi.Estimasi,
+			})
+		}
+		quotes = append(quotes, quote)
+	}
+	return quotes, nil
+}
+
+// pilihPengiriman mencocokkan opsi pengiriman pilihan pembeli dengan tarif terbaru untuk setiap toko.
+// Biaya selalu dihitung ulang di server, bukan diambil dari request.
+func (s *transaksiService) pilihPengiriman(pilihan []web.PengirimanRequest, tokoIDs []uint, berat map[uint]uint, tujuan shipping.Lokasi) (map[uint]shipping.RateOption, error) {
+	kode := map[uint]string{}
+	for _, p := range pilihan {
+		if _, ok := berat[p.TokoID]; !ok {
+			return nil, helpers.Errorf(helpers.ErrTidakValid, "Opsi pengiriman tidak valid: toko %d tidak ada di pesanan", p.TokoID)
+		}
+		if _, ok := kode[p.TokoID]; ok {
+			return nil, helpers.Errorf(helpers.ErrTidakValid, "Opsi pengiriman tidak valid: toko %d dipilih lebih dari sekali", p.TokoID)
+		}
+		kode[p.TokoID] = p.Kode
+	}
+
+	result := map[uint]shipping.RateOption{}
+	for _, tokoID := range tokoIDs {
+		if kode[tokoID] == "" {
+			return nil, helpers.Errorf(helpers.ErrTidakValid, "Opsi pengiriman untuk toko %d wajib diisi", tokoID)
+		}
+		_, asal, err := s.lokasiToko(tokoID)
+		if err != nil {
+			return nil, err
+		}
+		options, err := s.shippingProvider.Rates(shipping.RateRequest{Asal: asal, Tujuan: tujuan, BeratGram: berat[tokoID]})
+		if err != nil {
+			return nil, err
+		}
+
+		found := false
+		for _, opsi := range options {
+			if opsi.Kode == kode[tokoID] {
+				result[tokoID], found = opsi, true
+				break
+			}
+		}
+		if !found {
+			return nil, helpers.Errorf(helpers.ErrTidakValid, "Opsi pengiriman %s tidak valid untuk toko %d", kode[tokoID], tokoID)
+		}
+	}
+	return result, nil
+}
+
+// lokasiTujuan menentukan kota tujuan dari alamat milik pembeli atau data penerima dropship
+func (s *transaksiService) lokasiTujuan(userID uint, alamatKirim uint, dropship *web.DropshipRequest) (shipping.Lokasi, error) {
+	alamat, err := s.alamatTujuan(userID, alamatKirim, dropship)
+	if err != nil {
+		return shipping.Lokasi{}, err
+	}
+	return shipping.Lokasi{IDProvinsi: alamat.IDProvinsi, IDKota: alamat.IDKota}, nil
+}
+
+// alamatTujuan mengambil alamat milik pembeli, atau menyusun alamat pelanggan pada pesanan dropship
+func (s *transaksiService) alamatTujuan(userID uint, alamatKirim uint, dropship *web.DropshipRequest) (model.Alamat, error) {
+	if dropship != nil {
+		return model.Alamat{
+			JudulAlamat:  "Dropship",
+			NamaPenerima: dropship.NamaPenerima,
+			NoTelp:       dropship.NoTelp,
+			DetailAlamat: dropship.DetailAlamat,
+			IDProvinsi:   dropship.IDProvinsi,
+			IDKota:       dropship.IDKota,
+		}, nil
+	}
+
+	alamat, err := s.alamatRepository.FindByID(alamatKirim)
+	if err != nil {
+		return alamat, helpers.Errorf(helpers.ErrTidakValid, "Alamat kirim tidak ditemukan")
+	}
+	// Cek kepemilikan alamat
+	if alamat.UserID != userID {
+		return alamat, helpers.Errorf(helpers.ErrAksesDitolak, "Akses ditolak: Alamat kirim bukan milik Anda")
+	}
+	if alamat.IDKota == "" {
+		return alamat, helpers.Errorf(helpers.ErrTidakValid, "Kota pada alamat kirim wajib diisi, perbarui alamat terlebih dahulu")
+	}
+	return alamat, nil
+}
+
+// lokasiToko menentukan kota asal pengiriman toko, mengikuti domisili pemilik bila belum diatur
+func (s *transaksiService) lokasiToko(tokoID uint) (model.Toko, shipping.Lokasi, error) {
+	toko, err := s.tokoRepository.FindByID(tokoID)
+	if err != nil {
+		return toko, shipping.Lokasi{}, helpers.Errorf(helpers.ErrTidakValid, "Toko dengan ID %d tidak ditemukan", tokoID)
+	}
+	asal := shipping.Lokasi{IDProvinsi: toko.IDProvinsi, IDKota: toko.IDKota}
+	if asal.IDKota == "" {
+		if pemilik, err := s.userRepository.FindByID(toko.UserID); err == nil {
+			asal = shipping.Lokasi{IDProvinsi: pemilik.IDProvinsi, IDKota: pemilik.IDKota}
+		}
+	}
+	if asal.IDKota == "" {
+		return toko, asal, helpers.Errorf(helpers.ErrTidakDiizinkan, "Pengiriman tidak diizinkan: kota asal toko %s belum diatur", toko.NamaToko)
+	}
+	return toko, asal, nil
+}
+
+// nextKodeInvoice membuat kode invoice unik dari format yang dikonfigurasi
+func (s *transaksiService) nextKodeInvoice(at time.Time) (string, error) {
+	format := helpers.InvoiceFormat()
+	seq, err := s.invoiceRepository.NextSequence(helpers.InvoiceScope(format, at))
+	if err != nil {
+		return "", errors.New("Gagal membuat kode invoice")
+	}
+	return helpers.FormatInvoice(format, at, seq), nil
+}
+
+// GetMyTransactions mengambil transaksi milik user per halaman; jumlah totalnya diisi ke pagination
+func (s *transaksiService) GetMyTransactions(userID uint, pagination *helpers.Pagination, filterParams map[string]string) ([]model.Transaksi, error) {
+	filter, err := s.parseFilter(filterParams)
+	if err != nil {
+		return nil, err
+	}
+
+	transaksis, err := s.transaksiRepository.FindMyTransactions(userID, pagination, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -155,9 +754,713 @@ func (s *transaksiService) GetMyTransactionByID(userID uint, trxID uint) (model.
 	transaksi, err := s.transaksiRepository.FindMyTransactionByID(userID, trxID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return transaksi, errors.New("Transaksi tidak ditemukan atau bukan milik Anda")
+			return transaksi, helpers.Errorf(helpers.ErrTidakDitemukan, "Transaksi tidak ditemukan atau bukan milik Anda")
 		}
 		return transaksi, err
 	}
 	return transaksi, nil
-}
\ No newline at end of file
+}
+
+// GetInvoicePDF membuat PDF invoice untuk transaksi milik pembeli
+func (s *transaksiService) GetInvoicePDF(userID uint, trxID uint) (model.Transaksi, []byte, error) {
+	transaksi, err := s.GetMyTransactionByID(userID, trxID)
+	if err != nil {
+		return transaksi, nil, err
+	}
+
+	pdf, err := helpers.GenerateInvoicePDF(transaksi)
+	if err != nil {
+		return transaksi, nil, errors.New("Gagal membuat PDF invoice")
+	}
+	return transaksi, pdf, nil
+}
+
+// GetTokoInvoicePDF membuat PDF invoice untuk sub-transaksi milik toko penjual
+func (s *transaksiService) GetTokoInvoicePDF(userID uint, trxID uint) (model.Transaksi, []byte, error) {
+	toko, err := s.tokoRepository.FindByUserID(userID)
+	if err != nil {
+		return model.Transaksi{}, nil, helpers.Errorf(helpers.ErrTidakDitemukan, "Toko Anda tidak ditemukan")
+	}
+
+	transaksi, err := s.transaksiRepository.FindTokoOrderByID(toko.ID, trxID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return transaksi, nil, helpers.Errorf(helpers.ErrTidakDitemukan, "Pesanan tidak ditemukan atau bukan milik toko Anda")
+		}
+		return transaksi, nil, err
+	}
+
+	pdf, err := helpers.GenerateInvoicePDF(transaksi)
+	if err != nil {
+		return transaksi, nil, errors.New("Gagal membuat PDF invoice")
+	}
+	return transaksi, pdf, nil
+}
+
+// GetItemSnapshot mengambil snapshot produk dari satu item transaksi milik pembeli
+func (s *transaksiService) GetItemSnapshot(userID uint, detailID uint) (model.DetailTransaksi, error) {
+	return s.itemSnapshot(detailID, func(detail model.DetailTransaksi) bool {
+		transaksi, err := s.transaksiRepository.FindByID(s.db, detail.TransaksiID)
+		return err == nil && transaksi.UserID == userID
+	})
+}
+
+// GetTokoItemSnapshot mengambil snapshot produk dari satu item pesanan milik toko penjual
+func (s *transaksiService) GetTokoItemSnapshot(userID uint, detailID uint) (model.DetailTransaksi, error) {
+	toko, err := s.tokoRepository.FindByUserID(userID)
+	if err != nil {
+		return model.DetailTransaksi{}, helpers.Errorf(helpers.ErrTidakDitemukan, "Toko Anda tidak ditemukan")
+	}
+	return s.itemSnapshot(detailID, func(detail model.DetailTransaksi) bool {
+		return detail.TokoID == toko.ID
+	})
+}
+
+func (s *transaksiService) itemSnapshot(detailID uint, authorize func(model.DetailTransaksi) bool) (model.DetailTransaksi, error) {
+	detail, err := s.transaksiRepository.FindDetailWithSnapshot(detailID)
+	if err != nil || !authorize(detail) {
+		return model.DetailTransaksi{}, helpers.Errorf(helpers.ErrTidakDitemukan, "Item transaksi tidak ditemukan atau bukan milik Anda")
+	}
+	if detail.LogProdukID == 0 {
+		return model.DetailTransaksi{}, helpers.Errorf(helpers.ErrTidakDitemukan, "Snapshot produk untuk item ini tidak ditemukan")
+	}
+	return detail, nil
+}
+
+// parseFilter mengubah query params menjadi TransaksiFilter
+func (s *transaksiService) parseFilter(filterParams map[string]string) (repository.TransaksiFilter, error) {
+	filter := repository.TransaksiFilter{}
+
+	if status := filterParams["status"]; status != "" {
+		if !model.IsValidStatus(status) {
+			return filter, helpers.Errorf(helpers.ErrTidakValid, "Status tidak dikenal: %s", status)
+		}
+		filter.Status = status
+	}
+	if dari := filterParams["tanggal_dari"]; dari != "" {
+		tanggal, err := time.ParseInLocation("2006-01-02", dari, time.Local)
+		if err != nil {
+			return filter, helpers.Errorf(helpers.ErrTidakValid, "Format tanggal_dari tidak valid, gunakan yyyy-mm-dd")
+		}
+		filter.TanggalDari = tanggal
+	}
+	if sampai := filterParams["tanggal_sampai"]; sampai != "" {
+		tanggal, err := time.ParseInLocation("2006-01-02", sampai, time.Local)
+		if err != nil {
+			return filter, helpers.Errorf(helpers.ErrTidakValid, "Format tanggal_sampai tidak valid, gunakan yyyy-mm-dd")
+		}
+		filter.TanggalSampai = tanggal.AddDate(0, 0, 1) // Inklusif sampai akhir hari
+	}
+	filter.KodeInvoice = filterParams["kode_invoice"]
+	filter.MethodBayar = filterParams["method_bayar"]
+	if minTotal := filterParams["min_total"]; minTotal != "" {
+		nilai, err := strconv.ParseUint(minTotal, 10, 64)
+		if err != nil {
+			return filter, helpers.Errorf(helpers.ErrTidakValid, "min_total tidak valid")
+		}
+		filter.MinTotal = uint(nilai)
+	}
+	if maxTotal := filterParams["max_total"]; maxTotal != "" {
+		nilai, err := strconv.ParseUint(maxTotal, 10, 64)
+		if err != nil {
+			return filter, helpers.Errorf(helpers.ErrTidakValid, "max_total tidak valid")
+		}
+		filter.MaxTotal = uint(nilai)
+	}
+	if filter.MaxTotal != 0 && filter.MinTotal > filter.MaxTotal {
+		return filter, helpers.Errorf(helpers.ErrTidakValid, "min_total tidak valid: lebih besar dari max_total")
+	}
+	return filter, nil
+}
+
+// GetTokoOrders mengambil pesanan masuk untuk toko milik user
+func (s *transaksiService) GetTokoOrders(userID uint, pagination *helpers.Pagination, filterParams map[string]string) ([]model.Transaksi, error) {
+	toko, err := s.tokoRepository.FindByUserID(userID)
+	if err != nil {
+		return nil, helpers.Errorf(helpers.ErrTidakDitemukan, "Toko Anda tidak ditemukan")
+	}
+
+	filter, err := s.parseFilter(filterParams)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.transaksiRepository.FindTokoOrders(toko.ID, pagination, filter)
+}
+
+// UpdateStatusByBuyer memindahkan status pesanan oleh pembeli (konfirmasi diterima & selesai)
+func (s *transaksiService) UpdateStatusByBuyer(userID uint, trxID uint, request web.TransaksiStatusUpdateRequest) (model.Transaksi, error) {
+	return s.updateStatus(trxID, request, buyerStatusTargets, func(transaksi model.Transaksi) error {
+		if transaksi.UserID != userID {
+			return helpers.Errorf(helpers.ErrTidakDitemukan, "Transaksi tidak ditemukan atau bukan milik Anda")
+		}
+		return nil
+	})
+}
+
+// UpdateStatusBySeller memindahkan status pesanan oleh pemilik toko
+func (s *transaksiService) UpdateStatusBySeller(userID uint, trxID uint, request web.TransaksiStatusUpdateRequest) (model.Transaksi, error) {
+	toko, err := s.tokoRepository.FindByUserID(userID)
+	if err != nil {
+		return model.Transaksi{}, helpers.Errorf(helpers.ErrTidakDitemukan, "Toko Anda tidak ditemukan")
+	}
+
+	if request.Status == model.StatusShipped && request.NoResi == "" {
+		return model.Transaksi{}, helpers.Errorf(helpers.ErrTidakValid, "Nomor resi wajib diisi saat mengirim pesanan")
+	}
+
+	return s.updateStatus(trxID, request, sellerStatusTargets, func(transaksi model.Transaksi) error {
+		if transaksi.TokoID != toko.ID {
+			return helpers.Errorf(helpers.ErrAksesDitolak, "Akses ditolak: Pesanan ini bukan milik toko Anda")
+		}
+		return nil
+	})
+}
+
+// updateStatus menjalankan transisi status yang sudah dijaga di dalam satu DB transaction
+func (s *transaksiService) updateStatus(trxID uint, request web.TransaksiStatusUpdateRequest, allowed []string, authorize func(model.Transaksi) error) (model.Transaksi, error) {
+	if !model.IsValidStatus(request.Status) {
+		return model.Transaksi{}, helpers.Errorf(helpers.ErrTidakValid, "Status tidak dikenal: %s", request.Status)
+	}
+	if !containsStatus(allowed, request.Status) {
+		return model.Transaksi{}, helpers.Errorf(helpers.ErrAksesDitolak, "Akses ditolak: Anda tidak dapat mengubah status menjadi %s", request.Status)
+	}
+
+	var transaksi model.Transaksi
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var parent *model.Transaksi
+		var err error
+		transaksi, parent, err = s.lockTransaksi(tx, trxID)
+		if err != nil {
+			return err
+		}
+
+		if err := authorize(transaksi); err != nil {
+			return err
+		}
+
+		// Status transaksi induk mengikuti sub-transaksinya
+		if transaksi.ParentID == nil {
+			children, err := s.transaksiRepository.FindChildrenForUpdate(tx, transaksi.ID)
+			if err != nil {
+				return err
+			}
+			if len(children) > 0 {
+				return helpers.Errorf(helpers.ErrTidakDiizinkan, "Transisi status tidak diizinkan: ubah status melalui sub-pesanan per toko")
+			}
+		}
+
+		if !model.CanTransition(transaksi.Status, request.Status) {
+			return helpers.Errorf(helpers.ErrTidakDiizinkan, "Transisi status tidak diizinkan: dari %s ke %s", transaksi.Status, request.Status)
+		}
+
+		transaksi.SetStatus(request.Status, time.Now())
+		if request.Status == model.StatusShipped {
+			transaksi.NoResi = request.NoResi
+		}
+
+		if err := s.transaksiRepository.Update(tx, &transaksi); err != nil {
+			return errors.New("Gagal memperbarui status transaksi")
+		}
+
+		// Pesanan selesai: saldo toko dikredit di ledger
+		if transaksi.Status == model.StatusCompleted {
+			if err := s.ledgerService.PostPenjualan(tx, transaksi); err != nil {
+				return err
+			}
+		}
+		return s.syncParentStatus(tx, parent)
+	})
+
+	if err != nil {
+		return model.Transaksi{}, err
+	}
+	return transaksi, nil
+}
+
+// CancelByBuyer membatalkan pesanan milik pembeli yang belum dikirim dan mengembalikan stok produk.
+// Membatalkan transaksi induk berarti membatalkan seluruh sub-transaksinya.
+// Pesanan yang sudah dibayar dicatat sebagai refund terhadap pembayarannya.
+func (s *transaksiService) CancelByBuyer(userID uint, trxID uint, request web.TransaksiCancelRequest) (model.Transaksi, error) {
+	if request.Alasan == "" {
+		return model.Transaksi{}, helpers.Errorf(helpers.ErrTidakValid, "Alasan pembatalan wajib diisi")
+	}
+
+	var transaksi model.Transaksi
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var parent *model.Transaksi
+		var err error
+		transaksi, parent, err = s.lockTransaksi(tx, trxID)
+		if err != nil || transaksi.UserID != userID {
+			return helpers.Errorf(helpers.ErrTidakDitemukan, "Transaksi tidak ditemukan atau bukan milik Anda")
+		}
+
+		return s.cancelTransaksi(tx, &transaksi, parent, userID, request.Alasan)
+	})
+
+	if err != nil {
+		return model.Transaksi{}, err
+	}
+	return transaksi, nil
+}
+
+// lockTransaksi mengunci transaksi beserta induknya (jika ada).
+// Induk selalu dikunci lebih dulu agar urutan penguncian konsisten.
+func (s *transaksiService) lockTransaksi(tx *gorm.DB, trxID uint) (model.Transaksi, *model.Transaksi, error) {
+	transaksi, err := s.transaksiRepository.FindByID(tx, trxID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return transaksi, nil, helpers.Errorf(helpers.ErrTidakDitemukan, "Transaksi tidak ditemukan")
+		}
+		return transaksi, nil, err
+	}
+
+	var parent *model.Transaksi
+	if transaksi.ParentID != nil {
+		induk, err := s.transaksiRepository.FindByIDForUpdate(tx, *transaksi.ParentID)
+		if err != nil {
+			return transaksi, nil, err
+		}
+		parent = &induk
+	}
+
+	transaksi, err = s.transaksiRepository.FindByIDForUpdate(tx, trxID)
+	return transaksi, parent, err
+}
+
+// syncParentStatus memperbarui status transaksi induk berdasarkan status sub-transaksinya
+func (s *transaksiService) syncParentStatus(tx *gorm.DB, parent *model.Transaksi) error {
+	if parent == nil {
+		return nil
+	}
+
+	children, err := s.transaksiRepository.FindChildrenForUpdate(tx, parent.ID)
+	if err != nil {
+		return err
+	}
+
+	status, ok := model.AggregateStatus(children)
+	if !ok || status == parent.Status {
+		return nil
+	}
+
+	now := time.Now()
+	parent.SetStatus(status, now)
+	if err := s.transaksiRepository.Update(tx, parent); err != nil {
+		return errors.New("Gagal memperbarui status transaksi induk")
+	}
+
+	// Seluruh sub-pesanan batal: voucher platform ikut dikembalikan
+	if status == model.StatusCancelled {
+		if err := s.voucherService.LepasPemakaian(tx, parent.ID); err != nil {
+			return err
+		}
+	}
+
+	// Pembayaran COD dianggap lunas setelah seluruh pesanan selesai
+	if status == model.StatusCompleted && parent.MethodBayar == payment.MethodCOD {
+		pembayaran, err := s.pembayaranRepository.FindByTransaksiIDForUpdate(tx, parent.ID)
+		if err != nil {
+			return errors.New("Data pembayaran tidak ditemukan")
+		}
+		pembayaran.Status = model.PembayaranPaid
+		pembayaran.PaidAt = &now
+		if err := s.pembayaranRepository.Update(tx, &pembayaran); err != nil {
+			return errors.New("Gagal memperbarui data pembayaran")
+		}
+	}
+	return nil
+}
+
+// cancelTransaksi membatalkan transaksi yang sudah dikunci dan mengembalikan stok setiap item.
+// Untuk transaksi induk, sub-transaksi yang sudah final dilewati dan sisanya harus masih dapat dibatalkan.
+// Harus dipanggil di dalam DB transaction.
+func (s *transaksiService) cancelTransaksi(tx *gorm.DB, transaksi *model.Transaksi, parent *model.Transaksi, cancelledBy uint, alasan string) error {
+	if transaksi.ParentID != nil {
+		if err := s.cancelOne(tx, transaksi, cancelledBy, alasan); err != nil {
+			return err
+		}
+		if err := s.kurangiTagihan(tx, *transaksi.ParentID, transaksi.HargaTotal); err != nil {
+			return err
+		}
+		return s.syncParentStatus(tx, parent)
+	}
+
+	children, err := s.transaksiRepository.FindChildrenForUpdate(tx, transaksi.ID)
+	if err != nil {
+		return err
+	}
+	if len(children) == 0 {
+		return s.cancelOne(tx, transaksi, cancelledBy, alasan)
+	}
+
+	// Sub-pesanan yang sudah final (misalnya sudah dibatalkan sendiri) dilewati
+	dibatalkan := 0
+	for _, child := range children {
+		if model.IsFinalStatus(child.Status) {
+			continue
+		}
+		if !model.CanTransition(child.Status, model.StatusCancelled) {
+			return helpers.Errorf(helpers.ErrTidakDiizinkan, "Transisi status tidak diizinkan: sub-pesanan %s berstatus %s tidak dapat dibatalkan", child.KodeInvoice, child.Status)
+		}
+		dibatalkan++
+	}
+	if dibatalkan == 0 {
+		return helpers.Errorf(helpers.ErrTidakDiizinkan, "Transisi status tidak diizinkan: pesanan berstatus %s tidak dapat dibatalkan", transaksi.Status)
+	}
+	for i := range children {
+		if model.IsFinalStatus(children[i].Status) {
+			continue
+		}
+		if err := s.cancelOne(tx, &children[i], cancelledBy, alasan); err != nil {
+			return err
+		}
+	}
+
+	// Status induk mengikuti sub-pesanannya yang kini seluruhnya final
+	status, _ := model.AggregateStatus(children)
+	transaksi.SetStatus(status, time.Now())
+	if status == model.StatusCancelled {
+		transaksi.CancelledBy = cancelledBy
+		transaksi.CancelReason = alasan
+	}
+	if err := s.transaksiRepository.Update(tx, transaksi); err != nil {
+		return errors.New("Gagal membatalkan transaksi")
+	}
+	if status == model.StatusCancelled {
+		return s.voucherService.LepasPemakaian(tx, transaksi.ID)
+	}
+	return nil
+}
+
+// cancelOne membatalkan satu transaksi yang memiliki DetailTransaksi
+func (s *transaksiService) cancelOne(tx *gorm.DB, transaksi *model.Transaksi, cancelledBy uint, alasan string) error {
+	if !model.CanTransition(transaksi.Status, model.StatusCancelled) {
+		return helpers.Errorf(helpers.ErrTidakDiizinkan, "Transisi status tidak diizinkan: pesanan berstatus %s tidak dapat dibatalkan", transaksi.Status)
+	}
+
+	if err := s.restoreStok(tx, transaksi.ID); err != nil {
+		return err
+	}
+	if err := s.catatRefundPembatalan(tx, transaksi); err != nil {
+		return err
+	}
+
+	// Kuota voucher toko dikembalikan, voucher platform dilepas saat seluruh pesanan batal
+	var err error
+	if transaksi.ParentID != nil {
+		err = s.voucherService.LepasPemakaianToko(tx, *transaksi.ParentID, transaksi.TokoID)
+	} else {
+		err = s.voucherService.LepasPemakaian(tx, transaksi.ID)
+	}
+	if err != nil {
+		return err
+	}
+
+	transaksi.SetStatus(model.StatusCancelled, time.Now())
+	transaksi.CancelledBy = cancelledBy
+	transaksi.CancelReason = alasan
+
+	if err := s.transaksiRepository.Update(tx, transaksi); err != nil {
+		return errors.New("Gagal membatalkan transaksi")
+	}
+	return nil
+}
+
+// catatRefundPembatalan mencatat refund sebesar total pesanan yang dibatalkan bila pembayarannya sudah lunas.
+// Seperti refund retur, refund dicatat terhadap pembayaran transaksi induk.
+func (s *transaksiService) catatRefundPembatalan(tx *gorm.DB, transaksi *model.Transaksi) error {
+	indukID := transaksi.ID
+	if transaksi.ParentID != nil {
+		indukID = *transaksi.ParentID
+	}
+	pembayaran, err := s.pembayaranRepository.FindByTransaksiIDForUpdate(tx, indukID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if pembayaran.Status != model.PembayaranPaid {
+		return nil
+	}
+
+	refund := model.Refund{
+		TransaksiID:  transaksi.ID,
+		PembayaranID: pembayaran.ID,
+		Jumlah:       transaksi.HargaTotal,
+		Status:       model.RefundPending,
+	}
+	if err := s.pembayaranRepository.CreateRefund(tx, &refund); err != nil {
+		return errors.New("Gagal menyimpan refund")
+	}
+	return nil
+}
+
+// kurangiTagihan mengurangi jumlah tagihan yang belum dibayar sebesar sub-pesanan yang dibatalkan,
+// sehingga callback pembayaran hanya melunasi sisa yang masih harus dibayar
+func (s *transaksiService) kurangiTagihan(tx *gorm.DB, indukID uint, jumlah uint) error {
+	pembayaran, err := s.pembayaranRepository.FindByTransaksiIDForUpdate(tx, indukID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if pembayaran.Status != model.PembayaranPending {
+		return nil
+	}
+
+	if jumlah > pembayaran.Jumlah {
+		jumlah = pembayaran.Jumlah
+	}
+	pembayaran.Jumlah -= jumlah
+	if err := s.pembayaranRepository.Update(tx, &pembayaran); err != nil {
+		return errors.New("Gagal memperbarui data pembayaran")
+	}
+	return nil
+}
+
+// restoreStok menambahkan kembali kuantitas setiap DetailTransaksi ke stok produk
+func (s *transaksiService) restoreStok(tx *gorm.DB, trxID uint) error {
+	details, err := s.transaksiRepository.FindDetailsByTransaksiID(tx, trxID)
+	if err != nil {
+		return errors.New("Gagal mengambil detail transaksi")
+	}
+
+	for _, detail := range details {
+		if err := kembalikanStok(tx, s.produkRepository, detail.ProductID, detail.VarianID, detail.Kuantitas); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// kembalikanStok menambahkan kuantitas ke stok produk dan, bila ada, ke stok variannya.
+// Produk atau varian yang sudah dihapus dilewati.
+func kembalikanStok(tx *gorm.DB, produkRepository repository.ProdukRepository, produkID uint, varianID uint, kuantitas uint) error {
+	produk, err := produkRepository.FindByIDForUpdate(tx, produkID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil // Produk sudah dihapus, tidak ada stok yang dikembalikan
+		}
+		return err
+	}
+
+	if varianID != 0 {
+		varian, err := produkRepository.FindVarianForUpdate(tx, produk.ID, varianID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil // Varian sudah dihapus, stok produk mengikuti varian yang tersisa
+			}
+			return err
+		}
+		if err := produkRepository.UpdateStokVarian(tx, varian.ID, varian.Stok+kuantitas); err != nil {
+			return fmt.Errorf("Gagal mengembalikan stok untuk: %s", namaItem(produk, varian))
+		}
+	}
+
+	if err := produkRepository.UpdateStok(tx, produk.ID, produk.Stok+kuantitas); err != nil {
+		return fmt.Errorf("Gagal mengembalikan stok untuk: %s", produk.NamaProduk)
+	}
+	return nil
+}
+
+// ErrCallbackTidakSesuai dikembalikan saat callback berasal dari provider lain
+// atau merujuk tagihan lain dari yang tersimpan pada pembayaran
+var ErrCallbackTidakSesuai error = &helpers.ServiceError{Jenis: helpers.ErrTidakValid, Pesan: "Callback pembayaran tidak sesuai dengan tagihan"}
+
+// ConfirmPayment menerapkan hasil callback dari provider 'providerName' ke transaksi induk.
+// Pembayaran lunas memindahkan transaksi dan sub-transaksinya ke 'paid',
+// pembayaran gagal membatalkan transaksi dan mengembalikan stok.
+func (s *transaksiService) ConfirmPayment(providerName string, result payment.CallbackResult) (model.Transaksi, error) {
+	var transaksi model.Transaksi
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		transaksi, err = s.transaksiRepository.FindByKodeInvoiceForUpdate(tx, result.KodeInvoice)
+		if err != nil {
+			return helpers.Errorf(helpers.ErrTidakDitemukan, "Transaksi dengan invoice %s tidak ditemukan", result.KodeInvoice)
+		}
+
+		pembayaran, err := s.pembayaranRepository.FindByTransaksiIDForUpdate(tx, transaksi.ID)
+		if err != nil {
+			return helpers.Errorf(helpers.ErrTidakDitemukan, "Data pembayaran tidak ditemukan")
+		}
+		if err := cocokkanCallback(pembayaran, providerName, result); err != nil {
+			return err
+		}
+
+		// Callback yang dikirim ulang tidak diproses dua kali
+		if pembayaran.Status == result.Status {
+			return nil
+		}
+		if pembayaran.Status != model.PembayaranPending {
+			return helpers.Errorf(helpers.ErrTidakDiizinkan, "Transisi status tidak diizinkan: pembayaran sudah berstatus %s", pembayaran.Status)
+		}
+
+		now := time.Now()
+		switch result.Status {
+		case payment.StatusPaid:
+			if result.Jumlah != pembayaran.Jumlah {
+				return helpers.Errorf(helpers.ErrTidakValid, "Jumlah pembayaran tidak sesuai: tagihan %d, dibayar %d", pembayaran.Jumlah, result.Jumlah)
+			}
+			if err := s.markPaid(tx, &transaksi, now); err != nil {
+				return err
+			}
+			pembayaran.Status = model.PembayaranPaid
+			pembayaran.PaidAt = &now
+		case payment.StatusFailed:
+			if err := s.cancelTransaksi(tx, &transaksi, nil, 0, "Pembayaran gagal"); err != nil {
+				return err
+			}
+			pembayaran.Status = model.PembayaranFailed
+		}
+
+		if pembayaran.Reference == "" {
+			pembayaran.Reference = result.Reference
+		}
+		if err := s.pembayaranRepository.Update(tx, &pembayaran); err != nil {
+			return errors.New("Gagal memperbarui data pembayaran")
+		}
+		return nil
+	})
+
+	if err != nil {
+		return model.Transaksi{}, err
+	}
+	return transaksi, nil
+}
+
+// cocokkanCallback memastikan callback dikirim provider yang membuat tagihan dan merujuk tagihan
+// yang sama, sehingga callback sah dari provider lain tidak dapat melunasi pesanan ini.
+// Referensi hanya dapat dicocokkan setelah tagihan di provider tersimpan.
+func cocokkanCallback(pembayaran model.Pembayaran, providerName string, result payment.CallbackResult) error {
+	if pembayaran.Provider != providerName {
+		return fmt.Errorf("%w: tagihan dibuat melalui provider %s", ErrCallbackTidakSesuai, pembayaran.Provider)
+	}
+	if pembayaran.Reference != "" && result.Reference != pembayaran.Reference {
+		return fmt.Errorf("%w: referensi %q berbeda dengan tagihan", ErrCallbackTidakSesuai, result.Reference)
+	}
+	return nil
+}
+
+// markPaid memindahkan transaksi induk yang sudah dikunci beserta sub-transaksinya ke status 'paid'.
+// Sub-transaksi yang sudah final (misalnya dibatalkan sebelum dibayar) tidak ikut berubah
+// karena stoknya sudah dikembalikan.
+func (s *transaksiService) markPaid(tx *gorm.DB, transaksi *model.Transaksi, at time.Time) error {
+	if !model.CanTransition(transaksi.Status, model.StatusPaid) {
+		return helpers.Errorf(helpers.ErrTidakDiizinkan, "Transisi status tidak diizinkan: dari %s ke %s", transaksi.Status, model.StatusPaid)
+	}
+
+	children, err := s.transaksiRepository.FindChildrenForUpdate(tx, transaksi.ID)
+	if err != nil {
+		return err
+	}
+	for i := range children {
+		if model.IsFinalStatus(children[i].Status) {
+			continue
+		}
+		if !model.CanTransition(children[i].Status, model.StatusPaid) {
+			return helpers.Errorf(helpers.ErrTidakDiizinkan, "Transisi status tidak diizinkan: sub-pesanan %s berstatus %s", children[i].KodeInvoice, children[i].Status)
+		}
+		children[i].SetStatus(model.StatusPaid, at)
+		if err := s.transaksiRepository.Update(tx, &children[i]); err != nil {
+			return errors.New("Gagal memperbarui status sub-transaksi")
+		}
+	}
+
+	transaksi.SetStatus(model.StatusPaid, at)
+	if err := s.transaksiRepository.Update(tx, transaksi); err != nil {
+		return errors.New("Gagal memperbarui status transaksi")
+	}
+	return nil
+}
+
+// ExpireNextUnpaid mengklaim satu transaksi induk yang belum dibayar sejak sebelum 'batas'
+// (selain ID dalam 'lewati'), menandainya expired, dan mengembalikan stoknya dalam DB transaction
+// tersendiri agar kunci produk tidak ditahan lama. Mengembalikan ID transaksi yang diproses,
+// atau 0 bila tidak ada lagi transaksi yang perlu dikedaluwarsakan. Error dengan ID bukan 0 berarti
+// transaksi tersebut gagal diproses dan seluruh perubahannya dibatalkan.
+func (s *transaksiService) ExpireNextUnpaid(batas time.Time, lewati []uint) (uint, error) {
+	var trxID uint
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		transaksi, err := s.transaksiRepository.ClaimUnpaidBefore(tx, batas, lewati)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		trxID = transaksi.ID
+		return s.expireTransaksi(tx, &transaksi)
+	})
+	return trxID, err
+}
+
+// expireTransaksi memindahkan transaksi induk yang sudah dikunci beserta sub-transaksinya
+// ke status 'expired', mengembalikan stok, dan menutup tagihan pembayarannya
+func (s *transaksiService) expireTransaksi(tx *gorm.DB, transaksi *model.Transaksi) error {
+	now := time.Now()
+
+	children, err := s.transaksiRepository.FindChildrenForUpdate(tx, transaksi.ID)
+	if err != nil {
+		return err
+	}
+	if len(children) == 0 {
+		// Transaksi lama tanpa sub-transaksi menyimpan itemnya sendiri
+		if err := s.restoreStok(tx, transaksi.ID); err != nil {
+			return err
+		}
+	}
+	for i := range children {
+		// Sub-pesanan yang sudah dibatalkan pembeli stoknya sudah dikembalikan
+		if model.IsFinalStatus(children[i].Status) {
+			continue
+		}
+		if !model.CanTransition(children[i].Status, model.StatusExpired) {
+			return helpers.Errorf(helpers.ErrTidakDiizinkan, "Transisi status tidak diizinkan: sub-pesanan %s berstatus %s tidak dapat kedaluwarsa", children[i].KodeInvoice, children[i].Status)
+		}
+		if err := s.restoreStok(tx, children[i].ID); err != nil {
+			return err
+		}
+		children[i].SetStatus(model.StatusExpired, now)
+		if err := s.transaksiRepository.Update(tx, &children[i]); err != nil {
+			return errors.New("Gagal memperbarui status sub-transaksi")
+		}
+	}
+
+	transaksi.SetStatus(model.StatusExpired, now)
+	if err := s.transaksiRepository.Update(tx, transaksi); err != nil {
+		return errors.New("Gagal memperbarui status transaksi")
+	}
+
+	// Kuota voucher yang dipakai pesanan dikembalikan
+	if err := s.voucherService.LepasPemakaian(tx, transaksi.ID); err != nil {
+		return err
+	}
+
+	// Callback pembayaran yang datang terlambat akan ditolak karena tagihan sudah ditutup
+	pembayaran, err := s.pembayaranRepository.FindByTransaksiIDForUpdate(tx, transaksi.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if pembayaran.Status == model.PembayaranPending {
+		pembayaran.Status = model.PembayaranExpired
+		if err := s.pembayaranRepository.Update(tx, &pembayaran); err != nil {
+			return errors.New("Gagal memperbarui data pembayaran")
+		}
+	}
+	return nil
+}
+
+func containsStatus(statuses []string, status string) bool {
+	for _, st := range statuses {
+		if st == status {
+			return true
+		}
+	}
+	return false
+}
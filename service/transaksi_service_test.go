@@ -0,0 +1,40 @@
+package service
+
+import (
+	"github.com/Debjth19/go-evermos/model"
+	"github.com/Debjth19/go-evermos/payment"
+
+	"errors"
+	"testing"
+)
+
+func TestCocokkanCallback(t *testing.T) {
+	tersimpan := model.Pembayaran{Provider: "fake", Reference: "FAKE-INV-1"}
+
+	tests := []struct {
+		nama       string
+		pembayaran model.Pembayaran
+		provider   string
+		reference  string
+		cocok      bool
+	}{
+		{"provider dan referensi sama", tersimpan, "fake", "FAKE-INV-1", true},
+		{"provider berbeda", tersimpan, "lain", "FAKE-INV-1", false},
+		{"referensi berbeda", tersimpan, "fake", "FAKE-INV-2", false},
+		{"referensi kosong", tersimpan, "fake", "", false},
+		{"tagihan belum punya referensi", model.Pembayaran{Provider: "fake"}, "fake", "FAKE-INV-1", true},
+		{"tagihan belum punya referensi, provider berbeda", model.Pembayaran{Provider: "fake"}, "lain", "FAKE-INV-1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.nama, func(t *testing.T) {
+			err := cocokkanCallback(tt.pembayaran, tt.provider, payment.CallbackResult{Reference: tt.reference})
+			if tt.cocok && err != nil {
+				t.Fatalf("ingin cocok, didapat error %v", err)
+			}
+			if !tt.cocok && !errors.Is(err, ErrCallbackTidakSesuai) {
+				t.Fatalf("ingin ErrCallbackTidakSesuai, didapat %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"github.com/Debjth19/go-evermos/helpers"
+	"github.com/Debjth19/go-evermos/model"
+	"github.com/Debjth19/go-evermos/model/web"
+	"github.com/Debjth19/go-evermos/repository"
+
+	"errors"
+	"mime/multipart"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type VarianService interface {
+	CreateOpsi(userID uint, produkID uint, request web.OpsiCreateRequest) (model.OpsiProduk, error)
+	DeleteOpsi(userID uint, produkID uint, opsiID uint) error
+	CreateVarian(userID uint, produkID uint, request web.VarianCreateRequest, file *multipart.FileHeader) (model.VarianProduk, error)
+	UpdateVarian(userID uint, produkID uint, varianID uint, request web.VarianUpdateRequest, file *multipart.FileHeader) (model.VarianProduk, error)
+	DeleteVarian(userID uint, produkID uint, varianID uint) error
+}
+
+type varianService struct {
+	varianRepository repository.VarianRepository
+	produkRepository repository.ProdukRepository
+	tokoRepository   repository.TokoRepository // Dibutuhkan untuk otorisasi
+}
+
+func NewVarianService(varianRepo repository.VarianRepository, produkRepo repository.ProdukRepository, tokoRepo repository.TokoRepository) VarianService {
+	return &varianService{
+		varianRepository: varianRepo,
+		produkRepository: produkRepo,
+		tokoRepository:   tokoRepo,
+	}
+}
+
+// verifyProduk memastikan produk ada dan dimiliki toko user
+func (s *varianService) verifyProduk(userID uint, produkID uint) (model.Produk, error) {
+	toko, err := s.tokoRepository.FindByUserID(userID)
+	if err != nil {
+		return model.Produk{}, helpers.Errorf(helpers.ErrTidakDitemukan, "Toko Anda tidak ditemukan")
+	}
+
+	produk, err := s.produkRepository.FindByID(produkID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return produk, helpers.Errorf(helpers.ErrTidakDitemukan, "Produk tidak ditemukan")
+		}
+		return produk, err
+	}
+	if produk.TokoID != toko.ID {
+		return produk, helpers.Errorf(helpers.ErrAksesDitolak, "Akses ditolak: Anda bukan pemilik produk ini")
+	}
+	return produk, nil
+}
+
+// CreateOpsi menambahkan kelompok opsi beserta nilainya pada produk.
+// Opsi hanya dapat ditambahkan sebelum produk memiliki varian.
+func (s *varianService) CreateOpsi(userID uint, produkID uint, request web.OpsiCreateRequest) (model.OpsiProduk, error) {
+	produk, err := s.verifyProduk(userID, produkID)
+	if err != nil {
+		return model.OpsiProduk{}, err
+	}
+	if len(produk.Varian) > 0 {
+		return model.OpsiProduk{}, helpers.Errorf(helpers.ErrTidakDiizinkan, "Penambahan opsi tidak diizinkan: hapus varian produk terlebih dahulu")
+	}
+
+	nama := strings.TrimSpace(request.Nama)
+	if nama == "" || len(request.Nilai) == 0 {
+		return model.OpsiProduk{}, helpers.Errorf(helpers.ErrTidakValid, "Nama dan nilai opsi wajib diisi")
+	}
+	for _, o := range produk.Opsi {
+		if strings.EqualFold(o.Nama, nama) {
+			return model.OpsiProduk{}, helpers.Errorf(helpers.ErrTidakValid, "Nama opsi tidak valid: opsi %s sudah ada", nama)
+		}
+	}
+
+	opsi := model.OpsiProduk{ProductID: produk.ID, Nama: nama}
+	terpakai := map[string]bool{}
+	for _, nilai := range request.Nilai {
+		nilai = strings.TrimSpace(nilai)
+		if nilai == "" || terpakai[strings.ToLower(nilai)] {
+			return model.OpsiProduk{}, helpers.Errorf(helpers.ErrTidakValid, "Nilai opsi tidak valid: nilai kosong atau ganda")
+		}
+		terpakai[strings.ToLower(nilai)] = true
+		opsi.Nilai = append(opsi.Nilai, model.NilaiOpsiProduk{Nilai: nilai})
+	}
+
+	if err := s.varianRepository.CreateOpsi(&opsi); err != nil {
+		return opsi, errors.New("Gagal menyimpan opsi produk")
+	}
+	return opsi, nil
+}
+
+// DeleteOpsi menghapus kelompok opsi. Opsi hanya dapat dihapus bila produk belum memiliki varian.
+func (s *varianService) DeleteOpsi(userID uint, produkID uint, opsiID uint) error {
+	produk, err := s.verifyProduk(userID, produkID)
+	if err != nil {
+		return err
+	}
+
+	ditemukan := false
+	for _, o := range produk.Opsi {
+		ditemukan = ditemukan || o.ID == opsiID
+	}
+	if !ditemukan {
+		return helpers.Errorf(helpers.ErrTidakDitemukan, "Opsi produk tidak ditemukan")
+	}
+	if len(produk.Varian) > 0 {
+		return helpers.Errorf(helpers.ErrTidakDiizinkan, "Penghapusan opsi tidak diizinkan: hapus varian produk terlebih dahulu")
+	}
+	return s.varianRepository.DeleteOpsi(opsiID)
+}
+
+// CreateVarian menambahkan varian dengan satu nilai untuk setiap opsi produk.
+// Setelah varian pertama dibuat, stok produk mengikuti jumlah stok variannya.
+func (s *varianService) CreateVarian(userID uint, produkID uint, request web.VarianCreateRequest, file *multipart.FileHeader) (model.VarianProduk, error) {
+	produk, err := s.verifyProduk(userID, produkID)
+	if err != nil {
+		return model.VarianProduk{}, err
+	}
+	if len(produk.Opsi) == 0 {
+		return model.VarianProduk{}, helpers.Errorf(helpers.ErrTidakValid, "Opsi produk wajib diisi sebelum menambah varian")
+	}
+
+	sku := strings.TrimSpace(request.SKU)
+	if sku == "" {
+		return model.VarianProduk{}, helpers.Errorf(helpers.ErrTidakValid, "SKU varian wajib diisi")
+	}
+	if err := s.cekSKU(produk.ID, sku, 0); err != nil {
+		return model.VarianProduk{}, err
+	}
+
+	nilaiOpsi, err := pilihNilaiOpsi(produk.Opsi, request.NilaiOpsiIDs)
+	if err != nil {
+		return model.VarianProduk{}, err
+	}
+	nama := namaVarian(nilaiOpsi)
+	for _, v := range produk.Varian {
+		if v.Nama == nama {
+			return model.VarianProduk{}, helpers.Errorf(helpers.ErrTidakValid, "Nilai opsi tidak valid: varian %s sudah ada", nama)
+		}
+	}
+
+	varian := model.VarianProduk{
+		ProductID:     produk.ID,
+		SKU:           sku,
+		Nama:          nama,
+		HargaReseler:  request.HargaReseler,
+		HargaKonsumen: request.HargaKonsumen,
+		Stok:          request.Stok,
+		NilaiOpsi:     nilaiOpsi,
+	}
+
+	if file != nil {
+		urls, err := helpers.SaveUploadedFiles([]*multipart.FileHeader{file}, helpers.ProdukImagesPath)
+		if err != nil {
+			return varian, errors.New("Gagal menyimpan foto: " + err.Error())
+		}
+		varian.UrlFoto = urls[0]
+	}
+
+	if err := s.varianRepository.Create(&varian); err != nil {
+		helpers.DeleteFiles([]string{varian.UrlFoto}, helpers.ProdukImagesPath)
+		return varian, errors.New("Gagal menyimpan varian produk")
+	}
+	return varian, nil
+}
+
+// UpdateVarian mengubah SKU, harga, stok, atau foto varian. Kombinasi nilai opsi tidak dapat diubah.
+func (s *varianService) UpdateVarian(userID uint, produkID uint, varianID uint, request web.VarianUpdateRequest, file *multipart.FileHeader) (model.VarianProduk, error) {
+	varian, err := s.findVarian(userID, produkID, varianID)
+	if err != nil {
+		return varian, err
+	}
+
+	// Hanya kolom yang diisi yang disimpan agar stok tidak tertimpa nilai lama
+	perubahan := map[string]interface{}{}
+	if sku := strings.TrimSpace(request.SKU); sku != "" {
+		if err := s.cekSKU(produkID, sku, varian.ID); err != nil {
+			return varian, err
+		}
+		perubahan["sku"] = sku
+	}
+	if request.HargaReseler != nil {
+		perubahan["harga_reseler"] = *request.HargaReseler
+	}
+	if request.HargaKonsumen != nil {
+		perubahan["harga_konsumen"] = *request.HargaKonsumen
+	}
+	if request.Stok != nil {
+		perubahan["stok"] = *request.Stok
+	}
+
+	fotoLama := varian.UrlFoto
+	var fotoBaru string
+	if file != nil {
+		urls, err := helpers.SaveUploadedFiles([]*multipart.FileHeader{file}, helpers.ProdukImagesPath)
+		if err != nil {
+			return varian, errors.New("Gagal menyimpan foto baru: " + err.Error())
+		}
+		fotoBaru = urls[0]
+		perubahan["url_foto"] = fotoBaru
+	}
+
+	if err := s.varianRepository.Update(&varian, perubahan); err != nil {
+		if file != nil {
+			helpers.DeleteFiles([]string{fotoBaru}, helpers.ProdukImagesPath)
+		}
+		return varian, errors.New("Gagal menyimpan varian produk")
+	}
+	if file != nil && fotoLama != "" {
+		helpers.DeleteFiles([]string{fotoLama}, helpers.ProdukImagesPath)
+	}
+	return varian, nil
+}
+
+// DeleteVarian menghapus varian beserta item keranjang yang memilihnya.
+// Transaksi lama tetap menampilkan varian dari snapshot saat checkout.
+func (s *varianService) DeleteVarian(userID uint, produkID uint, varianID uint) error {
+	varian, err := s.findVarian(userID, produkID, varianID)
+	if err != nil {
+		return err
+	}
+	if err := s.varianRepository.Delete(varian); err != nil {
+		return errors.New("Gagal menghapus varian produk")
+	}
+	if varian.UrlFoto != "" {
+		helpers.DeleteFiles([]string{varian.UrlFoto}, helpers.ProdukImagesPath)
+	}
+	return nil
+}
+
+func (s *varianService) findVarian(userID uint, produkID uint, varianID uint) (model.VarianProduk, error) {
+	if _, err := s.verifyProduk(userID, produkID); err != nil {
+		return model.VarianProduk{}, err
+	}
+	varian, err := s.varianRepository.FindByID(varianID)
+	if err != nil || varian.ProductID != produkID {
+		if err == nil || errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.VarianProduk{}, helpers.Errorf(helpers.ErrTidakDitemukan, "Varian produk tidak ditemukan")
+		}
+		return varian, err
+	}
+	return varian, nil
+}
+
+func (s *varianService) cekSKU(produkID uint, sku string, exceptID uint) error {
+	ada, err := s.varianRepository.SKUExists(produkID, sku, exceptID)
+	if err != nil {
+		return err
+	}
+	if ada {
+		return helpers.Errorf(helpers.ErrTidakValid, "SKU tidak valid: %s sudah dipakai varian lain", sku)
+	}
+	return nil
+}
+
+// pilihNilaiOpsi memastikan setiap opsi produk mendapat tepat satu nilai, urut sesuai opsi
+func pilihNilaiOpsi(opsi []model.OpsiProduk, nilaiIDs []uint) ([]model.NilaiOpsiProduk, error) {
+	dipilih := map[uint]bool{}
+	for _, id := range nilaiIDs {
+		dipilih[id] = true
+	}
+
+	var hasil []model.NilaiOpsiProduk
+	for _, o := range opsi {
+		var cocok []model.NilaiOpsiProduk
+		for _, n := range o.Nilai {
+			if dipilih[n.ID] {
+				cocok = append(cocok, n)
+			}
+		}
+		if len(cocok) != 1 {
+			return nil, helpers.Errorf(helpers.ErrTidakValid, "Nilai opsi tidak valid: pilih tepat satu nilai untuk opsi %s", o.Nama)
+		}
+		hasil = append(hasil, cocok[0])
+	}
+	if len(hasil) != len(dipilih) {
+		return nil, helpers.Errorf(helpers.ErrTidakValid, "Nilai opsi tidak valid: terdapat nilai yang bukan milik produk ini")
+	}
+	return hasil, nil
+}
+
+// namaVarian menggabungkan nilai opsi, contoh "Merah / M"
+func namaVarian(nilai []model.NilaiOpsiProduk) string {
+	var bagian []string
+	for _, n := range nilai {
+		bagian = append(bagian, n.Nilai)
+	}
+	return strings.Join(bagian, " / ")
+}
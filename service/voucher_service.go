@@ -0,0 +1,424 @@
+package service
+
+import (
+	"github.com/Debjth19/go-evermos/helpers"
+	"github.com/Debjth19/go-evermos/model"
+	"github.com/Debjth19/go-evermos/model/web"
+	"github.com/Debjth19/go-evermos/repository"
+
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Semua method pengelolaan voucher menerima tokoID pemilik voucher: 0 untuk voucher platform (admin),
+// atau ID toko milik penjual (lihat TokoIDByUser).
+type VoucherService interface {
+	TokoIDByUser(userID uint) (uint, error)
+	CreateVoucher(tokoID uint, request web.VoucherCreateRequest) (model.Voucher, error)
+	GetVouchers(tokoID uint, pagination helpers.Pagination) ([]model.Voucher, error)
+	GetVoucherByID(tokoID uint, voucherID uint) (model.Voucher, uint64, error)
+	UpdateVoucher(tokoID uint, voucherID uint, request web.VoucherUpdateRequest) (model.Voucher, error)
+	GetPemakaian(tokoID uint, voucherID uint, pagination helpers.Pagination) ([]model.PemakaianVoucher, int64, error)
+
+	// TerapkanVoucher dipanggil di dalam DB transaction checkout
+	TerapkanVoucher(tx *gorm.DB, userID uint, kodes []string, details []*model.DetailTransaksi, kategoriProduk map[uint]uint, now time.Time) ([]model.PemakaianVoucher, error)
+	CatatPemakaian(tx *gorm.DB, pemakaian []model.PemakaianVoucher) error
+
+	// LepasPemakaian dan LepasPemakaianToko dipanggil di dalam DB transaction pembatalan
+	LepasPemakaian(tx *gorm.DB, transaksiID uint) error
+	LepasPemakaianToko(tx *gorm.DB, transaksiID uint, tokoID uint) error
+}
+
+type voucherService struct {
+	voucherRepository  repository.VoucherRepository
+	kategoriRepository repository.KategoriRepository // Dibutuhkan untuk validasi batasan kategori
+	tokoRepository     repository.TokoRepository     // Dibutuhkan untuk otorisasi penjual
+}
+
+func NewVoucherService(voucherRepo repository.VoucherRepository, kategoriRepo repository.KategoriRepository, tokoRepo repository.TokoRepository) VoucherService {
+	return &voucherService{
+		voucherRepository:  voucherRepo,
+		kategoriRepository: kategoriRepo,
+		tokoRepository:     tokoRepo,
+	}
+}
+
+// TokoIDByUser mengambil ID toko milik penjual
+func (s *voucherService) TokoIDByUser(userID uint) (uint, error) {
+	toko, err := s.tokoRepository.FindByUserID(userID)
+	if err != nil {
+		return 0, helpers.Errorf(helpers.ErrTidakDitemukan, "Toko Anda tidak ditemukan")
+	}
+	return toko.ID, nil
+}
+
+// CreateVoucher membuat voucher baru milik platform atau toko
+func (s *voucherService) CreateVoucher(tokoID uint, request web.VoucherCreateRequest) (model.Voucher, error) {
+	kode := normalizeKodeVoucher(request.Kode)
+	if kode == "" || strings.Contains(kode, " ") {
+		return model.Voucher{}, helpers.Errorf(helpers.ErrTidakValid, "Kode voucher tidak valid")
+	}
+	if request.Nama == "" {
+		return model.Voucher{}, helpers.Errorf(helpers.ErrTidakValid, "Nama voucher wajib diisi")
+	}
+
+	exists, err := s.voucherRepository.KodeExists(kode)
+	if err != nil {
+		return model.Voucher{}, err
+	}
+	if exists {
+		return model.Voucher{}, helpers.Errorf(helpers.ErrTidakValid, "Kode voucher tidak valid: %s sudah dipakai", kode)
+	}
+
+	kategori, err := s.findKategori(request.KategoriIDs)
+	if err != nil {
+		return model.Voucher{}, err
+	}
+
+	voucher := model.Voucher{
+		Kode:          kode,
+		Nama:          request.Nama,
+		TokoID:        tokoID,
+		Tipe:          request.Tipe,
+		Nilai:         request.Nilai,
+		MaksDiskon:    request.MaksDiskon,
+		MinBelanja:    request.MinBelanja,
+		KuotaTotal:    request.KuotaTotal,
+		KuotaPerUser:  request.KuotaPerUser,
+		BerlakuMulai:  request.BerlakuMulai,
+		BerlakuSampai: request.BerlakuSampai,
+		Aktif:         true,
+		Kategori:      kategori,
+	}
+	if err := validateVoucher(voucher); err != nil {
+		return model.Voucher{}, err
+	}
+
+	if err := s.voucherRepository.Create(&voucher); err != nil {
+		return model.Voucher{}, errors.New("Gagal menyimpan voucher")
+	}
+	return s.voucherRepository.FindByID(voucher.ID)
+}
+
+// GetVouchers mengambil voucher milik platform atau toko
+func (s *voucherService) GetVouchers(tokoID uint, pagination helpers.Pagination) ([]model.Voucher, error) {
+	return s.voucherRepository.FindByToko(tokoID, pagination)
+}
+
+// GetVoucherByID mengambil satu voucher beserta total potongan yang sudah diberikannya
+func (s *voucherService) GetVoucherByID(tokoID uint, voucherID uint) (model.Voucher, uint64, error) {
+	voucher, err := s.findOwned(tokoID, voucherID)
+	if err != nil {
+		return voucher, 0, err
+	}
+
+	totalDiskon, err := s.voucherRepository.SumPemakaian(voucher.ID)
+	if err != nil {
+		return voucher, 0, err
+	}
+	return voucher, totalDiskon, nil
+}
+
+// UpdateVoucher mengubah voucher. Kode dan tipe tidak dapat diubah.
+func (s *voucherService) UpdateVoucher(tokoID uint, voucherID uint, request web.VoucherUpdateRequest) (model.Voucher, error) {
+	voucher, err := s.findOwned(tokoID, voucherID)
+	if err != nil {
+		return voucher, err
+	}
+
+	// Update field jika diisi
+	if request.Nama != "" {
+		voucher.Nama = request.Nama
+	}
+	if request.Nilai != 0 {
+		voucher.Nilai = request.Nilai
+	}
+	if request.MaksDiskon != nil {
+		voucher.MaksDiskon = *request.MaksDiskon
+	}
+	if request.MinBelanja != nil {
+		voucher.MinBelanja = *request.MinBelanja
+	}
+	if request.KuotaTotal != nil {
+		voucher.KuotaTotal = *request.KuotaTotal
+	}
+	if request.KuotaPerUser != nil {
+		voucher.KuotaPerUser = *request.KuotaPerUser
+	}
+	if request.BerlakuMulai != nil {
+		voucher.BerlakuMulai = *request.BerlakuMulai
+	}
+	if request.BerlakuSampai != nil {
+		voucher.BerlakuSampai = *request.BerlakuSampai
+	}
+	if request.Aktif != nil {
+		voucher.Aktif = *request.Aktif
+	}
+	if request.KategoriIDs != nil {
+		kategori, err := s.findKategori(*request.KategoriIDs)
+		if err != nil {
+			return voucher, err
+		}
+		voucher.Kategori = kategori
+	}
+
+	if err := validateVoucher(voucher); err != nil {
+		return voucher, err
+	}
+	if err := s.voucherRepository.Update(&voucher); err != nil {
+		return voucher, errors.New("Gagal memperbarui voucher")
+	}
+	return s.voucherRepository.FindByID(voucher.ID)
+}
+
+// GetPemakaian mengambil riwayat potongan yang diberikan sebuah voucher
+func (s *voucherService) GetPemakaian(tokoID uint, voucherID uint, pagination helpers.Pagination) ([]model.PemakaianVoucher, int64, error) {
+	if _, err := s.findOwned(tokoID, voucherID); err != nil {
+		return nil, 0, err
+	}
+	return s.voucherRepository.FindPemakaian(voucherID, pagination)
+}
+
+// TerapkanVoucher mengunci voucher, memastikan voucher masih dapat dipakai user,
+// lalu membagi potongannya ke baris-baris DetailTransaksi yang memenuhi syarat.
+// Voucher toko diterapkan lebih dulu, voucher platform memotong sisa harganya.
+// Maksimal satu voucher platform dan satu voucher untuk setiap toko.
+func (s *voucherService) TerapkanVoucher(tx *gorm.DB, userID uint, kodes []string, details []*model.DetailTransaksi, kategoriProduk map[uint]uint, now time.Time) ([]model.PemakaianVoucher, error) {
+	if len(kodes) == 0 {
+		return nil, nil
+	}
+
+	// Kunci voucher dalam urutan kode agar checkout bersamaan tidak saling menunggu
+	var sorted []string
+	seen := map[string]bool{}
+	for _, kode := range kodes {
+		kode = normalizeKodeVoucher(kode)
+		if seen[kode] {
+			return nil, helpers.Errorf(helpers.ErrTidakValid, "Voucher tidak valid: %s dipakai lebih dari sekali", kode)
+		}
+		seen[kode] = true
+		sorted = append(sorted, kode)
+	}
+	sort.Strings(sorted)
+
+	var vouchers []model.Voucher
+	pemilik := map[uint]bool{}
+	for _, kode := range sorted {
+		voucher, err := s.voucherRepository.FindByKodeForUpdate(tx, kode)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, helpers.Errorf(helpers.ErrTidakDitemukan, "Voucher %s tidak ditemukan", kode)
+			}
+			return nil, err
+		}
+		if pemilik[voucher.TokoID] {
+			return nil, helpers.Errorf(helpers.ErrTidakValid, "Voucher tidak valid: hanya satu voucher platform dan satu voucher per toko")
+		}
+		pemilik[voucher.TokoID] = true
+
+		if err := s.cekPemakaian(tx, voucher, userID, now); err != nil {
+			return nil, err
+		}
+		vouchers = append(vouchers, voucher)
+	}
+
+	// Voucher toko dulu, voucher platform terakhir
+	sort.SliceStable(vouchers, func(i, j int) bool {
+		return vouchers[i].TokoID != 0 && vouchers[j].TokoID == 0
+	})
+
+	var pemakaian []model.PemakaianVoucher
+	for _, voucher := range vouchers {
+		jumlah, err := bagiDiskon(voucher, details, kategoriProduk)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.voucherRepository.IncrementTerpakai(tx, voucher.ID); err != nil {
+			return nil, errors.New("Gagal memperbarui pemakaian voucher")
+		}
+		pemakaian = append(pemakaian, model.PemakaianVoucher{
+			// TransaksiID akan diisi setelah transaksi induk dibuat
+			VoucherID: voucher.ID,
+			UserID:    userID,
+			TokoID:    voucher.TokoID,
+			Kode:      voucher.Kode,
+			Jumlah:    jumlah,
+		})
+	}
+	return pemakaian, nil
+}
+
+// CatatPemakaian menyimpan potongan voucher setelah transaksi induk dibuat
+func (s *voucherService) CatatPemakaian(tx *gorm.DB, pemakaian []model.PemakaianVoucher) error {
+	if err := s.voucherRepository.CreatePemakaian(tx, pemakaian); err != nil {
+		return errors.New("Gagal menyimpan pemakaian voucher")
+	}
+	return nil
+}
+
+// LepasPemakaian mengembalikan kuota seluruh voucher yang dipakai transaksi induk 'transaksiID'
+// dan menghapus catatan pemakaiannya, sehingga voucher dapat dipakai kembali
+func (s *voucherService) LepasPemakaian(tx *gorm.DB, transaksiID uint) error {
+	return s.lepasPemakaian(tx, transaksiID, func(p model.PemakaianVoucher) bool {
+		return true
+	})
+}
+
+// LepasPemakaianToko seperti LepasPemakaian, tetapi hanya untuk voucher milik toko 'tokoID'.
+// Dipakai saat satu sub-pesanan dibatalkan; voucher platform tetap terpakai oleh sub-pesanan lain.
+func (s *voucherService) LepasPemakaianToko(tx *gorm.DB, transaksiID uint, tokoID uint) error {
+	return s.lepasPemakaian(tx, transaksiID, func(p model.PemakaianVoucher) bool {
+		return p.TokoID != 0 && p.TokoID == tokoID
+	})
+}
+
+func (s *voucherService) lepasPemakaian(tx *gorm.DB, transaksiID uint, cocok func(model.PemakaianVoucher) bool) error {
+	pemakaian, err := s.voucherRepository.FindPemakaianByTransaksi(tx, transaksiID)
+	if err != nil {
+		return errors.New("Gagal mengambil pemakaian voucher")
+	}
+	for _, p := range pemakaian {
+		if !cocok(p) {
+			continue
+		}
+		if err := s.voucherRepository.DecrementTerpakai(tx, p.VoucherID); err != nil {
+			return errors.New("Gagal memperbarui pemakaian voucher")
+		}
+		if err := s.voucherRepository.DeletePemakaian(tx, p.ID); err != nil {
+			return errors.New("Gagal menghapus pemakaian voucher")
+		}
+	}
+	return nil
+}
+
+// cekPemakaian memastikan voucher aktif, dalam masa berlaku, dan kuotanya belum habis
+func (s *voucherService) cekPemakaian(tx *gorm.DB, voucher model.Voucher, userID uint, now time.Time) error {
+	if !voucher.Aktif {
+		return helpers.Errorf(helpers.ErrTidakValid, "Voucher %s tidak valid: voucher tidak aktif", voucher.Kode)
+	}
+	if now.Before(voucher.BerlakuMulai) || now.After(voucher.BerlakuSampai) {
+		return helpers.Errorf(helpers.ErrTidakValid, "Voucher %s tidak valid: di luar masa berlaku", voucher.Kode)
+	}
+	if voucher.KuotaTotal > 0 && voucher.Terpakai >= voucher.KuotaTotal {
+		return helpers.Errorf(helpers.ErrTidakValid, "Voucher %s tidak valid: kuota sudah habis", voucher.Kode)
+	}
+	if voucher.KuotaPerUser > 0 {
+		dipakai, err := s.voucherRepository.CountPemakaianUser(tx, voucher.ID, userID)
+		if err != nil {
+			return err
+		}
+		if dipakai >= int64(voucher.KuotaPerUser) {
+			return helpers.Errorf(helpers.ErrTidakValid, "Voucher %s tidak valid: batas pemakaian Anda sudah tercapai", voucher.Kode)
+		}
+	}
+	return nil
+}
+
+// bagiDiskon menghitung potongan voucher dari baris yang memenuhi syarat dan membaginya
+// secara proporsional terhadap sisa harga setiap baris. Sisa pembulatan masuk ke baris terakhir.
+func bagiDiskon(voucher model.Voucher, details []*model.DetailTransaksi, kategoriProduk map[uint]uint) (uint, error) {
+	kategori := map[uint]bool{}
+	for _, k := range voucher.Kategori {
+		kategori[k.ID] = true
+	}
+
+	var eligible []*model.DetailTransaksi
+	var dasar uint
+	for _, detail := range details {
+		if voucher.TokoID != 0 && detail.TokoID != voucher.TokoID {
+			continue
+		}
+		if len(kategori) > 0 && !kategori[kategoriProduk[detail.ProductID]] {
+			continue
+		}
+		eligible = append(eligible, detail)
+		dasar += sisaHargaBaris(*detail)
+	}
+	if len(eligible) == 0 {
+		return 0, helpers.Errorf(helpers.ErrTidakValid, "Voucher %s tidak valid: tidak ada produk yang memenuhi syarat", voucher.Kode)
+	}
+	if dasar < voucher.MinBelanja {
+		return 0, helpers.Errorf(helpers.ErrTidakValid, "Voucher %s tidak valid: minimal belanja %d", voucher.Kode, voucher.MinBelanja)
+	}
+
+	var diskon uint
+	if voucher.Tipe == model.VoucherPersen {
+		diskon = uint(uint64(dasar) * uint64(voucher.Nilai) / 100)
+		if voucher.MaksDiskon > 0 && diskon > voucher.MaksDiskon {
+			diskon = voucher.MaksDiskon
+		}
+	} else {
+		diskon = voucher.Nilai
+	}
+	if diskon > dasar {
+		diskon = dasar
+	}
+
+	sisa := diskon
+	for i, detail := range eligible {
+		bagian := sisa
+		if i < len(eligible)-1 {
+			bagian = uint(uint64(diskon) * uint64(sisaHargaBaris(*detail)) / uint64(dasar))
+		}
+		sisa -= bagian
+
+		if voucher.TokoID == 0 {
+			detail.DiskonPlatform += bagian
+		} else {
+			detail.DiskonToko += bagian
+		}
+	}
+	return diskon, nil
+}
+
+// sisaHargaBaris adalah harga baris setelah potongan voucher yang sudah diterapkan
+func sisaHargaBaris(detail model.DetailTransaksi) uint {
+	return detail.HargaTotal - detail.DiskonToko - detail.DiskonPlatform
+}
+
+func (s *voucherService) findOwned(tokoID uint, voucherID uint) (model.Voucher, error) {
+	voucher, err := s.voucherRepository.FindByID(voucherID)
+	if err != nil || voucher.TokoID != tokoID {
+		return model.Voucher{}, helpers.Errorf(helpers.ErrTidakDitemukan, "Voucher tidak ditemukan")
+	}
+	return voucher, nil
+}
+
+func (s *voucherService) findKategori(ids []uint) ([]model.Kategori, error) {
+	kategori := []model.Kategori{}
+	for _, id := range ids {
+		k, err := s.kategoriRepository.FindByID(id)
+		if err != nil {
+			return nil, helpers.Errorf(helpers.ErrTidakDitemukan, "Kategori dengan ID %d tidak ditemukan", id)
+		}
+		kategori = append(kategori, k)
+	}
+	return kategori, nil
+}
+
+func validateVoucher(voucher model.Voucher) error {
+	switch voucher.Tipe {
+	case model.VoucherPersen:
+		if voucher.Nilai == 0 || voucher.Nilai > 100 {
+			return helpers.Errorf(helpers.ErrTidakValid, "Nilai voucher tidak valid: persentase harus 1-100")
+		}
+	case model.VoucherNominal:
+		if voucher.Nilai == 0 {
+			return helpers.Errorf(helpers.ErrTidakValid, "Nilai voucher tidak valid")
+		}
+	default:
+		return helpers.Errorf(helpers.ErrTidakValid, "Tipe voucher tidak dikenal: %s", voucher.Tipe)
+	}
+	if !voucher.BerlakuSampai.After(voucher.BerlakuMulai) {
+		return helpers.Errorf(helpers.ErrTidakValid, "Masa berlaku voucher tidak valid: berlaku_sampai harus setelah berlaku_mulai")
+	}
+	return nil
+}
+
+func normalizeKodeVoucher(kode string) string {
+	return strings.ToUpper(strings.TrimSpace(kode))
+}
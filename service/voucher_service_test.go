@@ -0,0 +1,67 @@
+package service_test
+
+import (
+	"github.com/Debjth19/go-evermos/model"
+	"github.com/Debjth19/go-evermos/model/web"
+	"github.com/Debjth19/go-evermos/payment"
+
+	"testing"
+	"time"
+)
+
+func TestVoucherDilepasSaatPesananBatal(t *testing.T) {
+	f := newFixture(t, 5)
+
+	// Voucher toko dan voucher platform dengan kuota satu kali pakai
+	now := time.Now()
+	vouchers := []model.Voucher{
+		{Kode: "TOKOHEMAT", Nama: "Hemat Toko", TokoID: f.toko.ID, Tipe: model.VoucherNominal, Nilai: 1000, KuotaTotal: 1, KuotaPerUser: 1,
+			BerlakuMulai: now.Add(-time.Hour), BerlakuSampai: now.Add(time.Hour), Aktif: true},
+		{Kode: "HEMAT", Nama: "Hemat", Tipe: model.VoucherNominal, Nilai: 1000, KuotaTotal: 1, KuotaPerUser: 1,
+			BerlakuMulai: now.Add(-time.Hour), BerlakuSampai: now.Add(time.Hour), Aktif: true},
+	}
+	if err := f.db.Create(&vouchers).Error; err != nil {
+		t.Fatalf("gagal menyiapkan voucher: %v", err)
+	}
+
+	cekDilepas := func() {
+		t.Helper()
+		for _, v := range vouchers {
+			var voucher model.Voucher
+			if err := f.db.First(&voucher, v.ID).Error; err != nil {
+				t.Fatalf("gagal membaca voucher: %v", err)
+			}
+			if voucher.Terpakai != 0 {
+				t.Fatalf("voucher %s terpakai = %d, ingin 0", voucher.Kode, voucher.Terpakai)
+			}
+		}
+		var pemakaian int64
+		f.db.Model(&model.PemakaianVoucher{}).Count(&pemakaian)
+		if pemakaian != 0 {
+			t.Fatalf("masih ada %d pemakaian voucher", pemakaian)
+		}
+	}
+
+	// Pembatalan oleh pembeli mengembalikan kuota kedua voucher
+	transaksi, err := f.checkout(payment.MethodBankTransfer, 1, "TOKOHEMAT", "HEMAT")
+	if err != nil {
+		t.Fatalf("checkout gagal: %v", err)
+	}
+	if _, err := f.transaksiService.CancelByBuyer(f.pembeli.ID, transaksi.ID, web.TransaksiCancelRequest{Alasan: "Salah pilih"}); err != nil {
+		t.Fatalf("pembatalan gagal: %v", err)
+	}
+	cekDilepas()
+
+	// Pesanan kedaluwarsa juga mengembalikan kuota, sehingga voucher dapat dipakai lagi
+	if _, err := f.checkout(payment.MethodBankTransfer, 1, "TOKOHEMAT", "HEMAT"); err != nil {
+		t.Fatalf("checkout ulang dengan voucher yang dilepas gagal: %v", err)
+	}
+	if trxID, err := f.transaksiService.ExpireNextUnpaid(time.Now().Add(time.Minute), nil); err != nil || trxID == 0 {
+		t.Fatalf("ExpireNextUnpaid = %d, %v, ingin satu transaksi kedaluwarsa", trxID, err)
+	}
+	cekDilepas()
+
+	if _, err := f.checkout(payment.MethodBankTransfer, 1, "TOKOHEMAT", "HEMAT"); err != nil {
+		t.Fatalf("checkout setelah pesanan kedaluwarsa gagal: %v", err)
+	}
+}
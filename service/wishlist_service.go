@@ -0,0 +1,122 @@
+package service
+
+import (
+	"github.com/Debjth19/go-evermos/helpers"
+	"github.com/Debjth19/go-evermos/model"
+	"github.com/Debjth19/go-evermos/model/web"
+	"github.com/Debjth19/go-evermos/repository"
+
+	"errors"
+)
+
+// WishlistBaris adalah produk di wishlist beserta perbandingannya dengan saat disimpan
+type WishlistBaris struct {
+	Wishlist    model.Wishlist
+	TierHarga   string
+	HargaSatuan uint // Harga per unit saat ini sesuai tier user
+	HargaTurun  bool // Harga saat ini lebih murah dibanding saat disimpan
+	StokHabis   bool // Stok kosong padahal masih tersedia saat disimpan
+}
+
+type WishlistService interface {
+	GetWishlist(userID uint, pagination helpers.Pagination) ([]WishlistBaris, int64, error)
+	AddWishlist(userID uint, request web.WishlistAddRequest) (model.Wishlist, error)
+	RemoveWishlist(userID uint, produkID uint) error
+}
+
+type wishlistService struct {
+	wishlistRepository repository.WishlistRepository
+	produkRepository   repository.ProdukRepository
+	userRepository     repository.UserRepository // Dibutuhkan untuk menentukan tier harga user
+}
+
+func NewWishlistService(wishlistRepo repository.WishlistRepository, produkRepo repository.ProdukRepository, userRepo repository.UserRepository) WishlistService {
+	return &wishlistService{
+		wishlistRepository: wishlistRepo,
+		produkRepository:   produkRepo,
+		userRepository:     userRepo,
+	}
+}
+
+// GetWishlist mengambil wishlist user per halaman dengan harga dan stok terkini
+func (s *wishlistService) GetWishlist(userID uint, pagination helpers.Pagination) ([]WishlistBaris, int64, error) {
+	isReseller, err := s.isReseller(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	wishlists, total, err := s.wishlistRepository.FindByUserID(userID, pagination)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var baris []WishlistBaris
+	for _, w := range wishlists {
+		tier, harga := w.Produk.HargaTier(isReseller)
+		baris = append(baris, WishlistBaris{
+			Wishlist:    w,
+			TierHarga:   tier,
+			HargaSatuan: harga,
+			HargaTurun:  harga < w.HargaSaatDisimpan,
+			StokHabis:   w.Produk.Stok == 0 && w.StokSaatDisimpan > 0,
+		})
+	}
+	return baris, total, nil
+}
+
+// AddWishlist menyimpan produk ke wishlist beserta harga dan stoknya saat ini
+func (s *wishlistService) AddWishlist(userID uint, request web.WishlistAddRequest) (model.Wishlist, error) {
+	if request.ProductID == 0 {
+		return model.Wishlist{}, helpers.Errorf(helpers.ErrTidakValid, "Produk wajib diisi")
+	}
+
+	produk, err := s.produkRepository.FindByID(request.ProductID)
+	if err != nil {
+		return model.Wishlist{}, helpers.Errorf(helpers.ErrTidakValid, "Produk dengan ID %d tidak ditemukan", request.ProductID)
+	}
+
+	exists, err := s.wishlistRepository.Exists(userID, produk.ID)
+	if err != nil {
+		return model.Wishlist{}, err
+	}
+	if exists {
+		return model.Wishlist{}, helpers.Errorf(helpers.ErrTidakDiizinkan, "Penambahan tidak diizinkan: produk sudah ada di wishlist")
+	}
+
+	isReseller, err := s.isReseller(userID)
+	if err != nil {
+		return model.Wishlist{}, err
+	}
+	_, harga := produk.HargaTier(isReseller)
+
+	wishlist := model.Wishlist{
+		UserID:            userID,
+		ProductID:         produk.ID,
+		HargaSaatDisimpan: harga,
+		StokSaatDisimpan:  produk.Stok,
+	}
+	if err := s.wishlistRepository.Create(&wishlist); err != nil {
+		return wishlist, errors.New("Gagal menyimpan wishlist")
+	}
+	return wishlist, nil
+}
+
+// RemoveWishlist menghapus produk dari wishlist user
+func (s *wishlistService) RemoveWishlist(userID uint, produkID uint) error {
+	deleted, err := s.wishlistRepository.Delete(userID, produkID)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return helpers.Errorf(helpers.ErrTidakDitemukan, "Produk tidak ditemukan di wishlist Anda")
+	}
+	return nil
+}
+
+func (s *wishlistService) isReseller(userID uint) (bool, error) {
+	user, err := s.userRepository.FindByID(userID)
+	if err != nil {
+		return false, helpers.Errorf(helpers.ErrTidakDitemukan, "User tidak ditemukan")
+	}
+	return user.Role == model.RoleReseller, nil
+}
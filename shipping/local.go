@@ -0,0 +1,65 @@
+package shipping
+
+import (
+	"errors"
+)
+
+// LocalRate adalah satu baris tabel tarif: biaya per kg untuk kurir, layanan, dan zona tertentu
+type LocalRate struct {
+	Kurir      string
+	Layanan    string
+	Zona       string
+	TarifPerKg uint
+	Estimasi   string
+}
+
+// DefaultLocalRates adalah tabel tarif bawaan, dipakai sampai ada integrasi kurir sungguhan
+var DefaultLocalRates = []LocalRate{
+	{Kurir: "jne", Layanan: "reg", Zona: ZonaDalamKota, TarifPerKg: 9000, Estimasi: "1-2 hari"},
+	{Kurir: "jne", Layanan: "reg", Zona: ZonaDalamProvinsi, TarifPerKg: 14000, Estimasi: "2-3 hari"},
+	{Kurir: "jne", Layanan: "reg", Zona: ZonaAntarProvinsi, TarifPerKg: 22000, Estimasi: "3-5 hari"},
+	{Kurir: "jne", Layanan: "yes", Zona: ZonaDalamKota, TarifPerKg: 15000, Estimasi: "1 hari"},
+	{Kurir: "jne", Layanan: "yes", Zona: ZonaDalamProvinsi, TarifPerKg: 24000, Estimasi: "1 hari"},
+	{Kurir: "jne", Layanan: "yes", Zona: ZonaAntarProvinsi, TarifPerKg: 38000, Estimasi: "1-2 hari"},
+	{Kurir: "sicepat", Layanan: "reg", Zona: ZonaDalamKota, TarifPerKg: 8000, Estimasi: "1-2 hari"},
+	{Kurir: "sicepat", Layanan: "reg", Zona: ZonaDalamProvinsi, TarifPerKg: 13000, Estimasi: "2-3 hari"},
+	{Kurir: "sicepat", Layanan: "reg", Zona: ZonaAntarProvinsi, TarifPerKg: 20000, Estimasi: "3-6 hari"},
+	{Kurir: "kurir_toko", Layanan: "instan", Zona: ZonaDalamKota, TarifPerKg: 12000, Estimasi: "Hari ini"},
+}
+
+// LocalRateProvider menghitung ongkos kirim dari tabel tarif lokal tanpa memanggil pihak ketiga
+type LocalRateProvider struct {
+	rates []LocalRate
+}
+
+func NewLocalRateProvider(rates []LocalRate) *LocalRateProvider {
+	return &LocalRateProvider{rates: rates}
+}
+
+func (p *LocalRateProvider) Name() string {
+	return "local"
+}
+
+func (p *LocalRateProvider) Rates(request RateRequest) ([]RateOption, error) {
+	if request.Asal.IDKota == "" || request.Tujuan.IDKota == "" {
+		return nil, errors.New("Kota asal dan kota tujuan pengiriman wajib diisi")
+	}
+
+	zona := ZonaPengiriman(request.Asal, request.Tujuan)
+	berat := BeratTagih(request.BeratGram)
+
+	var options []RateOption
+	for _, rate := range p.rates {
+		if rate.Zona != zona {
+			continue
+		}
+		options = append(options, RateOption{
+			Kode:     rate.Kurir + "_" + rate.Layanan,
+			Kurir:    rate.Kurir,
+			Layanan:  rate.Layanan,
+			Biaya:    rate.TarifPerKg * berat,
+			Estimasi: rate.Estimasi,
+		})
+	}
+	return options, nil
+}
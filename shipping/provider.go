@@ -0,0 +1,58 @@
+package shipping
+
+// Zona pengiriman berdasarkan kota asal dan kota tujuan
+const (
+	ZonaDalamKota     = "dalam_kota"
+	ZonaDalamProvinsi = "dalam_provinsi"
+	ZonaAntarProvinsi = "antar_provinsi"
+)
+
+// Lokasi adalah kota asal atau tujuan pengiriman, memakai ID wilayah yang sama dengan data user
+type Lokasi struct {
+	IDProvinsi string
+	IDKota     string
+}
+
+// RateRequest adalah data paket yang akan dihitung ongkos kirimnya
+type RateRequest struct {
+	Asal      Lokasi
+	Tujuan    Lokasi
+	BeratGram uint
+}
+
+// RateOption adalah satu pilihan kurir beserta biayanya
+type RateOption struct {
+	Kode     string // Dipilih pembeli saat checkout, contoh "jne_reg"
+	Kurir    string
+	Layanan  string
+	Biaya    uint
+	Estimasi string
+}
+
+// ShippingRateProvider adalah kontrak untuk setiap sumber tarif ongkos kirim
+type ShippingRateProvider interface {
+	// Name adalah nama provider tarif
+	Name() string
+	// Rates mengembalikan semua pilihan kurir yang tersedia untuk sebuah paket
+	Rates(request RateRequest) ([]RateOption, error)
+}
+
+// ZonaPengiriman menentukan zona dari kota asal dan kota tujuan
+func ZonaPengiriman(asal, tujuan Lokasi) string {
+	if asal.IDKota == tujuan.IDKota {
+		return ZonaDalamKota
+	}
+	if asal.IDProvinsi == tujuan.IDProvinsi {
+		return ZonaDalamProvinsi
+	}
+	return ZonaAntarProvinsi
+}
+
+// BeratTagih membulatkan berat ke atas per kilogram, minimal 1 kg
+func BeratTagih(beratGram uint) uint {
+	kg := (beratGram + 999) / 1000
+	if kg == 0 {
+		kg = 1
+	}
+	return kg
+}
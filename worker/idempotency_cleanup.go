@@ -0,0 +1,52 @@
+package worker
+
+import (
+	"github.com/Debjth19/go-evermos/repository"
+
+	"context"
+	"log"
+	"time"
+)
+
+// IdempotencyCleanupWorker secara berkala menghapus record Idempotency-Key yang sudah kedaluwarsa.
+// Record kedaluwarsa sudah tidak diputar ulang, sehingga penghapusan hanya menjaga ukuran tabel.
+type IdempotencyCleanupWorker struct {
+	idempotencyRepository repository.IdempotencyRepository
+	interval              time.Duration // Jeda antar putaran
+}
+
+func NewIdempotencyCleanupWorker(idempotencyRepository repository.IdempotencyRepository, interval time.Duration) *IdempotencyCleanupWorker {
+	return &IdempotencyCleanupWorker{
+		idempotencyRepository: idempotencyRepository,
+		interval:              interval,
+	}
+}
+
+// Start menjalankan worker di goroutine terpisah hingga ctx dibatalkan
+func (w *IdempotencyCleanupWorker) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			w.RunOnce()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunOnce menghapus seluruh record yang masa berlakunya sudah habis
+func (w *IdempotencyCleanupWorker) RunOnce() {
+	total, err := w.idempotencyRepository.DeleteExpired(time.Now())
+	if err != nil {
+		log.Printf("Worker idempotency gagal: %v", err)
+		return
+	}
+	if total > 0 {
+		log.Printf("Worker idempotency: %d key kedaluwarsa dihapus", total)
+	}
+}
@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"github.com/Debjth19/go-evermos/service"
+
+	"context"
+	"log"
+	"time"
+)
+
+// OrderExpiryWorker secara berkala mengubah transaksi yang belum dibayar melewati TTL
+// menjadi 'expired' dan mengembalikan stok yang sudah dipesan saat checkout.
+// Aman dijalankan di beberapa instance karena setiap transaksi diklaim dengan row lock.
+type OrderExpiryWorker struct {
+	transaksiService service.TransaksiService
+	ttl              time.Duration // Batas waktu pembayaran sejak checkout
+	interval         time.Duration // Jeda antar putaran
+}
+
+func NewOrderExpiryWorker(transaksiService service.TransaksiService, ttl time.Duration, interval time.Duration) *OrderExpiryWorker {
+	return &OrderExpiryWorker{
+		transaksiService: transaksiService,
+		ttl:              ttl,
+		interval:         interval,
+	}
+}
+
+// Start menjalankan worker di goroutine terpisah hingga ctx dibatalkan
+func (w *OrderExpiryWorker) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			w.RunOnce()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunOnce mengedaluwarsakan transaksi yang lewat TTL sampai habis. Transaksi yang gagal diproses
+// dicatat di log dan dilewati hingga putaran berikutnya, sehingga tidak menghalangi transaksi lain.
+func (w *OrderExpiryWorker) RunOnce() {
+	batas := time.Now().Add(-w.ttl)
+	var lewati []uint
+	total := 0
+	defer func() {
+		if total > 0 {
+			log.Printf("Worker kedaluwarsa: %d transaksi belum dibayar ditandai expired", total)
+		}
+	}()
+
+	for {
+		trxID, err := w.transaksiService.ExpireNextUnpaid(batas, lewati)
+		if err != nil {
+			if trxID == 0 {
+				// Klaim gagal (misalnya database tidak tersedia), dicoba lagi pada putaran berikutnya
+				log.Printf("Worker kedaluwarsa gagal: %v", err)
+				return
+			}
+			log.Printf("Worker kedaluwarsa: transaksi %d dilewati: %v", trxID, err)
+			lewati = append(lewati, trxID)
+			continue
+		}
+		if trxID == 0 {
+			return
+		}
+		total++
+	}
+}
@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"github.com/Debjth19/go-evermos/service"
+
+	"errors"
+	"testing"
+	"time"
+)
+
+// transaksiServiceUji mensimulasikan antrean transaksi yang belum dibayar.
+// Transaksi dalam 'rusak' selalu gagal dikedaluwarsakan.
+type transaksiServiceUji struct {
+	service.TransaksiService
+	antrean []uint
+	rusak   map[uint]bool
+	dicoba  map[uint]int
+	expired []uint
+}
+
+func (s *transaksiServiceUji) ExpireNextUnpaid(batas time.Time, lewati []uint) (uint, error) {
+	dilewati := map[uint]bool{}
+	for _, id := range lewati {
+		dilewati[id] = true
+	}
+	for i, id := range s.antrean {
+		if dilewati[id] {
+			continue
+		}
+		s.dicoba[id]++
+		if s.rusak[id] {
+			return id, errors.New("Gagal memperbarui status transaksi")
+		}
+		s.antrean = append(s.antrean[:i], s.antrean[i+1:]...)
+		s.expired = append(s.expired, id)
+		return id, nil
+	}
+	return 0, nil
+}
+
+func TestRunOnceMelewatiTransaksiGagal(t *testing.T) {
+	svc := &transaksiServiceUji{
+		antrean: []uint{1, 2, 3},
+		rusak:   map[uint]bool{1: true},
+		dicoba:  map[uint]int{},
+	}
+	w := NewOrderExpiryWorker(svc, time.Hour, time.Minute)
+
+	w.RunOnce()
+
+	if len(svc.expired) != 2 || svc.expired[0] != 2 || svc.expired[1] != 3 {
+		t.Fatalf("expired = %v, ingin [2 3]", svc.expired)
+	}
+	if svc.dicoba[1] != 1 {
+		t.Fatalf("transaksi 1 dicoba %d kali, ingin sekali dalam satu putaran", svc.dicoba[1])
+	}
+}